@@ -19,30 +19,13 @@ import (
 	"os"
 	"os/signal"
 
-	"github.com/spf13/cobra"
+	"github.com/cilium/team-manager/pkg/cmd"
 )
 
-var (
-	orgName        string
-	configFilename string
-)
-
-func init() {
-	flag := rootCmd.PersistentFlags()
-
-	flag.StringVar(&orgName, "org", "cilium", "GitHub organization name")
-	flag.StringVar(&configFilename, "config-filename", "team-assignments.yaml", "Config filename")
-}
-
-var rootCmd = &cobra.Command{
-	Use:   "team-manager",
-	Short: "Manage GitHub team state locally and synchronize it with GitHub",
-}
-
 func main() {
 	ctx := interruptableContext()
 
-	if err := rootCmd.ExecuteContext(ctx); err != nil {
+	if err := cmd.NewRootCommand(cmd.DefaultDeps()).ExecuteContext(ctx); err != nil {
 		os.Exit(1)
 	}
 }
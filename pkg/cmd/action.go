@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewActionCommand returns the action command.
+func NewActionCommand(deps Deps) *cobra.Command {
+	return requireOperations(&cobra.Command{
+		Use:   "action",
+		Short: "Run check or push as a step of a GitHub Actions workflow",
+		Long: `Runs check or push as a step of a GitHub Actions workflow, see action.yml.
+The inputs are read from the INPUT_* environment variables set by GitHub
+Actions from the 'with' of the step:
+
+  command   check or push (default check)
+  config    config filename (default discovered as by --config-filename)
+  org       GitHub organization (default --org)
+  fail-on   minimum severity of drift that fails check (default from the
+            'drift.failOn' of the configuration)
+  dry-run   true to push without changing anything in GitHub
+
+Push never asks for confirmation. The drifts, warnings and errors are
+annotated in the workflow run, and the following outputs are set:
+
+  drift     true if the configuration and GitHub differ
+  summary   Markdown summary of the drifts or changes, also added to the
+            summary of the job`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if file := actionInput("config"); file != "" {
+				configFilename = file
+			}
+			if org := actionInput("org"); org != "" {
+				orgName = org
+			}
+			switch command := actionInput("command"); command {
+			case "", "check":
+				return runActionCheck(cmd, deps)
+			case "push":
+				dryRun, err := actionBoolInput("dry-run")
+				if err != nil {
+					return err
+				}
+				return runPush(cmd, deps, pushOptions{dryRun: dryRun, force: true, reportFormat: "github"})
+			default:
+				return fmt.Errorf("invalid input command %q, must be check or push", command)
+			}
+		},
+	}, pushOperations...)
+}
+
+// runActionCheck reports the drifts between the local configuration and
+// GitHub as annotations and outputs.
+func runActionCheck(cmd *cobra.Command, deps Deps) error {
+	cfg, err := loadCheckedState(deps)
+	if err != nil {
+		return fmt.Errorf("failed to load local state: %w", err)
+	}
+	threshold := cfg.Drift.Threshold()
+	if failOn := actionInput("fail-on"); failOn != "" {
+		threshold = config.Severity(failOn)
+		if !threshold.IsValid() {
+			return fmt.Errorf("invalid input fail-on %q", failOn)
+		}
+	}
+
+	drifts, err := computeDrifts(cmd.Context(), deps, cfg)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	sb.WriteString("### team-manager check\n\n")
+	if len(drifts) == 0 {
+		sb.WriteString("No drift between the configuration and GitHub.\n")
+	} else {
+		sb.WriteString("| Severity | Drift | Team | Member |\n|---|---|---|---|\n")
+	}
+	for _, d := range drifts {
+		level := "notice"
+		switch d.Severity {
+		case config.SeverityWarning:
+			level = "warning"
+		case config.SeverityCritical:
+			level = "error"
+		}
+		annotate(os.Stdout, level, d.String())
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", d.Severity, d.Kind, d.Team, orDash(d.Member))
+	}
+	if err = writeActionOutputs(len(drifts) != 0, sb.String()); err != nil {
+		return err
+	}
+
+	if failing := team.DriftsAtLeast(drifts, threshold); len(failing) != 0 {
+		return fmt.Errorf("found %d drifts with severity %s or higher", len(failing), threshold)
+	}
+	return nil
+}
+
+// actionInput returns the input with the given name of the GitHub Action.
+func actionInput(name string) string {
+	return strings.TrimSpace(os.Getenv("INPUT_" + strings.ToUpper(strings.ReplaceAll(name, " ", "_"))))
+}
+
+// actionBoolInput returns the boolean input with the given name of the GitHub
+// Action, false if unset.
+func actionBoolInput(name string) (bool, error) {
+	value := actionInput(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid input %s %q, must be true or false", name, value)
+	}
+	return b, nil
+}
+
+// annotate writes a workflow command annotating the workflow run with the
+// given message, at the given level: notice, warning or error.
+func annotate(w io.Writer, level, message string) {
+	message = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(message)
+	fmt.Fprintf(w, "::%s::%s\n", level, message)
+}
+
+// writeActionOutputs sets the drift and summary outputs of the GitHub Action
+// and adds the summary to the summary of the job. Outside of GitHub Actions,
+// the summary is printed instead.
+func writeActionOutputs(drift bool, summary string) error {
+	if os.Getenv("GITHUB_OUTPUT") == "" {
+		fmt.Print(summary)
+		return nil
+	}
+	delimiter := make([]byte, 16)
+	if _, err := rand.Read(delimiter); err != nil {
+		return err
+	}
+	eof := "EOF_" + hex.EncodeToString(delimiter)
+	outputs := fmt.Sprintf("drift=%t\nsummary<<%s\n%s\n%s\n", drift, eof, strings.TrimSuffix(summary, "\n"), eof)
+	if err := appendFile(os.Getenv("GITHUB_OUTPUT"), outputs); err != nil {
+		return fmt.Errorf("failed to write action outputs: %w", err)
+	}
+	if file := os.Getenv("GITHUB_STEP_SUMMARY"); file != "" {
+		if err := appendFile(file, summary); err != nil {
+			return fmt.Errorf("failed to write job summary: %w", err)
+		}
+	}
+	return nil
+}
+
+func appendFile(file, s string) error {
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.WriteString(s); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// githubReporter reports the output of push as a GitHub Action: warnings and
+// errors are annotated, and the summary is written to the outputs.
+type githubReporter struct {
+	team.TextReporter
+	changes strings.Builder
+}
+
+func newGitHubReporter() *githubReporter {
+	return &githubReporter{TextReporter: team.TextReporter{Out: os.Stdout, Err: os.Stderr}}
+}
+
+func (r *githubReporter) Plan(event team.PlanEvent) {
+	r.TextReporter.Plan(event)
+	if event.Title != "" {
+		fmt.Fprintf(&r.changes, "%s:\n", event.Title)
+	}
+	event.Print(&r.changes)
+}
+
+func (r *githubReporter) Warn(format string, args ...interface{}) {
+	annotate(r.Out, "warning", fmt.Sprintf(format, args...))
+}
+
+func (r *githubReporter) Error(format string, args ...interface{}) {
+	annotate(r.Out, "error", fmt.Sprintf(format, args...))
+}
+
+func (r *githubReporter) Summary(summary team.Summary) {
+	r.TextReporter.Summary(summary)
+
+	var sb strings.Builder
+	sb.WriteString("### team-manager push\n\n")
+	if summary.DryRun {
+		fmt.Fprintf(&sb, "Dry run, %d changes not submitted.\n", summary.Submitted+summary.Failed)
+	} else {
+		fmt.Fprintf(&sb, "Submitted %d changes, %d failed.\n", summary.Submitted, summary.Failed)
+	}
+	if r.changes.Len() != 0 {
+		fmt.Fprintf(&sb, "\n```\n%s```\n", r.changes.String())
+	}
+	if err := writeActionOutputs(summary.Submitted+summary.Failed != 0, sb.String()); err != nil {
+		r.Error("%s", err)
+	}
+}
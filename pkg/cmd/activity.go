@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewActivityCommand returns the activity command.
+func NewActivityCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		since        string
+		until        string
+		inactiveOnly bool
+	}
+	cmd := &cobra.Command{
+		Use:   "activity [TEAM ...]",
+		Short: "Count the mentions and review requests of teams to identify inactive teams",
+		Long: `Counts the issues and pull requests of the organization that mention each of
+the given teams, or all teams of the configuration if none are given, and the
+pull requests that requested a review from them, over a period of time. Teams
+that were neither mentioned nor requested for review are flagged as inactive
+and are candidates for retirement.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			since, err := time.Parse(config.DateFormat, opts.since)
+			if err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			until, err := time.Parse(config.DateFormat, opts.until)
+			if err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			teamNames := args
+			if len(teamNames) == 0 {
+				for teamName := range cfg.Teams {
+					teamNames = append(teamNames, teamName)
+				}
+				sort.Strings(teamNames)
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			tm := team.NewManager(ghClient, nil, orgName)
+			tm.SetTeamSlugs(cfg)
+
+			activities, err := tm.GetTeamActivity(cmd.Context(), teamNames, since, until)
+			if err != nil {
+				return fmt.Errorf("failed to get team activity: %w", err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TEAM\tMENTIONS\tREVIEW REQUESTS\t")
+			var inactive int
+			for _, a := range activities {
+				flag := ""
+				if a.Inactive() {
+					flag = "INACTIVE"
+					inactive++
+				} else if opts.inactiveOnly {
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", a.Team, a.Mentions, a.ReviewRequests, flag)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if inactive != 0 {
+				fmt.Printf("\n%d teams were inactive between %s and %s\n", inactive, opts.since, opts.until)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.since, "since", time.Now().AddDate(0, -3, 0).Format(config.DateFormat), "Only count issues and pull requests created on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.until, "until", time.Now().Format(config.DateFormat), "Only count issues and pull requests created on or before this date (YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&opts.inactiveOnly, "inactive-only", false, "Only print teams that were neither mentioned nor requested for review")
+
+	return requireOperations(cmd, github.OperationReadTeams, github.OperationReadRepositories)
+}
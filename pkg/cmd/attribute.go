@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/persistence"
+	"github.com/cilium/team-manager/pkg/set"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewAttributeCommand returns the attribute command.
+func NewAttributeCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		since       string
+		until       string
+		outsideOnly bool
+	}
+	cmd := &cobra.Command{
+		Use:   "attribute [TEAM ...]",
+		Short: "Attribute team membership changes from the organization audit log to their actors",
+		Long: `Lists the team membership changes recorded in the organization audit log and
+correlates them with the history of the configuration file, to find out who
+changed team memberships outside of team-manager. Requires the audit log API,
+only available for organizations on GitHub Enterprise Cloud.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+
+			history, err := configHistory(deps, configFilename)
+			if err != nil {
+				return fmt.Errorf("failed to read configuration history: %w", err)
+			}
+
+			teamsBySlug := map[string]string{}
+			for _, rev := range history {
+				for teamName := range rev.Config.Teams {
+					teamsBySlug[team.TeamSlug(rev.Config, teamName)] = teamName
+				}
+			}
+			filter := set.New[string]()
+			for _, t := range args {
+				filter.Add(team.Slug(t))
+				for teamSlug, teamName := range teamsBySlug {
+					if teamName == t {
+						filter.Add(teamSlug)
+					}
+				}
+			}
+
+			var entries []github.AuditLogEntry
+			for _, action := range []string{"team.add_member", "team.remove_member"} {
+				phrase := fmt.Sprintf("action:%s created:%s..%s", action, opts.since, opts.until)
+				e, err := github.ListAuditLog(cmd.Context(), ghClient, orgName, phrase)
+				if err != nil {
+					return fmt.Errorf("failed to read audit log: %w", err)
+				}
+				entries = append(entries, e...)
+			}
+			sort.Slice(entries, func(i, j int) bool {
+				return entries[i].Timestamp < entries[j].Timestamp
+			})
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tACTOR\tACTION\tTEAM\tUSER\tSOURCE")
+			for _, e := range entries {
+				teamSlug := strings.TrimPrefix(e.Team, orgName+"/")
+				if len(filter) != 0 {
+					if _, ok := filter[teamSlug]; !ok {
+						continue
+					}
+				}
+				source := attributeSource(history, teamsBySlug[teamSlug], e)
+				if opts.outsideOnly && source != "outside config" {
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					e.CreatedAt().UTC().Format(time.RFC3339), e.Actor, e.Action, teamSlug, e.User, source)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.since, "since", time.Now().AddDate(0, 0, -30).Format(config.DateFormat), "Only consider events on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.until, "until", time.Now().Format(config.DateFormat), "Only consider events on or before this date (YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&opts.outsideOnly, "outside-only", false, "Only print changes that were not made through the configuration file")
+
+	return requireOperations(cmd, github.OperationReadAuditLog)
+}
+
+// configHistory returns all committed revisions of the given configuration
+// file, oldest first. If the file is not tracked by git, the current
+// contents of the file are returned as the only revision.
+func configHistory(deps Deps, file string) ([]persistence.Revision, error) {
+	history, err := deps.LoadStateHistory(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) != 0 {
+		return history, nil
+	}
+	cfg, err := deps.LoadState(file)
+	if err != nil {
+		return nil, err
+	}
+	return []persistence.Revision{{Config: cfg}}, nil
+}
+
+// attributeSource returns whether the given audit log entry matches the
+// configuration file that was committed at the time of the event.
+func attributeSource(history []persistence.Revision, teamName string, e github.AuditLogEntry) string {
+	var cfg *config.Config
+	for _, rev := range history {
+		if rev.CommittedAt.After(e.CreatedAt()) {
+			break
+		}
+		cfg = rev.Config
+	}
+	if cfg == nil || teamName == "" {
+		return "unknown"
+	}
+	teamCfg, ok := cfg.Teams[teamName]
+	if !ok {
+		return "unmanaged team"
+	}
+
+	inConfig := false
+	for _, m := range teamCfg.Members {
+		if strings.EqualFold(m, e.User) {
+			inConfig = true
+			break
+		}
+	}
+	if inConfig == (e.Action == "team.add_member") {
+		return "config"
+	}
+	return "outside config"
+}
@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// auditOptions are the flags shared by the audit subcommands.
+type auditOptions struct {
+	format string
+}
+
+// NewAuditCommand returns the audit command.
+func NewAuditCommand(deps Deps) *cobra.Command {
+	opts := &auditOptions{}
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Audit the organization against the configuration",
+	}
+
+	cmd.PersistentFlags().StringVar(&opts.format, "format", "text", "Output format, one of: text, json")
+	cmd.AddCommand(
+		newAudit2FACommand(deps, opts),
+		newAuditCollaboratorsCommand(deps, opts),
+		newAuditOrphansCommand(deps, opts),
+	)
+
+	return cmd
+}
+
+// newAudit2FACommand returns the audit 2fa command.
+func newAudit2FACommand(deps Deps, opts *auditOptions) *cobra.Command {
+	return requireOperations(&cobra.Command{
+		Use:   "2fa",
+		Short: "List the teams with members without two-factor authentication",
+		Long: `Lists the members of the organization without two-factor authentication
+enabled and the teams of the configuration they are members of. Only owners of
+the organization are allowed to list them.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			without2FA, err := team.NewManager(ghClient, nil, orgName).ListMembersWithout2FA(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list organization members without two-factor authentication: %w", err)
+			}
+			audit := team.AuditTwoFactor(cfg, without2FA)
+
+			switch opts.format {
+			case "text":
+				if len(audit.Members) == 0 {
+					fmt.Println("All members have two-factor authentication enabled")
+					return nil
+				}
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "TEAM\tMEMBERS WITHOUT 2FA")
+				teamNames := make([]string, 0, len(audit.Teams))
+				for teamName := range audit.Teams {
+					teamNames = append(teamNames, teamName)
+				}
+				sort.Strings(teamNames)
+				for _, teamName := range teamNames {
+					fmt.Fprintf(w, "%s\t%s\n", teamName, strings.Join(audit.Teams[teamName], ", "))
+				}
+				if teamless := audit.TeamlessMembers(); len(teamless) != 0 {
+					fmt.Fprintf(w, "-\t%s\n", strings.Join(teamless, ", "))
+				}
+				if err = w.Flush(); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "[WARN]: %d members of organization %s don't have two-factor authentication enabled\n", len(audit.Members), orgName)
+				return nil
+			case "json":
+				if audit.Members == nil {
+					audit.Members = []string{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err = enc.Encode(audit); err != nil {
+					return fmt.Errorf("failed to write audit: %w", err)
+				}
+				return nil
+			default:
+				return fmt.Errorf("unknown audit format %q", opts.format)
+			}
+		},
+	}, github.OperationReadTwoFactorStatus)
+}
+
+// newAuditCollaboratorsCommand returns the audit collaborators command.
+func newAuditCollaboratorsCommand(deps Deps, opts *auditOptions) *cobra.Command {
+	var collaboratorsOpts struct {
+		fix            bool
+		force          bool
+		overrideFreeze bool
+	}
+	cmd := &cobra.Command{
+		Use:   "collaborators",
+		Short: "List the direct collaborators of repositories that aren't granted access by any team",
+		Long: `Lists the users with direct access to the repositories of the organization
+who aren't members of any team of the configuration having access to these
+repositories, bypassing the teams. The outside collaborators of the
+configuration are managed and thus not listed. Archived repositories are
+skipped.
+
+The collaborators found are removed from the repositories with --fix.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			tm := team.NewManager(ghClient, nil, orgName)
+			collaborators, err := tm.AuditDirectCollaborators(cmd.Context(), cfg)
+			if err != nil {
+				return fmt.Errorf("failed to audit collaborators: %w", err)
+			}
+
+			switch opts.format {
+			case "text":
+				if len(collaborators) == 0 {
+					fmt.Println("No collaborators bypassing the teams")
+					return nil
+				}
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "REPOSITORY\tLOGIN\tPERMISSION\tMEMBER")
+				for _, c := range collaborators {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", c.Repo, c.Login, c.Perm, c.Member)
+				}
+				if err = w.Flush(); err != nil {
+					return err
+				}
+			case "json":
+				if collaborators == nil {
+					collaborators = []team.DirectCollaborator{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err = enc.Encode(collaborators); err != nil {
+					return fmt.Errorf("failed to write collaborators: %w", err)
+				}
+			default:
+				return fmt.Errorf("unknown audit format %q", opts.format)
+			}
+
+			if !collaboratorsOpts.fix || len(collaborators) == 0 {
+				return nil
+			}
+			if err = checkFreeze(cmd.Context(), cfg, collaboratorsOpts.overrideFreeze); err != nil {
+				return err
+			}
+			if err = preflight(cmd.Context(), ghClient, github.OperationManageRepositoryAccess); err != nil {
+				return err
+			}
+			tm.SetReporter(&team.TextReporter{Out: os.Stderr, Err: os.Stderr})
+			return tm.RemoveDirectCollaborators(cmd.Context(), collaborators, collaboratorsOpts.force)
+		},
+	}
+
+	cmd.Flags().BoolVar(&collaboratorsOpts.fix, "fix", false, "Remove the collaborators found from the repositories")
+	cmd.Flags().BoolVar(&collaboratorsOpts.force, "force", false, "Do not ask for confirmation before removing the collaborators")
+	cmd.Flags().BoolVar(&collaboratorsOpts.overrideFreeze, "override-freeze", false, "Apply changes even during a freeze window")
+
+	return requireOperations(cmd, github.OperationReadRepositories, github.OperationManageRepositoryAccess)
+}
+
+// newAuditOrphansCommand returns the audit orphans command.
+func newAuditOrphansCommand(deps Deps, opts *auditOptions) *cobra.Command {
+	return requireOperations(&cobra.Command{
+		Use:   "orphans",
+		Short: "List the organization members that aren't members of any team of the configuration",
+		Long: `Lists the members of the organization that aren't members of any team of the
+configuration, e.g. stale accounts of former contributors. Orphans that are
+members of the configuration are marked as known.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+			members, err := team.NewManager(nil, ghGraphQLClient, orgName).ListOrgMembers(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list organization members: %w", err)
+			}
+			orphans := team.Orphans(cfg, members)
+			if redacting(cfg) {
+				for i := range orphans {
+					orphans[i].Name = ""
+				}
+			}
+
+			switch opts.format {
+			case "text":
+				if len(orphans) == 0 {
+					fmt.Println("No orphan members")
+					return nil
+				}
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "LOGIN\tNAME\tROLE\tKNOWN")
+				for _, o := range orphans {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", o.Login, orDash(o.Name), o.Role, o.Known)
+				}
+				return w.Flush()
+			case "json":
+				if orphans == nil {
+					orphans = []team.Orphan{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err = enc.Encode(orphans); err != nil {
+					return fmt.Errorf("failed to write orphans: %w", err)
+				}
+				return nil
+			default:
+				return fmt.Errorf("unknown audit format %q", opts.format)
+			}
+		},
+	}, github.OperationReadTeams)
+}
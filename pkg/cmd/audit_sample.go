@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewAuditSampleCommand returns the audit-sample command.
+func NewAuditSampleCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		members      int
+		teams        int
+		seed         int64
+		inactiveDays int
+		output       string
+		verify       string
+	}
+	cmd := &cobra.Command{
+		Use:   "audit-sample",
+		Short: "Deeply verify a weighted random sample of members and teams into a signed report",
+		Long: `Picks a random sample of the members and teams of the configuration and
+verifies them against GitHub, as a lightweight continuous audit instead of full
+periodic reviews. Members with more privileges, i.e. owners, maintainers and
+members of many teams, and teams with more members and repositories are more
+likely to be picked.
+
+For every member, the organization and team memberships and roles, the linked
+SAML SSO identity, two-factor authentication and last activity in the
+organization are checked. For every team, its members and maintainers are
+checked. Checks that can't be performed, e.g. for organizations without SAML
+SSO, are reported as unknown.
+
+The report is stored into --output, signed with the secret set in the
+TEAM_MANAGER_AUDIT_SECRET environment variable, and can be verified later with
+--verify. The seed of the sample is part of the report to reproduce it.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			secret := os.Getenv("TEAM_MANAGER_AUDIT_SECRET")
+			if secret == "" {
+				return fmt.Errorf("environment variable TEAM_MANAGER_AUDIT_SECRET must be set to sign and verify audits")
+			}
+			if opts.verify != "" {
+				audit, err := team.LoadSampleAudit(opts.verify)
+				if err != nil {
+					return fmt.Errorf("failed to load audit: %w", err)
+				}
+				if err = audit.Verify([]byte(secret)); err != nil {
+					return err
+				}
+				fmt.Printf("Audit of organization %s created at %s is authentic, %d checks failed\n", audit.Organization, audit.CreatedAt.Format(time.RFC3339), audit.Failed())
+				return nil
+			}
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+			tm := team.NewManager(ghClient, ghGraphQLClient, orgName)
+			tm.SetTeamSlugs(cfg)
+			tm.SetReporter(&team.TextReporter{Out: os.Stderr, Err: os.Stderr})
+
+			now := time.Now()
+			seed := opts.seed
+			if seed == 0 {
+				seed = now.UnixNano()
+			}
+			rng := rand.New(rand.NewSource(seed))
+			members := team.WeightedSample(team.MemberWeights(cfg), opts.members, rng)
+			teams := team.WeightedSample(team.TeamWeights(cfg), opts.teams, rng)
+
+			audit, err := tm.AuditSample(cmd.Context(), cfg, members, teams, opts.inactiveDays, now)
+			if err != nil {
+				return err
+			}
+			audit.Seed = seed
+			if redacting(cfg) {
+				audit.RedactIdentities()
+			}
+			printSampleAudit(audit)
+
+			if err = audit.Sign([]byte(secret)); err != nil {
+				return fmt.Errorf("failed to sign audit: %w", err)
+			}
+			if err = team.StoreSampleAudit(opts.output, audit); err != nil {
+				return fmt.Errorf("failed to store audit: %w", err)
+			}
+			fmt.Printf("Signed audit stored in %s\n", opts.output)
+			if failed := audit.Failed(); failed != 0 {
+				return fmt.Errorf("%d checks failed", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.members, "members", 5, "Number of members to sample")
+	cmd.Flags().IntVar(&opts.teams, "teams", 2, "Number of teams to sample")
+	cmd.Flags().Int64Var(&opts.seed, "seed", 0, "Seed of the random sample, to reproduce the sample of a previous audit (default random)")
+	cmd.Flags().IntVar(&opts.inactiveDays, "inactive-days", 90, "Number of days without activity in the organization after which members fail the activity check")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "audit-sample.json", "File to store the signed audit into")
+	cmd.Flags().StringVar(&opts.verify, "verify", "", "Verify the signature of the given audit instead of auditing")
+
+	return requireOperations(cmd, github.OperationReadTeams, github.OperationReadTwoFactorStatus, github.OperationReadSSOIdentities, github.OperationReadRepositories)
+}
+
+// printSampleAudit prints the checks of the given audit.
+func printSampleAudit(audit *team.SampleAudit) {
+	printSubjects := func(kind string, subjects []team.AuditSubject) {
+		for _, s := range subjects {
+			fmt.Printf("%s %s (weight %d)\n", kind, s.Name, s.Weight)
+			for _, c := range s.Checks {
+				fmt.Printf("  %-7s %-12s %s\n", c.Status, c.Name, c.Detail)
+			}
+		}
+	}
+	printSubjects("Member", audit.Members)
+	printSubjects("Team", audit.Teams)
+	fmt.Printf("Seed: %d\n", audit.Seed)
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/export"
+)
+
+// NewBadgesCommand returns the badges command.
+func NewBadgesCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		dir      string
+		syncedAt string
+	}
+	cmd := &cobra.Command{
+		Use:   "badges",
+		Short: "Generate JSON endpoints and shields.io badges of the teams for static hosting",
+		Long: `Writes into --dir a JSON endpoint per team, <slug>.json, with its description,
+parent team, number of members and maintainers and the time the configuration
+was last synced, along with a shields.io endpoint badge, <slug>.badge.json,
+showing its number of members, and index.json listing all the teams. Secret
+teams are left out.
+
+Serving the directory, e.g. with GitHub Pages, lets READMEs display live team
+information, e.g. with
+https://img.shields.io/endpoint?url=https://example.org/teams/<slug>.badge.json
+
+The configuration is considered synced when it was last committed, as push
+usually runs on every change of the configuration, or at --synced-at.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			var syncedAt time.Time
+			if opts.syncedAt != "" {
+				if syncedAt, err = time.Parse(time.RFC3339, opts.syncedAt); err != nil {
+					return fmt.Errorf("invalid --synced-at %q, must be an RFC 3339 time: %w", opts.syncedAt, err)
+				}
+			} else {
+				syncedAt = lastCommitTime(configFilename)
+			}
+
+			endpoints := export.TeamEndpoints(cfg, syncedAt)
+			if err = export.WriteTeamEndpoints(opts.dir, endpoints); err != nil {
+				return fmt.Errorf("failed to write team endpoints: %w", err)
+			}
+			fmt.Printf("Wrote the endpoints of %d teams to %s\n", len(endpoints), opts.dir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.dir, "dir", "badges", "Directory to write the endpoints to")
+	cmd.Flags().StringVar(&opts.syncedAt, "synced-at", "", "Time the configuration was last synced, in RFC 3339 format (default the time of the last commit of the configuration)")
+
+	return cmd
+}
+
+// lastCommitTime returns the time of the last commit changing file, or the
+// current time if file isn't committed in a git repository.
+func lastCommitTime(file string) time.Time {
+	dir, base := filepath.Split(file)
+	if dir == "" {
+		dir = "."
+	}
+	out, err := exec.Command("git", "-C", dir, "log", "-1", "--format=%ct", "--", base).Output()
+	if err != nil {
+		return time.Now()
+	}
+	secs, err := strconv.ParseInt(string(bytes.TrimSpace(out)), 10, 64)
+	if err != nil {
+		return time.Now()
+	}
+	return time.Unix(secs, 0)
+}
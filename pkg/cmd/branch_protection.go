@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewCheckBranchProtectionCommand returns the check-branch-protection command.
+func NewCheckBranchProtectionCommand(deps Deps) *cobra.Command {
+	return requireOperations(&cobra.Command{
+		Use:   "check-branch-protection [REPO ...]",
+		Short: "Check that teams referenced by branch protection rules exist in the local configuration and are not empty",
+		Long: `Checks the branch protection rules of the protected branches of the given
+repositories, or of all repositories of the organization if none are given,
+and reports rules referencing teams that were deleted, renamed or that have no
+members according to the local configuration. The teams allowed to dismiss or
+to bypass the required pull request reviews and the teams allowed to push are
+checked. Required reviewers are set by CODEOWNERS files, checked by
+'codeowners check'.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			tm := team.NewManager(ghClient, nil, orgName)
+
+			repos := args
+			if len(repos) == 0 {
+				if repos, err = tm.ListRepositories(cmd.Context()); err != nil {
+					return fmt.Errorf("failed to list repositories: %w", err)
+				}
+			}
+
+			issues, err := tm.CheckBranchProtections(cmd.Context(), cfg, repos)
+			if err != nil {
+				return fmt.Errorf("failed to check branch protections: %w", err)
+			}
+			for _, issue := range issues {
+				fmt.Println(issue)
+			}
+			if len(issues) != 0 {
+				return fmt.Errorf("found %d stale team references in branch protection rules", len(issues))
+			}
+			return nil
+		},
+	}, github.OperationReadRepositories, github.OperationReadBranchProtections)
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewCheckCommand returns the check command.
+func NewCheckCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		failOn      string
+		knownDrifts string
+	}
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Report the drift between the local configuration and GitHub, classified by severity",
+		Long: `Compares the local configuration with the upstream configuration and reports
+every drift with its severity, as configured in 'drift.severities' of the
+configuration. Fails if any drift is at least as severe as --fail-on.
+
+With --known-drifts, the drifts found are recorded in the given file, and
+only the drifts that weren't recorded by the previous run fail the check, so
+that a scheduled check only alerts on new drift.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			threshold := cfg.Drift.Threshold()
+			if opts.failOn != "" {
+				threshold = config.Severity(opts.failOn)
+				if !threshold.IsValid() {
+					return fmt.Errorf("invalid severity %q", opts.failOn)
+				}
+			}
+
+			drifts, err := computeDrifts(cmd.Context(), deps, cfg)
+			if err != nil {
+				return err
+			}
+			if opts.knownDrifts == "" {
+				for _, d := range drifts {
+					fmt.Println(d)
+				}
+				if failing := team.DriftsAtLeast(drifts, threshold); len(failing) != 0 {
+					return fmt.Errorf("found %d drifts with severity %s or higher", len(failing), threshold)
+				}
+				fmt.Printf("No drift with severity %s or higher\n", threshold)
+				return nil
+			}
+
+			known, err := loadKnownDrifts(opts.knownDrifts)
+			if err != nil {
+				return err
+			}
+			var newDrifts []team.Drift
+			for _, d := range drifts {
+				if _, ok := known[d.Hash()]; ok {
+					fmt.Printf("%s (known)\n", d)
+					continue
+				}
+				fmt.Printf("%s (new)\n", d)
+				newDrifts = append(newDrifts, d)
+			}
+			if err := storeKnownDrifts(opts.knownDrifts, drifts); err != nil {
+				return err
+			}
+			if failing := team.DriftsAtLeast(newDrifts, threshold); len(failing) != 0 {
+				return fmt.Errorf("found %d new drifts with severity %s or higher", len(failing), threshold)
+			}
+			fmt.Printf("No new drift with severity %s or higher\n", threshold)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.failOn, "fail-on", "", "Minimum severity of drift that fails the check, one of: info, warning, critical (default from the 'drift.failOn' of the configuration)")
+	cmd.Flags().StringVar(&opts.knownDrifts, "known-drifts", "", "File recording the drifts found by the previous run, only new drifts fail the check")
+
+	return requireOperations(cmd, github.OperationReadTeams)
+}
+
+// computeDrifts returns the drifts between cfg and the upstream configuration.
+func computeDrifts(ctx context.Context, deps Deps, cfg *config.Config) ([]team.Drift, error) {
+	ghGraphQLClient, err := deps.NewGraphQLClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create github graphql client: %w", err)
+	}
+	upstreamCfg, err := team.NewManager(nil, ghGraphQLClient, orgName).GetCurrentConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config from GitHub: %w", err)
+	}
+	return team.ComputeDrift(team.EffectiveConfig(cfg, upstreamCfg, time.Now()), upstreamCfg), nil
+}
+
+// loadKnownDrifts returns the hashes of the drifts recorded in filename, which
+// holds a drift per line, starting with its hash. A missing file records no
+// drift.
+func loadKnownDrifts(filename string) (map[string]struct{}, error) {
+	f, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]struct{}{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open known drifts: %w", err)
+	}
+	defer f.Close()
+
+	known := map[string]struct{}{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) != 0 {
+			known[fields[0]] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read known drifts: %w", err)
+	}
+	return known, nil
+}
+
+// storeKnownDrifts records drifts in filename, replacing the drifts recorded
+// previously.
+func storeKnownDrifts(filename string, drifts []team.Drift) error {
+	var sb strings.Builder
+	for _, d := range drifts {
+		fmt.Fprintf(&sb, "%s %s\n", d.Hash(), d)
+	}
+	if err := os.WriteFile(filename, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write known drifts: %w", err)
+	}
+	return nil
+}
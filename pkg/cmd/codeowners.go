@@ -0,0 +1,358 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/renameio"
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewCodeOwnersCommand returns the codeowners command.
+func NewCodeOwnersCommand(deps Deps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "codeowners",
+		Short: "Manage CODEOWNERS files",
+	}
+
+	cmd.AddCommand(
+		newCodeOwnersCheckCommand(deps),
+		newCodeOwnersCoverageCommand(deps),
+		newCodeOwnersGenerateCommand(deps),
+		newCodeOwnersImportCommand(deps),
+	)
+
+	return cmd
+}
+
+// newCodeOwnersCheckCommand returns the codeowners check command.
+func newCodeOwnersCheckCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		repo string
+	}
+	cmd := &cobra.Command{
+		Use:   "check [FILE ...]",
+		Short: "Check that the owners of CODEOWNERS files exist",
+		Long: `Checks that every @org/team and @user owner of the given CODEOWNERS files,
+by default the ones found in the current directory where GitHub looks them up,
+resolves to a non-empty team or to a member of the organization. Owners are
+resolved against the configuration first and against GitHub otherwise. A typo
+in an owner silently disables the review requests of its paths.
+
+The teams must also have write permission on the repository containing the
+files, --repo or by default the repository of the origin remote of the current
+git repository, since GitHub ignores the teams without.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			if len(args) == 0 {
+				for _, file := range team.CodeOwnersPaths {
+					if _, err := os.Stat(file); err == nil {
+						args = append(args, file)
+					}
+				}
+				if len(args) == 0 {
+					return fmt.Errorf("no CODEOWNERS file found")
+				}
+			}
+			files := make(map[string]string, len(args))
+			for _, file := range args {
+				content, err := os.ReadFile(file)
+				if err != nil {
+					return fmt.Errorf("failed to read CODEOWNERS: %w", err)
+				}
+				files[file] = string(content)
+			}
+
+			repo := opts.repo
+			if repo == "" {
+				if repo = originRepository(orgName); repo == "" {
+					fmt.Fprintf(os.Stderr, "[WARN]: repository of the CODEOWNERS files unknown, not checking the permissions of teams, set --repo to check them\n")
+				}
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			issues, err := team.NewManager(ghClient, nil, orgName).CheckCodeOwners(cmd.Context(), cfg, repo, files)
+			if err != nil {
+				return fmt.Errorf("failed to check CODEOWNERS: %w", err)
+			}
+			for _, issue := range issues {
+				fmt.Println(issue)
+			}
+			if len(issues) != 0 {
+				return fmt.Errorf("found %d invalid owners in CODEOWNERS files", len(issues))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.repo, "repo", "", "Repository of the organization containing the CODEOWNERS files (default the repository of the origin remote)")
+
+	return requireOperations(cmd, github.OperationReadTeams, github.OperationReadRepositories)
+}
+
+// originRemote matches the GitHub SSH and HTTPS URLs of git remotes,
+// capturing the owner and the name of the repository.
+var originRemote = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// originRepository returns the name of the repository of the given
+// organization the origin remote of the git repository of the current
+// directory points to, or an empty string if there is none.
+func originRepository(org string) string {
+	out, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return ""
+	}
+	m := originRemote.FindStringSubmatch(strings.TrimSpace(string(out)))
+	if m == nil || !strings.EqualFold(m[1], org) {
+		return ""
+	}
+	return m[2]
+}
+
+// newCodeOwnersCoverageCommand returns the codeowners coverage command.
+func newCodeOwnersCoverageCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		dir    string
+		repo   string
+		top    int
+		format string
+	}
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Report the paths without owner and the teams owning the most paths",
+		Long: `Matches the files of a repository against its CODEOWNERS file and reports the
+files without owner, collapsed into their directory when none of the files
+below it have an owner, and the teams owning the most files, e.g. to restructure
+ownership.
+
+The files tracked by git in the checkout --dir are used by default, or the
+files of the default branch of the repository --repo of the organization.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var (
+				content   string
+				files     []string
+				truncated bool
+			)
+			if opts.repo != "" {
+				ghClient, err := deps.NewClient()
+				if err != nil {
+					return fmt.Errorf("failed to create github client: %w", err)
+				}
+				content, files, truncated, err = team.NewManager(ghClient, nil, orgName).GetCodeOwnersTree(cmd.Context(), opts.repo)
+				if err != nil {
+					return err
+				}
+			} else {
+				var err error
+				if content, files, err = checkoutCodeOwners(opts.dir); err != nil {
+					return err
+				}
+			}
+			if content == "" {
+				return fmt.Errorf("no CODEOWNERS file found")
+			}
+			if truncated {
+				fmt.Fprintf(os.Stderr, "[WARN]: repository %s has too many files to list them all, the coverage is partial\n", opts.repo)
+			}
+			rules, err := team.ParseCodeOwners(content)
+			if err != nil {
+				return fmt.Errorf("failed to parse CODEOWNERS: %w", err)
+			}
+
+			coverage := team.ComputeCodeOwnersCoverage(rules, files)
+			if opts.top > 0 && len(coverage.Teams) > opts.top {
+				coverage.Teams = coverage.Teams[:opts.top]
+			}
+			switch opts.format {
+			case "text":
+				fmt.Printf("%d of %d files without owner\n", coverage.UnownedFiles, coverage.Files)
+				for _, p := range coverage.Unowned {
+					fmt.Printf("  %s\n", p)
+				}
+				if len(coverage.Teams) == 0 {
+					return nil
+				}
+				fmt.Println()
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "TEAM\tFILES")
+				for _, t := range coverage.Teams {
+					fmt.Fprintf(w, "%s\t%d\n", t.Owner, t.Paths)
+				}
+				return w.Flush()
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err = enc.Encode(coverage); err != nil {
+					return fmt.Errorf("failed to write coverage: %w", err)
+				}
+				return nil
+			default:
+				return fmt.Errorf("unknown coverage format %q", opts.format)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.dir, "dir", ".", "Checkout of the repository")
+	cmd.Flags().StringVar(&opts.repo, "repo", "", "Repository of the organization to retrieve the files of instead of a checkout")
+	cmd.Flags().IntVar(&opts.top, "top", 10, "Number of teams owning the most files to report, 0 for all")
+	cmd.Flags().StringVar(&opts.format, "format", "text", "Output format, one of: text, json")
+
+	return requireOperations(cmd, github.OperationReadRepositories)
+}
+
+// checkoutCodeOwners returns the content of the CODEOWNERS file GitHub would
+// use for the git checkout in dir and the paths of the files tracked in it.
+func checkoutCodeOwners(dir string) (content string, files []string, err error) {
+	for _, p := range team.CodeOwnersPaths {
+		data, err := os.ReadFile(filepath.Join(dir, p))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return "", nil, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+		}
+		content = string(data)
+		break
+	}
+	out, err := exec.Command("git", "-C", dir, "ls-files", "-z").Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list files of %s: %w", dir, err)
+	}
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return content, files, nil
+}
+
+// newCodeOwnersGenerateCommand returns the codeowners generate command.
+func newCodeOwnersGenerateCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		out string
+	}
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Render a CODEOWNERS file from the owned paths of the teams",
+		Long: `Renders a CODEOWNERS file assigning the 'ownedPaths' of every team of the
+configuration to that team, so that path ownership and team membership are
+kept in the configuration. The file is printed unless --out is set.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			content := team.GenerateCodeOwners(cfg, orgName)
+			if opts.out == "" {
+				fmt.Print(content)
+				return nil
+			}
+			if err = renameio.WriteFile(opts.out, []byte(content), 0o644); err != nil {
+				return fmt.Errorf("failed to write CODEOWNERS: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.out, "out", "", "Write the CODEOWNERS file to this file, e.g. .github/CODEOWNERS")
+
+	return cmd
+}
+
+// newCodeOwnersImportCommand returns the codeowners import command.
+func newCodeOwnersImportCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		repos  []string
+		dryRun bool
+	}
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Add the teams referenced by CODEOWNERS files to local configuration",
+		Long: `Scans the CODEOWNERS files of the default branch of the repositories of the
+organization for teams that aren't in the local configuration yet, and adds
+them to it together with their current members, as retrieved from GitHub.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+			tm := team.NewManager(ghClient, ghGraphQLClient, orgName)
+
+			repos := opts.repos
+			if len(repos) == 0 {
+				if repos, err = tm.ListRepositories(cmd.Context()); err != nil {
+					return fmt.Errorf("failed to list repositories: %w", err)
+				}
+			}
+			fmt.Printf("Scanning CODEOWNERS files of %d repositories...\n", len(repos))
+			slugs, err := tm.FindCodeOwnersTeams(cmd.Context(), repos)
+			if err != nil {
+				return fmt.Errorf("failed to find teams referenced by CODEOWNERS: %w", err)
+			}
+			upstreamCfg, err := tm.GetCurrentConfig(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to read config from GitHub: %w", err)
+			}
+
+			imported, unknown := team.ImportTeams(cfg, upstreamCfg, slugs)
+			for _, slug := range unknown {
+				fmt.Fprintf(os.Stderr, "[WARN]: team %s referenced by CODEOWNERS does not exist in organization %s\n", slug, orgName)
+			}
+			if len(imported) == 0 {
+				fmt.Println("No team to import")
+				return nil
+			}
+			for _, teamName := range imported {
+				fmt.Printf("Importing team %s with %d members\n", teamName, len(cfg.Teams[teamName].Members))
+			}
+			if opts.dryRun {
+				return nil
+			}
+
+			if redacting(cfg) {
+				team.RedactMembers(cfg)
+			}
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.repos, "repos", nil, "Repositories whose CODEOWNERS files are scanned (default all repositories)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the teams without adding them to the configuration")
+
+	return requireOperations(cmd, github.OperationReadTeams, github.OperationReadRepositories)
+}
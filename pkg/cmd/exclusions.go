@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// NewExclusionsCommand returns the exclusions command.
+func NewExclusionsCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		olderThan int
+	}
+	cmd := &cobra.Command{
+		Use:   "exclusions",
+		Short: "List all code review assignment exclusions with their reasons and ages",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			now := time.Now()
+			staleBefore := now.AddDate(0, -opts.olderThan, 0)
+
+			teamNames := make([]string, 0, len(cfg.Teams))
+			for teamName := range cfg.Teams {
+				teamNames = append(teamNames, teamName)
+			}
+			sort.Strings(teamNames)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TEAM\tMEMBER\tSINCE\tUNTIL\tAGE\tREASON\t")
+			var stale, expired int
+			for _, teamName := range teamNames {
+				for _, xMember := range cfg.ExcludedMembers(teamName) {
+					since, _ := xMember.SinceDate()
+					age, flag := "unknown", ""
+					if !since.IsZero() {
+						age = fmt.Sprintf("%dd", int(now.Sub(since).Hours()/24))
+					}
+					switch {
+					case xMember.Expired(now):
+						flag = "EXPIRED"
+						expired++
+					case !since.IsZero() && since.Before(staleBefore) && xMember.Until == "":
+						flag = "REVIEW"
+						stale++
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", teamName, xMember.Login, orDash(xMember.Since), orDash(xMember.Until), age, orDash(xMember.Reason), flag)
+				}
+			}
+			for _, login := range cfg.ExcludeCRAFromAllTeams {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", "(all teams)", login, "-", "-", "unknown", "excluded from all teams")
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if stale != 0 {
+				fmt.Printf("\n%d exclusions are older than %d months and should be reviewed\n", stale, opts.olderThan)
+			}
+			if expired != 0 {
+				fmt.Printf("\n%d exclusions expired, remove them or run push with --prune-exclusions\n", expired)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.olderThan, "older-than", 6, "Flag exclusions older than this number of months for review")
+
+	return cmd
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/export"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewExportCommand returns the export command.
+func NewExportCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		format      string
+		output      string
+		ldapBaseDN  string
+		groupDomain string
+	}
+	cmd := &cobra.Command{
+		Use:   "export [TEAM ...]",
+		Short: "Export team membership from the local configuration for mailing list synchronization",
+		Long: `Exports the membership of the given teams, or of all teams if none are given,
+as LDIF entries or as a Google Groups bulk upload CSV. Members are mapped to
+email addresses with the 'email' field of the members in the configuration.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.format {
+			case "ldif":
+			case "google-groups":
+				if opts.groupDomain == "" {
+					return fmt.Errorf("--group-domain is required for the google-groups format")
+				}
+			default:
+				return fmt.Errorf("unknown export format %q", opts.format)
+			}
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			if redacting(cfg) {
+				if opts.format == "google-groups" {
+					return fmt.Errorf("the google-groups format requires the email addresses of the members, which are redacted")
+				}
+				team.RedactMembers(cfg)
+			}
+
+			teams := args
+			if len(teams) == 0 {
+				for teamName := range cfg.Teams {
+					teams = append(teams, teamName)
+				}
+				sort.Strings(teams)
+			}
+			for _, teamName := range teams {
+				if _, ok := cfg.Teams[teamName]; !ok {
+					return fmt.Errorf("unknown team %q", teamName)
+				}
+			}
+
+			var w io.Writer = os.Stdout
+			if opts.output != "-" {
+				f, err := os.Create(opts.output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if opts.format == "ldif" {
+				err = export.WriteLDIF(w, cfg, teams, opts.ldapBaseDN)
+			} else {
+				err = export.WriteGoogleGroupsCSV(w, cfg, teams, opts.groupDomain)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to export teams: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "ldif", "Export format, one of: ldif, google-groups")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "-", "File to write the export to, '-' for stdout")
+	cmd.Flags().StringVar(&opts.ldapBaseDN, "ldap-base-dn", "dc=example,dc=org", "Base DN of the LDIF entries")
+	cmd.Flags().StringVar(&opts.groupDomain, "group-domain", "", "Domain of the Google Groups, each team is exported as <team-slug>@<domain>")
+
+	return cmd
+}
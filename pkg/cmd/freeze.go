@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/freeze"
+)
+
+// checkFreeze returns an error if changes are frozen by one of the freeze
+// windows of cfg, unless override is set by --override-freeze.
+func checkFreeze(ctx context.Context, cfg *config.Config, override bool) error {
+	w, err := freeze.Active(ctx, cfg.Freeze, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to check freeze windows: %w", err)
+	}
+	if w == nil {
+		return nil
+	}
+	if override {
+		fmt.Printf("Overriding freeze window %s\n", w)
+		return nil
+	}
+	return fmt.Errorf("changes are frozen by freeze window %s, use --override-freeze to apply them anyway", w)
+}
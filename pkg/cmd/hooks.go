@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// hookMarker marks the git hooks installed by install-hooks, which are
+// overwritten without --force.
+const hookMarker = "# Installed by team-manager install-hooks."
+
+// NewInstallHooksCommand returns the install-hooks command.
+func NewInstallHooksCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		hooks            []string
+		binary           string
+		force            bool
+		snapshotFilename string
+	}
+	cmd := &cobra.Command{
+		Use:   "install-hooks",
+		Short: "Install git hooks validating the config before it is committed or pushed",
+		Long: `Installs git hooks into the repository of the config file, failing the commit
+or push if the config doesn't pass lint --check, i.e. is invalid or not
+formatted, or if preview fails against the last snapshot of the organization,
+when there is one. The hooks never access the network.
+
+The hooks check the config file of the working tree, including changes that
+aren't staged. Existing hooks not installed by this command are only
+overwritten with --force.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			for _, hook := range opts.hooks {
+				if hook != "pre-commit" && hook != "pre-push" {
+					return fmt.Errorf("unknown hook %q, must be pre-commit or pre-push", hook)
+				}
+			}
+			bin := opts.binary
+			if bin == "" {
+				var err error
+				if bin, err = os.Executable(); err != nil {
+					return fmt.Errorf("failed to find team-manager binary: %w", err)
+				}
+			}
+
+			dir := filepath.Dir(configFilename)
+			top, err := gitRevParse(dir, "--show-toplevel")
+			if err != nil {
+				return fmt.Errorf("config %s is not in a git repository: %w", configFilename, err)
+			}
+			hooksDir, err := gitRevParse(dir, "--git-path", "hooks")
+			if err != nil {
+				return fmt.Errorf("failed to find git hooks directory: %w", err)
+			}
+			if !filepath.IsAbs(hooksDir) {
+				hooksDir = filepath.Join(dir, hooksDir)
+			}
+			cfgFile, err := relativeTo(top, configFilename)
+			if err != nil {
+				return err
+			}
+			snapshotFile, err := relativeTo(top, opts.snapshotFilename)
+			if err != nil {
+				return err
+			}
+
+			script := hookScript(bin, cfgFile, snapshotFile)
+			if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+				return fmt.Errorf("failed to create git hooks directory: %w", err)
+			}
+			for _, hook := range opts.hooks {
+				file := filepath.Join(hooksDir, hook)
+				if current, err := os.ReadFile(file); err == nil && !bytes.Contains(current, []byte(hookMarker)) && !opts.force {
+					return fmt.Errorf("git hook %s already exists, use --force to overwrite it", file)
+				}
+				if err := os.WriteFile(file, []byte(script), 0o755); err != nil {
+					return fmt.Errorf("failed to write git hook: %w", err)
+				}
+				// WriteFile keeps the mode of existing files.
+				if err := os.Chmod(file, 0o755); err != nil {
+					return fmt.Errorf("failed to make git hook executable: %w", err)
+				}
+				fmt.Printf("Installed git hook %s\n", file)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.hooks, "hooks", []string{"pre-commit"}, "Git hooks to install, among: pre-commit, pre-push")
+	cmd.Flags().StringVar(&opts.binary, "binary", "", "team-manager binary run by the hooks (default the running binary)")
+	cmd.Flags().BoolVar(&opts.force, "force", false, "Overwrite existing hooks not installed by team-manager")
+	cmd.Flags().StringVar(&opts.snapshotFilename, "snapshot-filename", "upstream-snapshot.yaml", "Snapshot filename previewed against by the hooks")
+
+	return cmd
+}
+
+// hookScript returns the git hook running the given team-manager binary to
+// check the given config file and to preview it against the given snapshot,
+// both relative to the top-level directory of the repository.
+func hookScript(bin, cfgFile, snapshotFile string) string {
+	tm := shellQuote(bin) + " --offline --config-filename " + shellQuote(cfgFile)
+	return fmt.Sprintf(`#!/bin/sh
+%s
+set -e
+cd "$(git rev-parse --show-toplevel)"
+%s lint --check
+if [ -f %s ]; then
+	%s preview --snapshot-filename %s >/dev/null
+fi
+`, hookMarker, tm, shellQuote(snapshotFile), tm, shellQuote(snapshotFile))
+}
+
+// gitRevParse returns the output of git rev-parse with the given arguments in
+// the given directory.
+func gitRevParse(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir, "rev-parse"}, args...)...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(out)), nil
+}
+
+// relativeTo returns the path of file relative to dir.
+func relativeTo(dir, file string) (string, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", file, err)
+	}
+	// The top-level directory reported by git has its symlinks resolved.
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		abs = filepath.Join(resolved, filepath.Base(abs))
+	}
+	return filepath.Rel(dir, abs)
+}
+
+// shellQuote returns s quoted for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
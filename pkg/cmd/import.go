@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/mailinglist"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// NewImportMembersCommand returns the import-members command.
+func NewImportMembersCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		format string
+		apply  bool
+	}
+	cmd := &cobra.Command{
+		Use:   "import-members TEAM FILE",
+		Short: "Reconcile the members of a team with the roster of a mailing list",
+		Long: `Compares the members of a team in the local configuration with a mailing list
+roster, either a member export of Google Groups (CSV) or the output of
+mailman's 'list_members'. Email addresses are mapped to members with the
+'email' field of the members in the configuration.
+
+The differences are reported, and applied to the local configuration with
+--apply. Run 'push' afterwards to synchronize them into GitHub.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			teamName, file := args[0], args[1]
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			teamCfg, ok := cfg.Teams[teamName]
+			if !ok {
+				return fmt.Errorf("unknown team %q", teamName)
+			}
+
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			var emails []string
+			switch opts.format {
+			case "google-groups":
+				emails, err = mailinglist.ParseGoogleGroupsCSV(f)
+			case "mailman":
+				emails, err = mailinglist.ParseMailmanRoster(f)
+			default:
+				return fmt.Errorf("unknown roster format %q", opts.format)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to parse roster: %w", err)
+			}
+
+			loginsByEmail := map[string]string{}
+			for login, user := range cfg.Members {
+				if user.Email != "" {
+					loginsByEmail[strings.ToLower(user.Email)] = login
+				}
+			}
+			listMembers := set.New[string]()
+			var unknown []string
+			for _, email := range emails {
+				login, ok := loginsByEmail[strings.ToLower(email)]
+				if !ok {
+					unknown = append(unknown, email)
+					continue
+				}
+				listMembers.Add(login)
+			}
+
+			toAdd := set.DifferenceFold(listMembers.Elements(), teamCfg.Members)
+			toDel := set.DifferenceFold(teamCfg.Members, listMembers.Elements())
+			fmt.Printf(" Team: %s\n", teamName)
+			fmt.Printf("    Adding members: %s\n", strings.Join(toAdd, ", "))
+			fmt.Printf("  Removing members: %s\n", strings.Join(toDel, ", "))
+			if len(unknown) != 0 {
+				fmt.Printf("Unknown email addresses, add them to the members of the configuration: %s\n", strings.Join(unknown, ", "))
+			}
+
+			if !opts.apply || (len(toAdd) == 0 && len(toDel) == 0) {
+				return nil
+			}
+			if err = setTeamMembers(teamName, listMembers.Elements(), cfg); err != nil {
+				return fmt.Errorf("failed to set team members: %w", err)
+			}
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "google-groups", "Roster format, one of: google-groups, mailman")
+	cmd.Flags().BoolVar(&opts.apply, "apply", false, "Set the team members in the local configuration to the members of the mailing list")
+
+	return cmd
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewInitCommand returns the init command.
+func NewInitCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		omitMemberNames bool
+	}
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Initializing the config file by fetching team assignments from GitHub",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+
+			tm := team.NewManager(ghClient, ghGraphQLClient, orgName)
+
+			if _, err := deps.LoadState(configFilename); err == nil {
+				fmt.Printf("Configuration file %q already exists\n", configFilename)
+				return nil
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			fmt.Println("Retrieving configuration from organization...")
+			remoteCfg, err := tm.GetCurrentConfig(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to read config from GitHub: %w", err)
+			}
+			remoteCfg.Policy.OmitMemberNames = opts.omitMemberNames
+
+			fmt.Printf("Creating configuration file %q...\n", configFilename)
+			if err = deps.StoreState(configFilename, remoteCfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.omitMemberNames, "omit-member-names", false, "Do not store the names of the members, only their logins and IDs")
+
+	return requireOperations(cmd, github.OperationReadTeams)
+}
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	gh "github.com/google/go-github/v33/github"
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// NewInvitationsCommand returns the invitations command.
+func NewInvitationsCommand(deps Deps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "invitations",
+		Short: "List the pending invitations to the organization and the team members that didn't accept theirs",
+		Long: `Lists the pending invitations to the organization and reports the team members
+of the configuration that didn't accept their invitation yet: GitHub only
+adds them to their teams once they accept it, so they show up as missing team
+members until then, however often the configuration is pushed.
+
+Stale invitations are canceled with 'invitations cancel'.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			tm := team.NewManager(ghClient, nil, orgName)
+			invitations, err := tm.ListOrgInvitations(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list invitations: %w", err)
+			}
+			if len(invitations) == 0 {
+				fmt.Println("No pending invitations")
+				return nil
+			}
+
+			pending := team.PendingTeamMemberships(cfg, invitations)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "LOGIN\tEMAIL\tROLE\tINVITED\tINVITER\tTEAMS")
+			for _, invitation := range invitations {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					orDash(invitation.GetLogin()), orDash(redact(cfg, invitation.GetEmail())), invitation.GetRole(),
+					invitation.GetCreatedAt().Format(config.DateFormat), orDash(invitation.GetInviter().GetLogin()),
+					orDash(strings.Join(pending[strings.ToLower(invitation.GetLogin())], ", ")))
+			}
+			if err = w.Flush(); err != nil {
+				return err
+			}
+			for _, invitation := range invitations {
+				if teams, ok := pending[strings.ToLower(invitation.GetLogin())]; ok {
+					fmt.Fprintf(os.Stderr, "[WARN]: %s didn't accept the invitation to the organization sent %s ago, their membership of teams %s is pending\n",
+						invitation.GetLogin(), time.Since(invitation.GetCreatedAt()).Round(time.Hour), strings.Join(teams, ", "))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(newInvitationsCancelCommand(deps))
+
+	return requireOperations(cmd, github.OperationReadTeams)
+}
+
+// newInvitationsCancelCommand returns the invitations cancel command.
+func newInvitationsCancelCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		maxAge        int
+		cancelUnknown bool
+		dryRun        bool
+		force         bool
+	}
+	cmd := &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel the invitations to the organization that are too old or of users removed from the configuration",
+		Long: `Cancels the pending invitations to the organization of users that aren't
+members of the configuration nor of any of its teams, e.g. because they were
+removed from it, and, with --max-age, the invitations older than the given
+number of days.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			if opts.maxAge < 0 {
+				return fmt.Errorf("invalid max age %d, must not be negative", opts.maxAge)
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			tm := team.NewManager(ghClient, nil, orgName)
+			invitations, err := tm.ListOrgInvitations(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list invitations: %w", err)
+			}
+
+			maxAge := time.Duration(opts.maxAge) * 24 * time.Hour
+			stale := team.StaleInvitations(cfg, invitations, maxAge, opts.cancelUnknown, time.Now())
+			if len(stale) == 0 {
+				fmt.Println("No stale invitations")
+				return nil
+			}
+
+			fmt.Println("Going to cancel the following invitations:")
+			for _, invitation := range stale {
+				fmt.Printf(" User: %s, %s\n", invitee(cfg, invitation.Invitation), invitation.Reason)
+			}
+			if opts.dryRun {
+				return nil
+			}
+			if err = preflight(cmd.Context(), ghClient, github.OperationManageTeams); err != nil {
+				return err
+			}
+			if !opts.force {
+				yes, err := terminal.AskForConfirmation("Continue?")
+				if err != nil {
+					return err
+				}
+				if !yes {
+					return nil
+				}
+			}
+
+			var failed int
+			for _, invitation := range stale {
+				if err := tm.CancelOrgInvitation(cmd.Context(), invitation.GetID()); err != nil {
+					fmt.Fprintf(os.Stderr, "[ERROR]: Unable to cancel invitation of %s: %s\n", invitee(cfg, invitation.Invitation), github.TranslateError(err))
+					failed++
+				}
+			}
+			if failed != 0 {
+				return fmt.Errorf("failed to cancel %d invitations", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.maxAge, "max-age", 0, "Also cancel the invitations older than this number of days, 0 for no limit")
+	cmd.Flags().BoolVar(&opts.cancelUnknown, "unknown", true, "Cancel the invitations of users that aren't members of the configuration nor of any of its teams")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Dry run the steps without performing any write operation to GitHub")
+	cmd.Flags().BoolVar(&opts.force, "opts.force", false, "Cancel the invitations without asking for confirmation")
+
+	return requireOperations(cmd, github.OperationReadTeams, github.OperationManageTeams)
+}
+
+// invitee returns the login of the user of the given invitation, or the email
+// the invitation was sent to unless redacted.
+func invitee(cfg *config.Config, invitation *gh.Invitation) string {
+	if login := invitation.GetLogin(); login != "" {
+		return login
+	}
+	return redact(cfg, invitation.GetEmail())
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/persistence"
+)
+
+// NewLintCommand returns the lint command.
+func NewLintCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		check bool
+	}
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Checks and formats local config",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+
+			localCfg, err := deps.LoadState(configFilename)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			err = config.SanityCheck(localCfg)
+			if err != nil {
+				return fmt.Errorf("failed to perform sanity check: %w", err)
+			}
+
+			if opts.check {
+				return checkFormat(localCfg)
+			}
+
+			if err = deps.StoreState(configFilename, localCfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.check, "check", false, "Fail if the config isn't formatted instead of formatting it, e.g. in git hooks")
+
+	return cmd
+}
+
+// checkFormat returns an error if the config file isn't formatted as cfg,
+// loaded from it, would be stored.
+func checkFormat(cfg *config.Config) error {
+	current, err := os.ReadFile(configFilename)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	formatted, err := persistence.FormatState(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to format config: %w", err)
+	}
+	if !bytes.Equal(current, formatted) {
+		return fmt.Errorf("config %s is not formatted, run lint to format it", configFilename)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/keyring"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// NewLoginCommand returns the login command.
+func NewLoginCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		device   bool
+		clientID string
+		scopes   []string
+	}
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Store a GitHub token in the OS keyring",
+		Long: `Asks for a GitHub token and stores it in the credential store of the
+operating system (macOS Keychain, Secret Service on Linux or Windows
+Credential Manager). All other commands use the stored token if GITHUB_TOKEN
+is not set and the GitHub CLI isn't authenticated, see --auth-providers. The
+token can also be piped to stdin, e.g.
+
+  team-manager login < token.txt
+
+With --device, the token is obtained interactively with the GitHub OAuth
+device authorization flow of an OAuth app of the organization that has the
+device flow enabled, given with --client-id.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var (
+				token string
+				err   error
+			)
+			if opts.device {
+				token, err = deviceLogin(cmd, opts.clientID, opts.scopes)
+			} else {
+				token, err = terminal.AskForSecret("Paste a GitHub token with the admin:org scope")
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read token: %w", err)
+			}
+			if token == "" {
+				return fmt.Errorf("no token given")
+			}
+
+			user, resp, err := github.NewClient(token).Users.Get(cmd.Context(), "")
+			if err != nil {
+				return fmt.Errorf("failed to validate token: %w", err)
+			}
+			if !strings.Contains(resp.Header.Get("X-OAuth-Scopes"), "admin:org") {
+				fmt.Fprintf(os.Stderr, "[WARN]: Token does not have the admin:org scope, some commands will fail\n")
+			}
+
+			if err := keyring.Set(github.KeyringUser, token); err != nil {
+				return fmt.Errorf("failed to store token in keyring: %w", err)
+			}
+			fmt.Printf("Logged in as %s\n", user.GetLogin())
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.device, "device", false, "Obtain the token with the OAuth device authorization flow instead of asking for it")
+	cmd.Flags().StringVar(&opts.clientID, "client-id", os.Getenv("TEAM_MANAGER_OAUTH_CLIENT_ID"), "Client ID of the OAuth app used by --device (default $TEAM_MANAGER_OAUTH_CLIENT_ID)")
+	cmd.Flags().StringSliceVar(&opts.scopes, "scopes", []string{"admin:org", "repo"}, "Scopes requested by --device")
+
+	return cmd
+}
+
+// deviceLogin obtains a token with the OAuth device authorization flow of the
+// OAuth app clientID, requesting the given scopes.
+func deviceLogin(cmd *cobra.Command, clientID string, scopes []string) (string, error) {
+	if clientID == "" {
+		return "", fmt.Errorf("--client-id must be set to use the device flow")
+	}
+	code, err := github.RequestDeviceCode(cmd.Context(), clientID, scopes)
+	if err != nil {
+		return "", fmt.Errorf("failed to request device code: %w", err)
+	}
+	fmt.Printf("Open %s and enter the code %s\n", code.VerificationURI, code.UserCode)
+	return github.WaitForDeviceToken(cmd.Context(), clientID, code)
+}
+
+// NewLogoutCommand returns the logout command.
+func NewLogoutCommand(deps Deps) *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the GitHub token stored by 'login' from the OS keyring",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			err := keyring.Delete(github.KeyringUser)
+			if errors.Is(err, keyring.ErrNotFound) {
+				fmt.Printf("Not logged in\n")
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to remove token from keyring: %w", err)
+			}
+			fmt.Printf("Logged out\n")
+			return nil
+		},
+	}
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewMembershipsCommand returns the memberships command.
+func NewMembershipsCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		maxTeams int
+	}
+	cmd := &cobra.Command{
+		Use:   "memberships",
+		Short: "List the members belonging to too many teams or to mutually exclusive teams",
+		Long: `Lists the members belonging to more teams than the 'maxTeams' of the policy,
+which dilutes code review assignments, and the members belonging to more than
+one team of any group of the 'exclusiveTeams' of the policy, which violates
+least privilege.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			maxTeams := cfg.Policy.MaxTeams
+			if cmd.Flags().Changed("max-teams") {
+				maxTeams = opts.maxTeams
+			}
+			violations := team.CheckMemberships(cfg, maxTeams)
+			if len(violations) == 0 {
+				fmt.Println("No membership violations")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "MEMBER\tTEAMS\tREASON")
+			for _, v := range violations {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", v.Login, strings.Join(v.Teams, ", "), v.Reason)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.maxTeams, "max-teams", 0, "Flag members of more than this number of teams, overriding 'maxTeams' of the policy, 0 for no limit")
+
+	return cmd
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewMergeTeamsCommand returns the merge-teams command.
+func NewMergeTeamsCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		dryRun bool
+	}
+	cmd := &cobra.Command{
+		Use:   "merge-teams SRC DST",
+		Short: "Merge a team into another one in local configuration",
+		Long: `Merges the team SRC into the team DST in the local configuration: the members
+of SRC are added to DST along with their exclusions from the code review
+assignment, and the metadata, owned paths, maintainers and repositories of SRC
+are merged into the ones of DST. The child teams, onboarding rules, repository
+templates and exclusive teams of the policy referencing SRC are updated to
+reference DST.
+
+SRC is left without members nor code review assignment, so that 'push' empties
+it in GitHub, after which it can be retired with 'retire-team'. The changes
+'push' would submit are printed, computed against the upstream configuration.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, dst := args[0], args[1]
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			merge, err := team.MergeTeamsInConfig(cfg, src, dst)
+			if err != nil {
+				return fmt.Errorf("failed to merge teams: %w", err)
+			}
+			for _, warning := range merge.Warnings {
+				fmt.Fprintf(os.Stderr, "[WARN]: %s\n", warning)
+			}
+
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+			upstreamCfg, err := team.NewManager(nil, ghGraphQLClient, orgName).GetCurrentConfig(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to read config from GitHub: %w", err)
+			}
+			now := time.Now()
+			effectiveCfg := team.EffectiveConfig(cfg, upstreamCfg, now)
+			printPlan(team.ComputePlan(effectiveCfg, upstreamCfg), effectiveCfg)
+			fmt.Printf("Merged %d members of team %s into team %s, retire team %s with 'retire-team' once pushed\n", len(merge.Added), src, dst, src)
+			if opts.dryRun {
+				return nil
+			}
+
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the changes without storing them into the configuration")
+
+	return requireOperations(cmd, github.OperationReadTeams)
+}
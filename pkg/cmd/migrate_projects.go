@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// NewMigrateProjectsCommand returns the migrate-projects command.
+func NewMigrateProjectsCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		dryRun         bool
+		force          bool
+		overrideFreeze bool
+	}
+	cmd := &cobra.Command{
+		Use:   "migrate-projects",
+		Short: "Grant teams access to Projects according to their permissions on classic projects",
+		Long: `Lists the permissions of the teams of the configuration on classic organization
+projects and grants these teams the corresponding roles on the Projects the
+classic projects are migrated to, as configured in 'projectsMigration' of the
+configuration. Classic projects that aren't mapped to any Project are
+reported and left out.
+
+Permissions are migrated to the role of the same level, e.g. write to WRITER,
+unless mapped otherwise in 'projectsMigration.roles'.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			if !opts.dryRun {
+				if err = checkFreeze(cmd.Context(), cfg, opts.overrideFreeze); err != nil {
+					return err
+				}
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+			tm := team.NewManager(ghClient, ghGraphQLClient, orgName)
+
+			grants, err := tm.ListClassicProjectGrants(cmd.Context(), cfg)
+			if err != nil {
+				return fmt.Errorf("failed to list classic project permissions: %w", err)
+			}
+			migrations, unmapped := team.PlanProjectMigrations(cfg, grants)
+			for _, g := range unmapped {
+				fmt.Fprintf(os.Stderr, "[WARN]: Classic project #%d %q of team %s isn't mapped to any project, not migrating it\n", g.Project, g.ProjectName, g.Team)
+			}
+			if len(migrations) == 0 {
+				fmt.Printf("No classic project permissions to migrate\n")
+				return nil
+			}
+
+			fmt.Printf("Going to grant the following project roles:\n")
+			team.PrintProjectMigrations(os.Stdout, migrations)
+			if opts.dryRun {
+				return nil
+			}
+			if err = preflight(cmd.Context(), ghClient, github.OperationManageProjects); err != nil {
+				return err
+			}
+			if !opts.force {
+				yes, err := terminal.AskForConfirmation("Continue?")
+				if err != nil {
+					return err
+				}
+				if !yes {
+					return nil
+				}
+			}
+
+			if failed := tm.MigrateProjects(cmd.Context(), cfg, migrations); failed != 0 {
+				return fmt.Errorf("failed to migrate %d projects", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Dry run the steps without performing any write operation to GitHub")
+	cmd.Flags().BoolVar(&opts.force, "opts.force", false, "Grant the project roles without asking for confirmation")
+	cmd.Flags().BoolVar(&opts.overrideFreeze, "override-freeze", false, "Apply changes even during a freeze window")
+
+	return requireOperations(cmd, github.OperationReadTeams, github.OperationManageProjects)
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewMoveCommand returns the move command.
+func NewMoveCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		from   string
+		to     string
+		dryRun bool
+	}
+	cmd := &cobra.Command{
+		Use:   "move USER [USER ...] --from TEAM --to TEAM",
+		Short: "Move members from a team to another one in local configuration",
+		Long: `Moves the given members of a team to another team in the local configuration,
+together with their exclusions from the code review assignment of the team.
+Maintainers stay maintainers if the roles of the destination team are managed.
+Either all members are moved or, if any of them isn't a member of the team,
+none. The net membership changes are printed, to be pushed with 'push'.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := deps.LoadState(configFilename)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			users, err := findUsers(cfg, args)
+			if err != nil {
+				return fmt.Errorf("unable to find users: %w", err)
+			}
+
+			move, err := team.MoveMembersInConfig(cfg, users, opts.from, opts.to)
+			if err != nil {
+				return fmt.Errorf("failed to move members: %w", err)
+			}
+			fmt.Println("Team membership changes:")
+			plan := &team.Plan{TeamChanges: move.Changes}
+			plan.PrintTeamChanges(os.Stdout)
+			for _, xMember := range move.Exclusions {
+				fmt.Printf("Moving exclusion of %s from the code review assignment of team %s to team %s\n", xMember.Login, opts.from, opts.to)
+			}
+			if opts.dryRun {
+				return nil
+			}
+
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.from, "from", "", "Team the members are moved from")
+	cmd.Flags().StringVar(&opts.to, "to", "", "Team the members are moved to")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the changes without storing them into the configuration")
+	cmd.MarkFlagRequired("from")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
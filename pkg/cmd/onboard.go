@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/persistence"
+	"github.com/cilium/team-manager/pkg/set"
+	"github.com/cilium/team-manager/pkg/team"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// onboardOptions are the flags of the onboard command.
+type onboardOptions struct {
+	role            string
+	area            string
+	manager         string
+	pullRequestRepo string
+}
+
+// NewOnboardCommand returns the onboard command.
+func NewOnboardCommand(deps Deps) *cobra.Command {
+	opts := &onboardOptions{}
+	cmd := &cobra.Command{
+		Use:   "onboard USER",
+		Short: "Interactively add a new member to the local configuration and to the teams selected by the onboarding rules",
+		Long: `Adds a new member to the local configuration, with the profile retrieved from
+GitHub, and to the teams selected by the onboarding rules from the answers to
+a few questions.
+
+With --pull-request-repo, a pull request updating the configuration file in
+the given repository of the organization is also opened, at the path of the
+file relative to the top-level directory of its git repository.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+
+			cfg, err := deps.LoadState(configFilename)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			if err = addUsersToConfig(cmd.Context(), args, cfg, ghClient); err != nil {
+				return fmt.Errorf("failed to add user: %w", err)
+			}
+			login, err := findUser(cfg, args[0])
+			if err != nil {
+				return err
+			}
+
+			role, area, manager, err := askOnboardingQuestions(cfg, opts)
+			if err != nil {
+				return fmt.Errorf("failed to read onboarding answers: %w", err)
+			}
+
+			teams := onboardingTeams(cfg, role, area, manager)
+			if len(teams) == 0 {
+				fmt.Printf("No onboarding rule matches, %s will not be added to any team\n", login)
+			} else {
+				fmt.Printf("Adding %s to teams: %s\n", login, strings.Join(teams, ", "))
+			}
+			for _, t := range teams {
+				if err = addTeamMembers(t, []string{login}, cfg); err != nil {
+					return fmt.Errorf("failed to add team members to team %q: %w", t, err)
+				}
+			}
+
+			yes, err := terminal.AskForConfirmation("Store changes into the configuration file?")
+			if err != nil {
+				return err
+			}
+			if !yes {
+				return nil
+			}
+
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+
+			if opts.pullRequestRepo == "" {
+				return nil
+			}
+			if err = preflight(cmd.Context(), ghClient, github.OperationOpenPullRequests); err != nil {
+				return err
+			}
+			path, err := configPathInRepository(configFilename)
+			if err != nil {
+				return err
+			}
+			content, err := persistence.FormatState(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to format config: %w", err)
+			}
+			body := fmt.Sprintf("This adds %s to the configuration.", login)
+			if len(teams) != 0 {
+				body = fmt.Sprintf("This adds %s to the configuration and to the teams %s, selected by the onboarding rules.", login, strings.Join(teams, ", "))
+			}
+			pr, err := team.NewManager(ghClient, nil, orgName).OpenFilePullRequest(cmd.Context(), opts.pullRequestRepo, path, content,
+				fmt.Sprintf("Onboard %s", login), body)
+			if err != nil {
+				return fmt.Errorf("failed to open pull request: %w", err)
+			}
+			if pr != nil {
+				fmt.Printf("Opened %s\n", pr.GetHTMLURL())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.role, "role", "", "Role of the new member, asked interactively if not set")
+	cmd.Flags().StringVar(&opts.area, "area", "", "Area of work of the new member, asked interactively if not set")
+	cmd.Flags().StringVar(&opts.manager, "manager", "", "Manager of the new member, asked interactively if not set")
+	cmd.Flags().StringVar(&opts.pullRequestRepo, "pull-request-repo", "", "Repository of the organization to open a pull request updating the configuration file in")
+
+	return requireOperations(cmd, github.OperationReadUsers, github.OperationOpenPullRequests)
+}
+
+// configPathInRepository returns the path of the given configuration file
+// relative to the top-level directory of its git repository.
+func configPathInRepository(file string) (string, error) {
+	top, err := gitRevParse(filepath.Dir(file), "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("config %s is not in a git repository: %w", file, err)
+	}
+	path, err := relativeTo(top, file)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(path), nil
+}
+
+// askOnboardingQuestions asks for the role, area and manager of the new
+// member, unless they were already given as flags in opts.
+func askOnboardingQuestions(cfg *config.Config, opts *onboardOptions) (role, area, manager string, err error) {
+	role = opts.role
+	if role == "" {
+		if role, err = terminal.AskForInput("Role (e.g. maintainer, reviewer)", ""); err != nil {
+			return "", "", "", err
+		}
+	}
+	area = opts.area
+	if area == "" {
+		if area, err = terminal.AskForInput("Area of work (e.g. datapath, docs)", ""); err != nil {
+			return "", "", "", err
+		}
+	}
+	manager = opts.manager
+	if manager == "" {
+		if manager, err = terminal.AskForInput("Manager (GitHub login or name, empty if none)", ""); err != nil {
+			return "", "", "", err
+		}
+	}
+	if manager != "" {
+		if manager, err = findUser(cfg, manager); err != nil {
+			return "", "", "", fmt.Errorf("unable to find manager: %w", err)
+		}
+	}
+	return role, area, manager, nil
+}
+
+// onboardingTeams returns the sorted list of teams of all onboarding rules
+// that match the given answers.
+func onboardingTeams(cfg *config.Config, role, area, manager string) []string {
+	teams := set.New[string]()
+	for _, rule := range cfg.OnboardingRules {
+		if rule.Matches(role, area, manager) {
+			teams.Add(rule.Teams...)
+		}
+	}
+	return teams.Elements()
+}
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+)
+
+var (
+	explainPermissions bool
+)
+
+// commandOperations are the operations the commands may perform against
+// GitHub, registered by requireOperations.
+var commandOperations = map[*cobra.Command][]github.Operation{}
+
+// requireOperations registers the operations cmd may perform against GitHub,
+// whatever its flags, and returns cmd. They are listed by the permissions
+// command and by --explain-permissions.
+func requireOperations(cmd *cobra.Command, ops ...github.Operation) *cobra.Command {
+	commandOperations[cmd] = append(commandOperations[cmd], ops...)
+	return cmd
+}
+
+// commandPermissions are the permissions a command needs.
+type commandPermissions struct {
+	Command     string            `json:"command"`
+	Operations  []operationAccess `json:"operations"`
+	Scopes      []string          `json:"scopes"`
+	Permissions []string          `json:"permissions"`
+	OrgAdmin    bool              `json:"orgAdmin"`
+}
+
+// operationAccess is the access a token needs to perform an operation.
+type operationAccess struct {
+	Name        string   `json:"name"`
+	Scopes      []string `json:"scopes"`
+	Permissions []string `json:"permissions"`
+	OrgAdmin    bool     `json:"orgAdmin"`
+}
+
+// permissionsOf returns the permissions cmd needs according to the operations
+// it registered.
+func permissionsOf(cmd *cobra.Command) commandPermissions {
+	ops := commandOperations[cmd]
+	perms := commandPermissions{
+		Command:     cmd.CommandPath(),
+		Operations:  []operationAccess{},
+		Scopes:      nonNil(github.RequiredScopes(ops...)),
+		Permissions: github.RequiredPermissions(ops...),
+	}
+	for _, op := range ops {
+		perms.Operations = append(perms.Operations, operationAccess{
+			Name:        op.Name,
+			Scopes:      nonNil(op.Scopes),
+			Permissions: nonNil(op.Permissions),
+			OrgAdmin:    op.OrgAdmin,
+		})
+		perms.OrgAdmin = perms.OrgAdmin || op.OrgAdmin
+	}
+	return perms
+}
+
+// nonNil returns s, or an empty slice if s is nil, for JSON output.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// printPermissions prints the given permissions of a command.
+func printPermissions(w io.Writer, perms commandPermissions) {
+	fmt.Fprintln(w, perms.Command)
+	if len(perms.Operations) == 0 {
+		fmt.Fprintln(w, "  No access to GitHub")
+		return
+	}
+	for _, op := range perms.Operations {
+		fmt.Fprintf(w, "  - %s\n", op.Name)
+		fmt.Fprintf(w, "      scopes: %s\n", orDash(strings.Join(op.Scopes, " or ")))
+		fmt.Fprintf(w, "      permissions: %s\n", orDash(strings.Join(op.Permissions, ", ")))
+		if op.OrgAdmin {
+			fmt.Fprintln(w, "      organization owner")
+		}
+	}
+	fmt.Fprintf(w, "  Classic token scopes: %s\n", orDash(strings.Join(perms.Scopes, ", ")))
+	fmt.Fprintf(w, "  Fine-grained token permissions: %s\n", orDash(strings.Join(perms.Permissions, ", ")))
+	if perms.OrgAdmin {
+		fmt.Fprintln(w, "  The token owner must be an owner of the organization")
+	}
+}
+
+// NewPermissionsCommand returns the permissions command.
+func NewPermissionsCommand(_ Deps) *cobra.Command {
+	var opts struct {
+		format string
+	}
+	cmd := &cobra.Command{
+		Use:   "permissions [COMMAND ...]",
+		Short: "List the token scopes and permissions every command needs",
+		Long: `Lists the operations every command may perform against GitHub, with the
+OAuth scopes classic tokens need, at least one of the scopes of every
+operation, and the permissions fine-grained tokens and GitHub Apps need, named
+as in GitHub App manifests. Given a command, e.g. 'permissions codeowners',
+only that command and its subcommands are listed.
+
+Commands perform some of these operations only depending on their flags and on
+the configuration. Commands accept --explain-permissions to print their own
+permissions instead of running.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			if len(args) != 0 {
+				found, rest, err := root.Find(args)
+				if err != nil || len(rest) != 0 || found == root {
+					return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+				}
+				root = found
+			}
+
+			var all []commandPermissions
+			var walk func(c *cobra.Command)
+			walk = func(c *cobra.Command) {
+				if c.Runnable() && c.IsAvailableCommand() {
+					all = append(all, permissionsOf(c))
+				}
+				for _, sub := range c.Commands() {
+					walk(sub)
+				}
+			}
+			walk(root)
+
+			switch opts.format {
+			case "text":
+				for i, perms := range all {
+					if i != 0 {
+						fmt.Println()
+					}
+					printPermissions(os.Stdout, perms)
+				}
+				return nil
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(all); err != nil {
+					return fmt.Errorf("failed to write permissions: %w", err)
+				}
+				return nil
+			default:
+				return fmt.Errorf("unknown permissions format %q", opts.format)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "text", "Output format, one of: text, json")
+
+	return cmd
+}
+
+// WithPermissionExplanation wraps the Args and RunE functions of cmd and of
+// all its subcommands so that, with --explain-permissions, they print the
+// permissions they need instead of running, see requireOperations.
+func WithPermissionExplanation(cmd *cobra.Command) *cobra.Command {
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(cmd *cobra.Command, a []string) error {
+			if explainPermissions {
+				return nil
+			}
+			return args(cmd, a)
+		}
+	}
+	if runE := cmd.RunE; runE != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			if explainPermissions {
+				printPermissions(os.Stdout, permissionsOf(cmd))
+				return nil
+			}
+			return runE(cmd, args)
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		WithPermissionExplanation(sub)
+	}
+	return cmd
+}
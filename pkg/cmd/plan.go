@@ -0,0 +1,274 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// NewPlanCommand returns the plan command.
+func NewPlanCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		filename string
+	}
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Store the changes 'push' would submit into a signed plan file, to be applied later with 'apply'",
+		Long: `Computes the changes 'push' would submit to GitHub and stores them into a plan
+file, to be reviewed and applied later with 'apply'. The plan file is signed
+with the secret set in the TEAM_MANAGER_PLAN_SECRET environment variable and
+contains a hash of the upstream teams the plan was computed against.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			secret, err := planSecret()
+			if err != nil {
+				return err
+			}
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+			tm := team.NewManager(nil, ghGraphQLClient, orgName)
+			upstreamCfg, err := tm.GetCurrentConfig(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to read config from GitHub: %w", err)
+			}
+
+			now := time.Now()
+			effectiveCfg := team.EffectiveConfig(cfg, upstreamCfg, now)
+			plan := team.ComputePlan(effectiveCfg, upstreamCfg)
+			if err = tm.PlanInvitations(cmd.Context(), cfg, plan); err != nil {
+				fmt.Fprintf(os.Stderr, "[WARN]: Unable to check organization membership of added users: %s\n", github.TranslateError(err))
+			}
+			plan.PrintDiffs(os.Stdout)
+			printPlan(plan, effectiveCfg)
+
+			planFile, err := team.NewPlanFile(plan, upstreamCfg, now)
+			if err != nil {
+				return fmt.Errorf("failed to create plan: %w", err)
+			}
+			if err = planFile.Sign(secret); err != nil {
+				return fmt.Errorf("failed to sign plan: %w", err)
+			}
+			if err = team.StorePlanFile(opts.filename, planFile); err != nil {
+				return fmt.Errorf("failed to store plan: %w", err)
+			}
+			fmt.Printf("Plan stored in %s\n", opts.filename)
+
+			// Store when the pending removals were first seen.
+			if cfg.Policy.GraceDays != 0 {
+				if err = deps.StoreState(configFilename, cfg); err != nil {
+					return fmt.Errorf("failed to store state to config: %w", err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.filename, "out", "team-plan.json", "Plan filename")
+
+	return requireOperations(cmd, github.OperationReadTeams)
+}
+
+// NewApplyCommand returns the apply command.
+func NewApplyCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		revalidate     bool
+		dryRun         bool
+		force          bool
+		overrideFreeze bool
+		reportFormat   string
+		verifyTimeout  time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:   "apply PLAN",
+		Short: "Submit the changes of a plan file created with 'plan' to GitHub",
+		Long: `Submits the changes of a plan file created with 'plan' to GitHub. The plan is
+only applied if its signature matches the secret set in the
+TEAM_MANAGER_PLAN_SECRET environment variable and if the upstream teams did
+not change since the plan was created.
+
+With --revalidate, plans are also applied if the upstream teams changed since
+the plan was created. The current upstream state is instead re-checked before
+every change: member changes that were already made upstream are skipped, and
+code review assignments that were changed upstream in the meantime are
+reported as conflicts and left untouched.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			secret, err := planSecret()
+			if err != nil {
+				return err
+			}
+
+			planFile, err := team.LoadPlanFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load plan: %w", err)
+			}
+			if err = planFile.Verify(secret); err != nil {
+				return fmt.Errorf("failed to verify plan: %w", err)
+			}
+			if planFile.Organization != orgName {
+				return fmt.Errorf("plan was created for organization %s, not %s", planFile.Organization, orgName)
+			}
+
+			// The local state is only used to check the freeze windows, to
+			// print the logins of the members excluded from review assignments
+			// and to record the created teams and the exclusions that were
+			// applied.
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			if !opts.dryRun {
+				if err = checkFreeze(cmd.Context(), cfg, opts.overrideFreeze); err != nil {
+					return err
+				}
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+			reporter, err := newReporter(opts.reportFormat, opts.force)
+			if err != nil {
+				return err
+			}
+			tm := team.NewManager(ghClient, ghGraphQLClient, orgName)
+			tm.SetReporter(reporter)
+			tm.SetVerifyTimeout(opts.verifyTimeout)
+
+			upstreamCfg, err := tm.GetCurrentConfig(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to read config from GitHub: %w", err)
+			}
+			if err = planFile.VerifyUpstream(upstreamCfg); err != nil {
+				if !opts.revalidate {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "[WARN]: %s, revalidating every change\n", err)
+			}
+
+			if err = preflight(cmd.Context(), ghClient, github.OperationManageTeams); err != nil {
+				return err
+			}
+
+			plan := planFile.Plan()
+			fmt.Printf("Applying plan created at %s\n", planFile.CreatedAt.Format(time.RFC3339))
+			printPlan(plan, cfg)
+			if !opts.force {
+				yes, err := terminal.AskForConfirmation("Continue?")
+				if err != nil {
+					return err
+				}
+				if !yes {
+					return nil
+				}
+			}
+
+			ids, applied, appliedOptions := teamIDs(cfg), appliedExclusions(cfg), appliedReviewOptions(cfg)
+			applyErr := tm.ApplyPlan(cmd.Context(), cfg, plan, opts.revalidate, opts.dryRun)
+			// Store the IDs of the created teams and the members excluded from
+			// and options of the updated code review assignments, including the
+			// ones applied before a failure.
+			if (!reflect.DeepEqual(ids, teamIDs(cfg)) || !reflect.DeepEqual(applied, appliedExclusions(cfg)) ||
+				!reflect.DeepEqual(appliedOptions, appliedReviewOptions(cfg))) && !opts.dryRun {
+				if err = deps.StoreState(configFilename, cfg); err != nil {
+					return fmt.Errorf("failed to store state to config: %w", err)
+				}
+			}
+			if applyErr != nil {
+				return fmt.Errorf("failed to apply plan: %w", applyErr)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Dry run the steps without performing any write operation to GitHub")
+	cmd.Flags().BoolVar(&opts.force, "opts.force", false, "Apply the plan without asking for confirmation")
+	cmd.Flags().BoolVar(&opts.revalidate, "opts.revalidate", false, "Re-check the upstream state before every change instead of refusing plans created against different upstream teams")
+	cmd.Flags().DurationVar(&opts.verifyTimeout, "verify-timeout", 0, "Wait up to this long for membership changes to be reflected by GitHub, 0 to not verify them")
+	cmd.Flags().BoolVar(&opts.overrideFreeze, "override-freeze", false, "Apply changes even during a freeze window")
+	cmd.Flags().StringVar(&opts.reportFormat, "report-format", "text", "Format of the report of the changes, one of: text, json (requires --force), github, silent")
+
+	return requireOperations(cmd, github.OperationReadTeams, github.OperationManageTeams)
+}
+
+// printPlan prints the pending removals of the given configuration and the
+// team membership changes and code review assignments of the given plan,
+// followed by the hash of the plan to compare it across runs. Unresolved
+// exclusions are reported on stderr.
+func printPlan(plan *team.Plan, cfg *config.Config) {
+	reportUnresolvedExclusions(plan)
+	if len(cfg.PendingRemovals) != 0 {
+		fmt.Println("Pending removals:")
+		team.PrintPendingRemovals(os.Stdout, cfg)
+	}
+	if len(plan.NewTeams) != 0 {
+		fmt.Println("New teams:")
+		plan.PrintNewTeams(os.Stdout)
+	}
+	if len(plan.TeamChanges) == 0 {
+		fmt.Println("No team membership changes")
+	} else {
+		fmt.Println("Team membership changes:")
+		plan.PrintTeamChanges(os.Stdout)
+	}
+	if len(plan.TeamEdits) != 0 {
+		fmt.Println("Team settings changes:")
+		plan.PrintTeamEdits(os.Stdout)
+	}
+	if len(plan.ReviewAssignments) == 0 {
+		fmt.Println("No code review assignment changes")
+	} else {
+		fmt.Println("Code review assignments:")
+		plan.PrintReviewAssignments(os.Stdout, cfg)
+	}
+	ops := plan.Operations()
+	fmt.Printf("Plan hash: %s (%d operations)\n", team.HashOperations(ops), len(ops))
+}
+
+// planSecret returns the secret used to sign and verify plan files.
+func planSecret() ([]byte, error) {
+	secret := os.Getenv("TEAM_MANAGER_PLAN_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("environment variable TEAM_MANAGER_PLAN_SECRET must be set to sign and verify plans")
+	}
+	return []byte(secret), nil
+}
+
+// reportUnresolvedExclusions reports the excluded members of the plan that
+// aren't members of the organization on stderr.
+func reportUnresolvedExclusions(plan *team.Plan) {
+	teamNames := make([]string, 0, len(plan.UnresolvedExclusions))
+	for teamName := range plan.UnresolvedExclusions {
+		teamNames = append(teamNames, teamName)
+	}
+	sort.Strings(teamNames)
+	for _, teamName := range teamNames {
+		for _, login := range plan.UnresolvedExclusions[teamName] {
+			fmt.Fprintf(os.Stderr, "[ERROR]: User %q excluded from team %s not found in the list of team members in the organization\n", login, teamName)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/github"
+)
+
+// preflight checks that the GitHub token can perform the given operations
+// before a command changes anything, so that it doesn't fail half-way.
+func preflight(ctx context.Context, ghClient *gh.Client, ops ...github.Operation) error {
+	if skipPreflight {
+		return nil
+	}
+	missing, err := github.Preflight(ctx, ghClient, orgName, ops...)
+	if err != nil {
+		return fmt.Errorf("failed to perform permission preflight: %w", err)
+	}
+	for _, m := range missing {
+		fmt.Fprintf(os.Stderr, "[ERROR]: %s\n", m)
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("missing %d permissions, use --skip-preflight to run anyway", len(missing))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/persistence"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewPreviewCommand returns the preview command.
+func NewPreviewCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		snapshotFilename string
+	}
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Print the changes 'push' would submit, computed against a snapshot of the upstream configuration",
+		Long: `Computes the changes 'push' would submit to GitHub against a snapshot taken
+with 'snapshot' instead of the live upstream configuration. It does not
+require any network access or GitHub token, e.g. to preview configuration
+changes in pull requests from forks.
+
+With --replay-cassette, the upstream configuration is instead read from the
+interactions recorded with --record-cassette.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			var upstreamCfg *config.Config
+			if replayCassette != "" {
+				ghGraphQLClient, err := deps.NewGraphQLClient()
+				if err != nil {
+					return fmt.Errorf("failed to create github graphql client: %w", err)
+				}
+				upstreamCfg, err = team.NewManager(nil, ghGraphQLClient, orgName).GetCurrentConfig(cmd.Context())
+				if err != nil {
+					return fmt.Errorf("failed to replay config from cassette: %w", err)
+				}
+				fmt.Printf("Comparing against cassette %s\n", replayCassette)
+			} else {
+				snapshot, err := persistence.LoadSnapshot(opts.snapshotFilename)
+				if err != nil {
+					return fmt.Errorf("failed to load snapshot: %w", err)
+				}
+				upstreamCfg = snapshot.Config
+				fmt.Printf("Comparing against snapshot of %s taken at %s\n", snapshot.Config.Organization, snapshot.CreatedAt)
+			}
+
+			now := time.Now()
+			cfg = team.EffectiveConfig(cfg, upstreamCfg, now)
+			plan := team.ComputePlan(cfg, upstreamCfg)
+			plan.PrintDiffs(os.Stdout)
+			printPlan(plan, cfg)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.snapshotFilename, "snapshot-filename", "upstream-snapshot.yaml", "Snapshot filename")
+
+	return cmd
+}
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/persistence"
+	"github.com/cilium/team-manager/pkg/team"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// NewPurgeUserDataCommand returns the purge-user-data command.
+func NewPurgeUserDataCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		snapshotFilename string
+		historyDir       string
+		knownDrifts      string
+		dryRun           bool
+		force            bool
+	}
+	cmd := &cobra.Command{
+		Use:   "purge-user-data LOGIN",
+		Short: "Remove the personal data of a former member from the configuration, snapshots and known drifts",
+		Long: `Removes the member with the given login, with its name, email address and
+metadata, from the configuration, from the snapshot and the snapshots of the
+history directory, and from the known drifts recorded by 'check'. Its past team
+memberships are kept under a random pseudonym, the same in all files, so that
+the trends computed from the snapshots don't change.
+
+The member must not be part of any team of the configuration anymore. Cassettes
+recorded with --record-cassette are not rewritten, they must be deleted.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			login := args[0]
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			var teams []string
+			for teamName, teamCfg := range cfg.Teams {
+				for _, member := range teamCfg.Members {
+					if strings.EqualFold(member, login) {
+						teams = append(teams, teamName)
+					}
+				}
+			}
+			if len(teams) != 0 {
+				sort.Strings(teams)
+				return fmt.Errorf("%s is still a member of teams %s, remove them from these teams and push first", login, strings.Join(teams, ", "))
+			}
+			for _, owner := range cfg.Owners {
+				if strings.EqualFold(owner, login) {
+					return fmt.Errorf("%s is an owner of the organization, remove them from the owners and push first", login)
+				}
+			}
+			for _, r := range cfg.PendingRemovals {
+				if strings.EqualFold(r.Login, login) {
+					return fmt.Errorf("%s is pending removal from team %s, wait for the removal to be pushed first", login, r.Team)
+				}
+			}
+
+			snapshotFiles, err := purgeSnapshotFiles(opts.snapshotFilename, opts.historyDir)
+			if err != nil {
+				return err
+			}
+
+			pseudonym, err := team.NewPseudonym()
+			if err != nil {
+				return fmt.Errorf("failed to generate pseudonym: %w", err)
+			}
+			fmt.Printf("Going to purge the data of %s, replacing its login with %s in:\n", login, pseudonym)
+			fmt.Printf(" %s\n", configFilename)
+			for _, file := range snapshotFiles {
+				fmt.Printf(" %s\n", file)
+			}
+			if opts.knownDrifts != "" {
+				fmt.Printf(" %s\n", opts.knownDrifts)
+			}
+			if opts.dryRun {
+				return nil
+			}
+			if !opts.force {
+				yes, err := terminal.AskForConfirmation("This can't be undone, continue?")
+				if err != nil {
+					return err
+				}
+				if !yes {
+					return nil
+				}
+			}
+
+			if team.PurgeMember(cfg, login, pseudonym) {
+				if err = deps.StoreState(configFilename, cfg); err != nil {
+					return fmt.Errorf("failed to store state to config: %w", err)
+				}
+			}
+			for _, file := range snapshotFiles {
+				snapshot, err := persistence.LoadSnapshot(file)
+				if err != nil {
+					return fmt.Errorf("failed to load snapshot %q: %w", file, err)
+				}
+				if !team.PurgeMember(snapshot.Config, login, pseudonym) {
+					continue
+				}
+				if err = persistence.StoreSnapshot(file, snapshot); err != nil {
+					return fmt.Errorf("failed to store snapshot %q: %w", file, err)
+				}
+			}
+			if opts.knownDrifts != "" {
+				if err = purgeFile(opts.knownDrifts, login, pseudonym); err != nil {
+					return fmt.Errorf("failed to purge known drifts: %w", err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.snapshotFilename, "snapshot-filename", "upstream-snapshot.yaml", "Snapshot to purge, ignored if missing")
+	cmd.Flags().StringVar(&opts.historyDir, "history-dir", "", "Directory of the snapshots kept with 'snapshot --history-dir' to purge")
+	cmd.Flags().StringVar(&opts.knownDrifts, "known-drifts", "", "File recording the known drifts of 'check' to purge")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Only print the files that would be purged")
+	cmd.Flags().BoolVar(&opts.force, "opts.force", false, "Purge the data without asking for confirmation")
+
+	return cmd
+}
+
+// purgeSnapshotFiles returns the existing snapshot files to purge, the
+// snapshot file and the snapshots of the history directory.
+func purgeSnapshotFiles(snapshotFile, historyDir string) ([]string, error) {
+	var files []string
+	if _, err := os.Stat(snapshotFile); err == nil {
+		files = append(files, snapshotFile)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if historyDir != "" {
+		history, err := filepath.Glob(filepath.Join(historyDir, "*.yaml"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, history...)
+	}
+	return files, nil
+}
+
+// purgeFile replaces the given login with pseudonym in the given text file,
+// if it exists.
+func purgeFile(file, login, pseudonym string) error {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	re := regexp.MustCompile(`(?i)(^|[^\w-])` + regexp.QuoteMeta(login) + `($|[^\w-])`)
+	purged := re.ReplaceAll(data, []byte("${1}"+pseudonym+"${2}"))
+	if string(purged) == string(data) {
+		return nil
+	}
+	return os.WriteFile(file, purged, 0644)
+}
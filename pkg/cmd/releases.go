@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/export"
+)
+
+// NewReleasesCommand returns the releases command.
+func NewReleasesCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		format string
+		output string
+	}
+	cmd := &cobra.Command{
+		Use:   "releases",
+		Short: "Export the release cycles with the members of their responsible team and their shepherd",
+		Long: `Exports the release cycles of the configuration, sorted by start date, with
+the current members and maintainers of their responsible team and their release
+shepherd, for release tooling.
+
+Release cycles without a shepherd in the configuration are assigned one by
+rotation: the members of the team, sorted by login, take turns in the order of
+the start of the release cycles of the team, skipping the members away at the
+start of the cycle, i.e. excluded from the code review assignment of the team
+until a later day.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			var w io.Writer = os.Stdout
+			if opts.output != "-" {
+				f, err := os.Create(opts.output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			releases := export.Releases(cfg, time.Now())
+			switch opts.format {
+			case "json":
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				return enc.Encode(releases)
+			case "text":
+				tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(tw, "RELEASE\tSTART\tEND\tTEAM\tSHEPHERD\tMEMBERS\t")
+				for _, r := range releases {
+					shepherd := orDash(r.Shepherd)
+					if r.Rotated && r.Shepherd != "" {
+						shepherd += " (rotation)"
+					}
+					name := r.Name
+					if r.Active {
+						name += " *"
+					}
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t\n", name, r.Start, orDash(r.End), r.Team, shepherd, strings.Join(r.Members, ","))
+				}
+				return tw.Flush()
+			}
+			return fmt.Errorf("unknown format %q", opts.format)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "json", "Output format, one of: json, text; active release cycles are marked with '*' in the text format")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "-", "File to write the export to, '-' for stdout")
+
+	return cmd
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// NewRenameTeamCommand returns the rename-team command.
+func NewRenameTeamCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		fixReferences  bool
+		repos          []string
+		dryRun         bool
+		force          bool
+		overrideFreeze bool
+	}
+	cmd := &cobra.Command{
+		Use:   "rename-team OLD NEW",
+		Short: "Rename a team in GitHub and in the configuration",
+		Long: `Renames a team in GitHub and in the configuration, including the parents of
+its child teams, the onboarding rules and the repository templates.
+
+Renaming a team changes its slug, which silently breaks the CODEOWNERS files
+referencing it. The repositories are checked for such references, and with
+--fix-references a pull request updating each CODEOWNERS file is opened.
+Branch protection rules reference teams by ID and are not affected.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldName, newName := args[0], args[1]
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			if _, ok := cfg.Teams[oldName]; !ok {
+				return fmt.Errorf("unknown team %q", oldName)
+			}
+			if _, ok := cfg.Teams[newName]; ok {
+				return fmt.Errorf("team %q already exists", newName)
+			}
+			if !opts.dryRun {
+				if err = checkFreeze(cmd.Context(), cfg, opts.overrideFreeze); err != nil {
+					return err
+				}
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			tm := team.NewManager(ghClient, nil, orgName)
+			tm.SetTeamSlugs(cfg)
+
+			ops := []github.Operation{github.OperationManageTeams}
+			if opts.fixReferences {
+				ops = append(ops, github.OperationOpenPullRequests)
+			}
+			if err = preflight(cmd.Context(), ghClient, ops...); err != nil {
+				return err
+			}
+
+			repos := opts.repos
+			if len(repos) == 0 {
+				if repos, err = tm.ListRepositories(cmd.Context()); err != nil {
+					return fmt.Errorf("failed to list repositories: %w", err)
+				}
+			}
+			oldSlug := team.TeamSlug(cfg, oldName)
+			fmt.Printf("Checking %d repositories for references to team %s...\n", len(repos), oldName)
+			refs, err := tm.FindTeamReferences(cmd.Context(), oldSlug, repos)
+			if err != nil {
+				return fmt.Errorf("failed to find references to team: %w", err)
+			}
+			type codeOwnersFile struct{ repo, path string }
+			var files []codeOwnersFile
+			for _, ref := range refs {
+				fmt.Println(ref)
+				if ref.Path == "" {
+					continue
+				}
+				file := codeOwnersFile{ref.Repository, ref.Path}
+				if len(files) == 0 || files[len(files)-1] != file {
+					files = append(files, file)
+				}
+			}
+
+			fmt.Printf("Going to submit the following changes:\n")
+			fmt.Printf(" Team: %s\n", oldName)
+			fmt.Printf("    Renaming team to %s\n", newName)
+			if opts.fixReferences {
+				for _, file := range files {
+					fmt.Printf("    Opening pull request updating %s of %s\n", file.path, file.repo)
+				}
+			} else if len(files) != 0 {
+				fmt.Printf("    Leaving %d CODEOWNERS files referencing the old team, use --fix-references to update them\n", len(files))
+			}
+			yes := opts.force
+			if !opts.force {
+				yes, err = terminal.AskForConfirmation("Continue?")
+				if err != nil {
+					return err
+				}
+			}
+			if !yes || opts.dryRun {
+				return nil
+			}
+
+			newSlug, err := tm.RenameTeam(cmd.Context(), oldName, newName)
+			if err != nil {
+				return fmt.Errorf("failed to rename team in GitHub: %w", err)
+			}
+			team.RenameTeamInConfig(cfg, oldName, newName)
+			teamCfg := cfg.Teams[newName]
+			teamCfg.Slug = newSlug
+			cfg.Teams[newName] = teamCfg
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+
+			if !opts.fixReferences {
+				return nil
+			}
+			oldOwner, newOwner := "@"+orgName+"/"+oldSlug, "@"+orgName+"/"+newSlug
+			for _, file := range files {
+				content, err := tm.GetFileContent(cmd.Context(), file.repo, file.path)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "[ERROR]: Unable to read %s of %s: %s\n", file.path, file.repo, err)
+					continue
+				}
+				pr, err := tm.OpenFilePullRequest(cmd.Context(), file.repo, file.path,
+					[]byte(team.RewriteCodeOwners(content, oldOwner, newOwner)),
+					fmt.Sprintf("Rename %s to %s in %s", oldOwner, newOwner, file.path),
+					fmt.Sprintf("The team %s was renamed to %s, this updates the code owners accordingly.", oldOwner, newOwner))
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "[ERROR]: Unable to open pull request updating %s of %s: %s\n", file.path, file.repo, err)
+					continue
+				}
+				fmt.Printf("Opened %s\n", pr.GetHTMLURL())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.fixReferences, "fix-references", false, "Open pull requests updating the CODEOWNERS files referencing the team")
+	cmd.Flags().StringSliceVar(&opts.repos, "repos", nil, "Repositories checked for references to the team (default all repositories)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the changes without changing the configuration or GitHub")
+	cmd.Flags().BoolVar(&opts.force, "opts.force", false, "Do not ask for confirmation")
+	cmd.Flags().BoolVar(&opts.overrideFreeze, "override-freeze", false, "Apply changes even during a freeze window")
+
+	return requireOperations(cmd, github.OperationReadRepositories, github.OperationManageTeams, github.OperationOpenPullRequests)
+}
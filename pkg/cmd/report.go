@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewReportCommand returns the report command.
+func NewReportCommand(deps Deps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report on how the teams of the configuration are used",
+	}
+
+	cmd.AddCommand(
+		newReportReviewLoadCommand(deps),
+	)
+
+	return cmd
+}
+
+// newReportReviewLoadCommand returns the report review-load command.
+func newReportReviewLoadCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		format string
+		since  string
+		until  string
+	}
+	cmd := &cobra.Command{
+		Use:   "review-load [TEAM ...]",
+		Short: "Print the distribution of the review requests among the members of teams",
+		Long: `Counts the pull requests of the organization created over a period of time
+that each member of the given teams, or of all teams of the configuration if
+none are given, was requested to review, and prints their distribution within
+every team, to tune the code review assignments and their exclusions.
+
+As the search API only returns the review requests that are still pending,
+the requests fulfilled by a review are counted by the reviews of the members.
+Review requests are counted across the organization, whichever team they were
+requested from, and the mean load of a team only accounts for the members that
+aren't excluded from its code review assignment.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			since, err := time.Parse(config.DateFormat, opts.since)
+			if err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			until, err := time.Parse(config.DateFormat, opts.until)
+			if err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			teamNames := args
+			for _, teamName := range teamNames {
+				if _, ok := cfg.Teams[teamName]; !ok {
+					return fmt.Errorf("team %q not found in the configuration", teamName)
+				}
+			}
+			if len(teamNames) == 0 {
+				for teamName := range cfg.Teams {
+					teamNames = append(teamNames, teamName)
+				}
+				sort.Strings(teamNames)
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			tm := team.NewManager(ghClient, nil, orgName)
+
+			loads, err := tm.GetReviewLoad(cmd.Context(), cfg, teamNames, since, until)
+			if err != nil {
+				return fmt.Errorf("failed to get review load: %w", err)
+			}
+
+			switch opts.format {
+			case "text":
+				return printReviewLoads(loads, opts.since, opts.until)
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(loads)
+			default:
+				return fmt.Errorf("unknown report format %q", opts.format)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "text", "Output format, one of: text, json")
+	cmd.Flags().StringVar(&opts.since, "since", time.Now().AddDate(0, -3, 0).Format(config.DateFormat), "Only count pull requests created on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.until, "until", time.Now().Format(config.DateFormat), "Only count pull requests created on or before this date (YYYY-MM-DD)")
+
+	return requireOperations(cmd, github.OperationReadRepositories)
+}
+
+// printReviewLoads prints the review load of every member of the given teams
+// with their share of the load of their team, counted between since and until.
+func printReviewLoads(loads []team.TeamReviewLoad, since, until string) error {
+	for i, load := range loads {
+		if i != 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Team %s: %d review requests between %s and %s, %.1f per eligible member\n",
+			load.Team, load.Total(), since, until, load.Mean())
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MEMBER\tPENDING\tREVIEWED\tTOTAL\tSHARE\t")
+		for _, m := range load.Members {
+			share := 0.0
+			if total := load.Total(); total != 0 {
+				share = float64(m.Total()) * 100 / float64(total)
+			}
+			flag := ""
+			if m.Excluded {
+				flag = "EXCLUDED"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.0f%%\t%s\n", m.Login, m.Pending, m.Reviewed, m.Total(), share, flag)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/renameio"
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewApplyRepoTemplatesCommand returns the apply-repo-templates command.
+func NewApplyRepoTemplatesCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		lastRunFilename string
+		since           time.Duration
+		dryRun          bool
+		force           bool
+		overrideFreeze  bool
+	}
+	cmd := &cobra.Command{
+		Use:   "apply-repo-templates [REPO ...]",
+		Short: "Grant the team permissions of the repository templates to the given or newly created repositories",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := deps.LoadState(configFilename)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			if err = config.SanityCheck(cfg); err != nil {
+				return fmt.Errorf("failed to perform sanity check: %w", err)
+			}
+
+			if !opts.dryRun {
+				if err = checkFreeze(cmd.Context(), cfg, opts.overrideFreeze); err != nil {
+					return err
+				}
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			tm := team.NewManager(ghClient, nil, orgName)
+
+			if err = preflight(cmd.Context(), ghClient, github.OperationManageRepositoryAccess); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			repos := args
+			if len(repos) == 0 {
+				since, err := loadLastRun(opts.lastRunFilename)
+				if err != nil {
+					return fmt.Errorf("failed to load time of last run: %w", err)
+				}
+				if since.IsZero() {
+					since = now.Add(-opts.since)
+				}
+
+				fmt.Printf("Retrieving repositories created since %s...\n", since.Format(time.RFC3339))
+				newRepos, err := tm.ListRepositoriesCreatedSince(cmd.Context(), since)
+				if err != nil {
+					return fmt.Errorf("failed to list repositories: %w", err)
+				}
+				for _, r := range newRepos {
+					repos = append(repos, r.GetName())
+				}
+			}
+
+			// The time of the last run is only advanced once all grants
+			// succeeded, so that the others are retried by the next run.
+			err = tm.ApplyRepositoryTemplates(cmd.Context(), cfg, repos, opts.force, opts.dryRun)
+			if errors.Is(err, team.ErrDeclined) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("failed to apply repository templates: %w", err)
+			}
+
+			if len(args) == 0 && !opts.dryRun {
+				if err = renameio.WriteFile(opts.lastRunFilename, []byte(now.UTC().Format(time.RFC3339)+"\n"), 0o666); err != nil {
+					return fmt.Errorf("failed to store time of last run: %w", err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Dry run the steps without performing any write operation to GitHub")
+	cmd.Flags().BoolVar(&opts.force, "opts.force", false, "Force local changes into GitHub without asking for configuration")
+	cmd.Flags().BoolVar(&opts.overrideFreeze, "override-freeze", false, "Apply changes even during a freeze window")
+	cmd.Flags().StringVar(&opts.lastRunFilename, "last-run-filename", ".repository-templates-last-run", "File storing the time of the last run, used to detect newly created repositories")
+	cmd.Flags().DurationVar(&opts.since, "since", 24*time.Hour, "Consider repositories created within this duration if there is no record of a previous run")
+
+	return requireOperations(cmd, github.OperationReadRepositories, github.OperationManageRepositoryAccess)
+}
+
+// loadLastRun returns the time stored in the given file, or the zero time if
+// the file does not exist.
+func loadLastRun(file string) (time.Time, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// NewRetireTeamCommand returns the retire-team command.
+func NewRetireTeamCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		archive          bool
+		delete           bool
+		repos            []string
+		ignoreReferences bool
+		dryRun           bool
+		force            bool
+		overrideFreeze   bool
+	}
+	cmd := &cobra.Command{
+		Use:   "retire-team TEAM",
+		Short: "Remove a team from the configuration and empty or delete it in GitHub",
+		Long: `Retires a team that is no longer needed. The repositories of the organization
+are first checked for CODEOWNERS files and branch protection rules that still
+reference the team, in which case the team is not retired.
+
+The team is removed from the configuration, including from onboarding rules
+and repository templates, and optionally archived into the 'retired' section.
+All members are removed from the team in GitHub, or the team is deleted with
+--delete.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			teamName := args[0]
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			if _, ok := cfg.Teams[teamName]; !ok {
+				return fmt.Errorf("unknown team %q", teamName)
+			}
+			for childName, child := range cfg.Teams {
+				if child.Parent == teamName {
+					return fmt.Errorf("team %q is the parent of team %q, retire or move its child teams first", teamName, childName)
+				}
+			}
+			if !opts.dryRun {
+				if err = checkFreeze(cmd.Context(), cfg, opts.overrideFreeze); err != nil {
+					return err
+				}
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			tm := team.NewManager(ghClient, nil, orgName)
+			tm.SetTeamSlugs(cfg)
+
+			if err = preflight(cmd.Context(), ghClient, github.OperationManageTeams); err != nil {
+				return err
+			}
+
+			repos := opts.repos
+			if len(repos) == 0 {
+				if repos, err = tm.ListRepositories(cmd.Context()); err != nil {
+					return fmt.Errorf("failed to list repositories: %w", err)
+				}
+			}
+			fmt.Printf("Checking %d repositories for references to team %s...\n", len(repos), teamName)
+			refs, err := tm.FindTeamReferences(cmd.Context(), team.TeamSlug(cfg, teamName), repos)
+			if err != nil {
+				return fmt.Errorf("failed to find references to team: %w", err)
+			}
+			for _, ref := range refs {
+				fmt.Println(ref)
+			}
+			if len(refs) != 0 && !opts.ignoreReferences {
+				return fmt.Errorf("team %q is still referenced %d times, remove the references or use --ignore-references", teamName, len(refs))
+			}
+
+			members, err := tm.ListTeamMembers(cmd.Context(), teamName)
+			if err != nil {
+				return fmt.Errorf("failed to list team members: %w", err)
+			}
+
+			fmt.Printf("Going to submit the following changes:\n")
+			fmt.Printf(" Team: %s\n", teamName)
+			if opts.archive {
+				fmt.Printf("    Archiving team into the retired teams of the configuration\n")
+			} else {
+				fmt.Printf("    Removing team from the configuration\n")
+			}
+			if opts.delete {
+				fmt.Printf("    Deleting team in GitHub\n")
+			} else {
+				fmt.Printf("    Removing members in GitHub: %v\n", members)
+			}
+			yes := opts.force
+			if !opts.force {
+				yes, err = terminal.AskForConfirmation("Continue?")
+				if err != nil {
+					return err
+				}
+			}
+			if !yes || opts.dryRun {
+				return nil
+			}
+
+			if opts.delete {
+				err = tm.DeleteTeam(cmd.Context(), teamName)
+			} else {
+				err = tm.SyncTeamMembers(cmd.Context(), teamName, team.TeamChange{Remove: members})
+			}
+			if err != nil {
+				return fmt.Errorf("failed to retire team in GitHub: %w", err)
+			}
+
+			team.RemoveTeamFromConfig(cfg, teamName, opts.archive, time.Now())
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.archive, "archive", false, "Keep the configuration of the team in the 'retired' section of the configuration")
+	cmd.Flags().BoolVar(&opts.delete, "delete", false, "Delete the team in GitHub instead of removing all its members")
+	cmd.Flags().StringSliceVar(&opts.repos, "repos", nil, "Repositories checked for references to the team (default all repositories)")
+	cmd.Flags().BoolVar(&opts.ignoreReferences, "ignore-references", false, "Retire the team even if CODEOWNERS files or branch protection rules reference it")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the changes without changing the configuration or GitHub")
+	cmd.Flags().BoolVar(&opts.force, "opts.force", false, "Do not ask for confirmation")
+	cmd.Flags().BoolVar(&opts.overrideFreeze, "override-freeze", false, "Apply changes even during a freeze window")
+
+	return requireOperations(cmd, github.OperationReadRepositories, github.OperationManageTeams)
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// NewAddPtoCommand returns the add-pto command.
+func NewAddPtoCommand(deps Deps) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add-pto USER [USER ...]",
+		Short: "Exclude user from code review assignments",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := deps.LoadState(configFilename)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			if err = addCRAExclusionToConfig(args, cfg); err != nil {
+				return fmt.Errorf("failed to add code review assignment exclusion: %w", err)
+			}
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+
+			return nil
+		},
+	}
+}
+
+// NewRemovePtoCommand returns the remove-pto command.
+func NewRemovePtoCommand(deps Deps) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove-pto USER [USER ...]",
+		Short: "Include user in code review assignments",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := deps.LoadState(configFilename)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			if err := removeCRAExclusionToConfig(args, cfg); err != nil {
+				return fmt.Errorf("failed to remove code review assignment exclusion: %w", err)
+			}
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+
+			return nil
+		},
+	}
+}
+
+func addCRAExclusionToConfig(addCRAExclusion []string, cfg *config.Config) error {
+	excludeCRAFromAllTeams := set.New(cfg.ExcludeCRAFromAllTeams...)
+	for _, s := range addCRAExclusion {
+		user, err := findUser(cfg, s)
+		if err != nil {
+			return err
+		}
+		excludeCRAFromAllTeams.Add(user)
+	}
+	cfg.ExcludeCRAFromAllTeams = excludeCRAFromAllTeams.Elements()
+
+	return nil
+}
+
+func removeCRAExclusionToConfig(addCRAExclusion []string, cfg *config.Config) error {
+	excludeCRAFromAllTeams := set.New(cfg.ExcludeCRAFromAllTeams...)
+	for _, s := range addCRAExclusion {
+		user, err := findUser(cfg, s)
+		if err != nil {
+			return err
+		}
+		excludeCRAFromAllTeams.Remove(user)
+	}
+	cfg.ExcludeCRAFromAllTeams = excludeCRAFromAllTeams.Elements()
+
+	return nil
+}
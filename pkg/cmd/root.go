@@ -0,0 +1,331 @@
+// Copyright 2021 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd contains the team-manager commands. Every command is returned
+// by a constructor taking the Deps it uses to load and store the
+// configuration and to access GitHub, so that other tools can embed these
+// commands into their own CLIs with their own wiring.
+//
+// The flags of every command are kept in options owned by its constructor, so
+// that a command can be instantiated several times. Only the global flags of
+// AddGlobalFlags are stored in package variables, as they configure the
+// configuration file and the GitHub clients of the whole process.
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v33/github"
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/persistence"
+)
+
+// Deps are the dependencies of the commands.
+type Deps struct {
+	// LoadState loads the configuration from the given file.
+	LoadState func(file string) (*config.Config, error)
+	// StoreState stores the configuration into the given file.
+	StoreState func(file string, cfg *config.Config) error
+	// LoadStateHistory loads the committed revisions of the given
+	// configuration file, oldest first, none if it isn't versioned.
+	LoadStateHistory func(file string) ([]persistence.Revision, error)
+	// NewClient returns a client of the GitHub REST API.
+	NewClient func() (*gh.Client, error)
+	// NewGraphQLClient returns a client of the GitHub GraphQL API.
+	NewGraphQLClient func() (*github.GraphQLClient, error)
+}
+
+// DefaultDeps returns the dependencies used by the team-manager binary: the
+// configuration is stored in YAML files and GitHub is accessed with the
+// token found by github.NewClientFromEnv.
+func DefaultDeps() Deps {
+	return Deps{
+		LoadState:        persistence.LoadState,
+		StoreState:       persistence.StoreState,
+		LoadStateHistory: persistence.LoadStateHistory,
+		NewClient:        github.NewClientFromEnv,
+		NewGraphQLClient: github.NewClientGraphQLFromEnv,
+	}
+}
+
+var (
+	orgName        string
+	configFilename string
+	recordCassette string
+	replayCassette string
+	skipPreflight  bool
+	caBundle       string
+	clientCert     string
+	clientKey      string
+	dialTimeout    time.Duration
+	fallbackDelay  time.Duration
+	dnsRetries     int
+	redactNames    bool
+	authProviders  []string
+	chaosRate      float64
+	chaosSeed      int64
+	offline        bool
+)
+
+// AddGlobalFlags adds the flags shared by all commands to the persistent
+// flags of the given root command. ApplyGlobalFlags must be called once the
+// flags are parsed, e.g. in a PersistentPreRunE.
+func AddGlobalFlags(root *cobra.Command) {
+	flag := root.PersistentFlags()
+	flag.StringVar(&orgName, "org", "cilium", "GitHub organization name")
+	flag.StringVar(&configFilename, "config-filename", "", "Config filename (default $"+configEnv+", or "+defaultConfigFilename+" in the current directory or its closest parent directory having one)")
+	flag.StringVar(&configFilename, "config", "", "Alias of --config-filename")
+	flag.StringVar(&recordCassette, "record-cassette", "", "Record all interactions with GitHub into this file")
+	flag.StringVar(&replayCassette, "replay-cassette", "", "Replay the interactions with GitHub from this file instead of accessing the network")
+	flag.StringVar(&caBundle, "ca-bundle", "", "PEM file of additional certificate authorities trusted to verify GitHub servers")
+	flag.StringVar(&clientCert, "client-cert", "", "PEM file of the TLS client certificate presented to GitHub servers")
+	flag.StringVar(&clientKey, "client-key", "", "PEM file of the key of --client-cert")
+	flag.DurationVar(&dialTimeout, "dial-timeout", 30*time.Second, "Timeout of establishing connections to GitHub")
+	flag.DurationVar(&fallbackDelay, "happy-eyeballs-delay", 300*time.Millisecond, "Delay after which IPv4 is attempted if IPv6 did not connect yet, negative to disable the fallback")
+	flag.IntVar(&dnsRetries, "dns-retries", 3, "Number of times connections are retried after DNS resolution failures")
+	flag.BoolVar(&skipPreflight, "skip-preflight", false, "Do not check the permissions of the GitHub token before changing anything")
+	flag.BoolVar(&explainPermissions, "explain-permissions", false, "Print the token scopes and permissions the command needs instead of running it")
+	flag.StringSliceVar(&authProviders, "auth-providers", github.DefaultAuthProviders(), "Sources of the GitHub token, tried in order, among: "+strings.Join(github.DefaultAuthProviders(), ", "))
+	flag.BoolVar(&redactNames, "redact-names", false, "Omit the names, email addresses and SSO identities of the members from reports, exports and snapshots")
+	flag.BoolVar(&offline, "offline", false, "Fail any access to the network, e.g. in CI stages that must only validate the configuration")
+
+	// The chaos mode is meant for staging organizations only, it is hidden
+	// to not be mistaken for a regular option.
+	flag.Float64Var(&chaosRate, "chaos", 0, "Inject simulated API failures, rate limits and partial results into this share of the requests, 0.1 if no share is given")
+	flag.Lookup("chaos").NoOptDefVal = "0.1"
+	flag.Int64Var(&chaosSeed, "chaos-seed", 0, "Seed of the injection of simulated failures, to reproduce a previous run (default random)")
+	_ = flag.MarkHidden("chaos")
+	_ = flag.MarkHidden("chaos-seed")
+}
+
+// ApplyGlobalFlags validates the flags added by AddGlobalFlags and applies
+// them.
+func ApplyGlobalFlags() error {
+	if recordCassette != "" && replayCassette != "" {
+		return fmt.Errorf("--record-cassette and --replay-cassette are mutually exclusive")
+	}
+	if offline && recordCassette != "" {
+		return fmt.Errorf("--offline and --record-cassette are mutually exclusive")
+	}
+	if (clientCert == "") != (clientKey == "") {
+		return fmt.Errorf("--client-cert and --client-key must be set together")
+	}
+	if err := github.ValidateAuthProviders(authProviders); err != nil {
+		return err
+	}
+	if chaosRate < 0 || chaosRate > 1 {
+		return fmt.Errorf("--chaos must be between 0 and 1")
+	}
+	if chaosRate > 0 {
+		fmt.Fprintf(os.Stderr, "[WARN]: Chaos mode enabled, simulated failures are injected into %.0f%% of the requests to GitHub. Only use it with staging organizations.\n", chaosRate*100)
+	}
+	if configFilename == "" {
+		configFilename = os.Getenv(configEnv)
+	}
+	if configFilename == "" {
+		file, err := discoverConfig(defaultConfigFilename)
+		if err != nil {
+			return fmt.Errorf("failed to discover config: %w", err)
+		}
+		configFilename = file
+	}
+	github.SetHTTPOptions(github.HTTPOptions{
+		RecordCassette: recordCassette,
+		ReplayCassette: replayCassette,
+		CABundle:       caBundle,
+		ClientCert:     clientCert,
+		ClientKey:      clientKey,
+		DialTimeout:    dialTimeout,
+		FallbackDelay:  fallbackDelay,
+		DNSRetries:     dnsRetries,
+		AuthProviders:  authProviders,
+		ChaosRate:      chaosRate,
+		ChaosSeed:      chaosSeed,
+		Offline:        offline,
+	})
+	if offline {
+		// Webhooks and calendars are fetched with the default client.
+		http.DefaultClient.Transport = github.OfflineTransport
+	}
+	return nil
+}
+
+const (
+	// defaultConfigFilename is the name of the config file discovered by
+	// discoverConfig.
+	defaultConfigFilename = "team-assignments.yaml"
+	// configEnv is the environment variable overriding the config file.
+	configEnv = "TEAM_MANAGER_CONFIG"
+)
+
+// discoverConfig returns the path, relative to the current directory, of the
+// file with the given name in the current directory or in its closest parent
+// directory having one, as git does with .git. It returns name if there is
+// no such file, e.g. for init to create it in the current directory.
+func discoverConfig(name string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for dir := cwd; ; {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err == nil {
+			return filepath.Rel(cwd, file)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return name, nil
+		}
+		dir = parent
+	}
+}
+
+// redacting returns true if the personal data of the members must be omitted
+// from the output, according to the --redact-names flag or to the policy of
+// cfg, if any.
+func redacting(cfg *config.Config) bool {
+	return redactNames || (cfg != nil && cfg.Policy.RedactNames)
+}
+
+// redact returns s, or a placeholder if s is personal data that must be
+// omitted according to redacting.
+func redact(cfg *config.Config, s string) string {
+	if s != "" && redacting(cfg) {
+		return "[redacted]"
+	}
+	return s
+}
+
+// NewRootCommand returns the team-manager command with all its subcommands.
+func NewRootCommand(deps Deps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "team-manager",
+		Short: "Manage GitHub team state locally and synchronize it with GitHub",
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return ApplyGlobalFlags()
+		},
+	}
+	AddGlobalFlags(cmd)
+
+	cmd.AddCommand(
+		NewActionCommand(deps),
+		NewActivityCommand(deps),
+		NewAddPtoCommand(deps),
+		NewAddTeamCommand(deps),
+		NewAddUserCommand(deps),
+		NewApplyCommand(deps),
+		NewApplyRepoTemplatesCommand(deps),
+		NewAttributeCommand(deps),
+		NewAuditCommand(deps),
+		NewAuditSampleCommand(deps),
+		NewBadgesCommand(deps),
+		NewCheckCommand(deps),
+		NewCheckBranchProtectionCommand(deps),
+		NewCheckRepoTopicsCommand(deps),
+		NewCodeOwnersCommand(deps),
+		NewExclusionsCommand(deps),
+		NewExportCommand(deps),
+		NewImportMembersCommand(deps),
+		NewInitCommand(deps),
+		NewInstallHooksCommand(deps),
+		NewInvitationsCommand(deps),
+		NewLintCommand(deps),
+		NewLoginCommand(deps),
+		NewLogoutCommand(deps),
+		NewMembershipsCommand(deps),
+		NewMergeTeamsCommand(deps),
+		NewMigrateProjectsCommand(deps),
+		NewMoveCommand(deps),
+		NewOnboardCommand(deps),
+		NewPermissionsCommand(deps),
+		NewPlanCommand(deps),
+		NewPreviewCommand(deps),
+		NewPurgeUserDataCommand(deps),
+		NewPushCommand(deps),
+		NewReleasesCommand(deps),
+		NewRemovePtoCommand(deps),
+		NewRenameTeamCommand(deps),
+		NewReportCommand(deps),
+		NewRetireTeamCommand(deps),
+		NewServeCommand(deps),
+		NewSetTeamCommand(deps),
+		NewSnapshotCommand(deps),
+		NewSplitTeamCommand(deps),
+		NewSsoIdentitiesCommand(deps),
+		NewTreeCommand(deps),
+		NewTrendsCommand(deps),
+		NewWhatIfCommand(deps),
+		NewWhoReviewsCommand(deps),
+	)
+	return WithPermissionExplanation(WithDeprecationWarnings(WithErrorTranslation(cmd)))
+}
+
+// WithErrorTranslation wraps the RunE functions of cmd and of all its
+// subcommands so that the common GitHub API errors they return come with a
+// hint on how to resolve them, see github.TranslateError.
+func WithErrorTranslation(cmd *cobra.Command) *cobra.Command {
+	if runE := cmd.RunE; runE != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return github.TranslateError(runE(cmd, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		WithErrorTranslation(sub)
+	}
+	return cmd
+}
+
+// WithDeprecationWarnings wraps the RunE functions of cmd and of all its
+// subcommands so that the deprecation notices of the GitHub API received while
+// running them are printed in a dedicated section once they return, whether
+// they fail or not, see github.Deprecations.
+func WithDeprecationWarnings(cmd *cobra.Command) *cobra.Command {
+	if runE := cmd.RunE; runE != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			err := runE(cmd, args)
+			printDeprecations(os.Stderr, github.Deprecations())
+			return err
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		WithDeprecationWarnings(sub)
+	}
+	return cmd
+}
+
+// printDeprecations prints the given deprecation notices of the GitHub API,
+// if any.
+func printDeprecations(w io.Writer, deprecations []github.Deprecation) {
+	if len(deprecations) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "GitHub API deprecation warnings:")
+	for _, d := range deprecations {
+		fmt.Fprintf(w, "  [WARN]: %s\n", d)
+	}
+	fmt.Fprintln(w, "Upgrade team-manager or report these warnings before the deprecated APIs are removed.")
+}
@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	gh "github.com/google/go-github/v33/github"
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/notify"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// serveOptions are the flags of the serve command.
+type serveOptions struct {
+	listenAddress      string
+	dryRun             bool
+	overrideFreeze     bool
+	driftCheckInterval time.Duration
+	digestInterval     time.Duration
+	immediateSeverity  string
+}
+
+// NewServeCommand returns the serve command.
+func NewServeCommand(deps Deps) *cobra.Command {
+	opts := &serveOptions{}
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run as a daemon reacting to GitHub webhook events",
+		Long: `Runs an HTTP server receiving organization webhook events on /webhook. The
+webhook secret is read from the GITHUB_WEBHOOK_SECRET environment variable.
+
+On repository creation events the repository templates of the configuration
+file are applied to the new repository.
+
+With --drift-check-interval, the drift between the configuration file and
+GitHub is checked periodically, and every drift with at least the severity
+set in 'drift.failOn' of the configuration is logged as an alert.
+
+Drift alerts and applied changes are batched into a notification digest sent
+every --digest-interval, only notifications with at least the severity of
+--immediate-severity are sent right away. Notifications are posted to the
+Slack incoming webhook set in the SLACK_WEBHOOK_URL environment variable, or
+logged if it is not set.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			secret := os.Getenv("GITHUB_WEBHOOK_SECRET")
+			if secret == "" {
+				return fmt.Errorf("environment variable GITHUB_WEBHOOK_SECRET must be set to validate webhook events")
+			}
+
+			immediate := config.Severity(opts.immediateSeverity)
+			if !immediate.IsValid() {
+				return fmt.Errorf("invalid --immediate-severity %q", opts.immediateSeverity)
+			}
+			var notifier notify.Notifier = notify.Log{}
+			if url := os.Getenv("SLACK_WEBHOOK_URL"); url != "" {
+				notifier = notify.SlackWebhook{URL: url}
+			}
+			digest := notify.NewDigest(notifier, opts.digestInterval, immediate)
+			go digest.Run(cmd.Context())
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+			tm := team.NewManager(ghClient, ghGraphQLClient, orgName)
+
+			if !opts.dryRun {
+				if err = preflight(cmd.Context(), ghClient, github.OperationManageRepositoryAccess, github.OperationManageRepositoryProperties, github.OperationOpenPullRequests); err != nil {
+					return err
+				}
+			}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
+				payload, err := gh.ValidatePayload(r, []byte(secret))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				event, err := gh.ParseWebHook(gh.WebHookType(r), payload)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				// Reply right away, GitHub times out webhook deliveries after
+				// 10 seconds.
+				w.WriteHeader(http.StatusAccepted)
+
+				go handleWebhookEvent(cmd.Context(), deps, opts, tm, digest, event)
+			})
+
+			srv := &http.Server{
+				Addr:              opts.listenAddress,
+				Handler:           mux,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			go func() {
+				<-cmd.Context().Done()
+				srv.Close()
+			}()
+
+			if opts.driftCheckInterval != 0 {
+				go checkDriftPeriodically(cmd.Context(), deps, tm, digest, opts.driftCheckInterval)
+			}
+
+			log.Printf("Listening for webhook events on %s", opts.listenAddress)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.listenAddress, "listen-address", ":8080", "Address to listen on for GitHub webhook events")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Dry run the steps without performing any write operation to GitHub")
+	cmd.Flags().BoolVar(&opts.overrideFreeze, "override-freeze", false, "Apply changes even during a freeze window")
+	cmd.Flags().DurationVar(&opts.driftCheckInterval, "drift-check-interval", 0, "Interval of the drift checks alerting on drift with at least the 'drift.failOn' severity, 0 to disable them")
+	cmd.Flags().DurationVar(&opts.digestInterval, "digest-interval", time.Hour, "Interval of the notification digest")
+	cmd.Flags().StringVar(&opts.immediateSeverity, "immediate-severity", string(config.SeverityCritical), "Minimum severity of the notifications sent right away instead of in the digest")
+
+	return requireOperations(cmd, github.OperationReadTeams, github.OperationManageRepositoryAccess, github.OperationManageRepositoryProperties, github.OperationOpenPullRequests)
+}
+
+func handleWebhookEvent(ctx context.Context, deps Deps, opts *serveOptions, tm *team.Manager, digest *notify.Digest, event interface{}) {
+	switch e := event.(type) {
+	case *gh.RepositoryEvent:
+		if e.GetAction() != "created" || e.GetOrg().GetLogin() != orgName {
+			return
+		}
+		// The configuration is re-read for every event so that the daemon
+		// picks up changes without being restarted.
+		cfg, err := loadCheckedState(deps)
+		if err != nil {
+			log.Printf("[ERROR]: Unable to load local state: %s", err)
+			return
+		}
+		repo := e.GetRepo().GetName()
+		log.Printf("Repository %s created by %s", repo, e.GetSender().GetLogin())
+		if !opts.dryRun {
+			if err := checkFreeze(ctx, cfg, opts.overrideFreeze); err != nil {
+				log.Printf("[ERROR]: Not handling creation of repository %s: %s", repo, err)
+				return
+			}
+		}
+		if err := tm.HandleRepositoryCreated(ctx, cfg, repo, opts.dryRun); err != nil {
+			log.Printf("[ERROR]: Unable to handle creation of repository %s: %s", repo, err)
+			digest.Add(ctx, config.SeverityWarning, fmt.Sprintf("Unable to apply repository templates to %s: %s", repo, err))
+			return
+		}
+		if !opts.dryRun {
+			digest.Add(ctx, config.SeverityInfo, fmt.Sprintf("Applied repository templates to %s", repo))
+		}
+	}
+}
+
+// checkDriftPeriodically logs an alert and adds a notification to the digest
+// for every new drift between the local and the upstream configuration with
+// at least the severity threshold of the drift policy, every interval until
+// ctx is done. Drifts are only reported again once they were resolved.
+func checkDriftPeriodically(ctx context.Context, deps Deps, tm *team.Manager, digest *notify.Digest, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// seen are the hashes of the drifts found by the last check.
+	seen := map[string]bool{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cfg, err := loadCheckedState(deps)
+		if err != nil {
+			log.Printf("[ERROR]: Unable to load local state: %s", err)
+			continue
+		}
+		upstreamCfg, err := tm.GetCurrentConfig(ctx)
+		if err != nil {
+			log.Printf("[ERROR]: Unable to read config from GitHub: %s", err)
+			continue
+		}
+		current := map[string]bool{}
+		for _, d := range team.DriftsAtLeast(team.ComputeDrift(team.EffectiveConfig(cfg, upstreamCfg, time.Now()), upstreamCfg), cfg.Drift.Threshold()) {
+			current[d.Hash()] = true
+			if seen[d.Hash()] {
+				continue
+			}
+			log.Printf("[ALERT]: %s", d)
+			digest.Add(ctx, d.Severity, d.String())
+		}
+		seen = current
+	}
+}
+
+// loadCheckedState loads the local config and performs a sanity check on it.
+func loadCheckedState(deps Deps) (*config.Config, error) {
+	cfg, err := deps.LoadState(configFilename)
+	if err != nil {
+		return nil, err
+	}
+	if err = config.SanityCheck(cfg); err != nil {
+		return nil, fmt.Errorf("failed to perform sanity check: %w", err)
+	}
+	return cfg, nil
+}
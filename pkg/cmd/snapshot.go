@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/persistence"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewSnapshotCommand returns the snapshot command.
+func NewSnapshotCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		filename   string
+		full       bool
+		historyDir string
+	}
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Store a snapshot of the upstream configuration, only re-fetching the teams updated since the previous snapshot",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+
+			tm := team.NewManager(nil, ghGraphQLClient, orgName)
+
+			var prev *config.Snapshot
+			if !opts.full {
+				prev, err = persistence.LoadSnapshot(opts.filename)
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("failed to load previous snapshot: %w", err)
+				}
+			}
+
+			fmt.Println("Retrieving configuration from organization...")
+			snapshot, err := tm.GetSnapshot(cmd.Context(), prev)
+			if err != nil {
+				return fmt.Errorf("failed to read config from GitHub: %w", err)
+			}
+
+			// The local configuration is optional, it only tells whether
+			// the personal data of the members is redacted.
+			localCfg, err := deps.LoadState(configFilename)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			if redacting(localCfg) {
+				team.RedactMembers(snapshot.Config)
+			}
+
+			fmt.Printf("Storing snapshot %q...\n", opts.filename)
+			if err = persistence.StoreSnapshot(opts.filename, snapshot); err != nil {
+				return fmt.Errorf("failed to store snapshot: %w", err)
+			}
+
+			if opts.historyDir != "" {
+				if err = os.MkdirAll(opts.historyDir, 0o755); err != nil {
+					return fmt.Errorf("failed to create snapshot history directory: %w", err)
+				}
+				file := filepath.Join(opts.historyDir, snapshot.CreatedAt.UTC().Format("20060102T150405Z")+".yaml")
+				fmt.Printf("Storing snapshot %q...\n", file)
+				if err = persistence.StoreSnapshot(file, snapshot); err != nil {
+					return fmt.Errorf("failed to store snapshot: %w", err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.filename, "snapshot-filename", "upstream-snapshot.yaml", "Snapshot filename")
+	cmd.Flags().BoolVar(&opts.full, "full", false, "Fetch all teams instead of only the ones updated since the previous snapshot")
+	cmd.Flags().StringVar(&opts.historyDir, "history-dir", "", "Also store the snapshot into this directory, keeping the previous ones for 'trends'")
+
+	return requireOperations(cmd, github.OperationReadTeams)
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/team"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// NewSplitTeamCommand returns the split-team command.
+func NewSplitTeamCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		mapping string
+		dryRun  bool
+	}
+	cmd := &cobra.Command{
+		Use:   "split-team SRC NEW [NEW ...]",
+		Short: "Split a team into new teams in local configuration",
+		Long: `Distributes the members of the team SRC into the new teams NEW, created in
+the local configuration with the parent, privacy, notification setting and
+code review assignment settings of SRC. The members of SRC keep their
+exclusions from the code review assignment and, if the roles of SRC are
+managed, their maintainer role in the new teams they are assigned to.
+
+The team of every member of SRC is asked for interactively, unless a mapping
+file is given with --mapping, mapping the new teams to their members:
+
+  sig-foo-datapath: [alice, bob]
+  sig-foo-docs: [carol]
+
+Members can be assigned to several new teams. The assigned members are removed
+from SRC, the ones not assigned to any new team stay members of SRC.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, newTeams := args[0], args[1:]
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			srcCfg, ok := cfg.Teams[src]
+			if !ok {
+				return fmt.Errorf("unknown team %q", src)
+			}
+
+			var assignment map[string][]string
+			if opts.mapping != "" {
+				if assignment, err = loadSplitMapping(opts.mapping, newTeams); err != nil {
+					return fmt.Errorf("failed to load mapping: %w", err)
+				}
+			} else if assignment, err = askForSplit(srcCfg.Members, newTeams); err != nil {
+				return err
+			}
+
+			split, err := team.SplitTeamInConfig(cfg, src, assignment)
+			if err != nil {
+				return fmt.Errorf("failed to split team: %w", err)
+			}
+			if err = config.SanityCheck(cfg); err != nil {
+				return fmt.Errorf("split configuration is invalid: %w", err)
+			}
+			for _, teamName := range newTeams {
+				members := cfg.Teams[teamName].Members
+				if len(members) == 0 {
+					fmt.Fprintf(os.Stderr, "[WARN]: team %s has no members\n", teamName)
+				}
+				fmt.Printf("Team %s: %s\n", teamName, orDash(strings.Join(members, ", ")))
+			}
+			fmt.Printf("Team %s: %s\n", src, orDash(strings.Join(split.Remaining, ", ")))
+			if opts.dryRun {
+				return nil
+			}
+
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.mapping, "mapping", "", "YAML file mapping the new teams to their members instead of asking for them")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the new teams without storing them into the configuration")
+
+	return cmd
+}
+
+// loadSplitMapping returns the assignment of members to the new teams read
+// from the given mapping file, which must only contain new teams.
+func loadSplitMapping(file string, newTeams []string) (map[string][]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	mapping := map[string][]string{}
+	if err = yaml.UnmarshalStrict(data, &mapping); err != nil {
+		return nil, err
+	}
+	assignment := make(map[string][]string, len(newTeams))
+	for _, teamName := range newTeams {
+		assignment[teamName] = mapping[teamName]
+		delete(mapping, teamName)
+	}
+	for teamName := range mapping {
+		return nil, fmt.Errorf("team %q is not one of the new teams", teamName)
+	}
+	return assignment, nil
+}
+
+// askForSplit asks for the new teams every member is assigned to, and returns
+// the resulting assignment of members to the new teams.
+func askForSplit(members, newTeams []string) (map[string][]string, error) {
+	assignment := make(map[string][]string, len(newTeams))
+	for _, teamName := range newTeams {
+		assignment[teamName] = nil
+	}
+	for _, member := range members {
+		for {
+			answer, err := terminal.AskForInput(fmt.Sprintf("Teams of %s (%s, comma-separated, empty to keep)", member, strings.Join(newTeams, ", ")), "")
+			if err != nil {
+				return nil, err
+			}
+			var unknown []string
+			teams := strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' })
+			for _, teamName := range teams {
+				if _, ok := assignment[teamName]; !ok {
+					unknown = append(unknown, teamName)
+				}
+			}
+			if len(unknown) != 0 {
+				fmt.Fprintf(os.Stderr, "[ERROR]: %s are not new teams\n", strings.Join(unknown, ", "))
+				continue
+			}
+			for _, teamName := range teams {
+				assignment[teamName] = append(assignment[teamName], member)
+			}
+			break
+		}
+	}
+	return assignment, nil
+}
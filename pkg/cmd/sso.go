@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewSsoIdentitiesCommand returns the sso-identities command.
+func NewSsoIdentitiesCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		dryRun bool
+	}
+	cmd := &cobra.Command{
+		Use:   "sso-identities",
+		Short: "Map the members to their SAML single sign-on identities and report the members without one",
+		Long: `Reads the SAML single sign-on identities linked to the GitHub accounts of the
+organization members, for organizations with SAML single sign-on, and stores
+their name IDs and emails into the 'ssoNameId' and 'ssoEmail' metadata of the
+members of the configuration. Members of the configuration without a linked
+identity are reported, as well as the members marked as bots without any
+credential authorized for SAML single sign-on, whose automation can't access
+the organization.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+			tm := team.NewManager(ghClient, ghGraphQLClient, orgName)
+			identities, err := tm.ListSSOIdentities(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list SSO identities: %w", err)
+			}
+
+			unlinked := team.SetSSOIdentities(cfg, identities)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "LOGIN\tNAME ID\tEMAIL")
+			for _, identity := range identities {
+				if _, ok := cfg.Members[identity.Login]; !ok {
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", identity.Login, redact(cfg, identity.NameID), orDash(redact(cfg, identity.Email)))
+			}
+			if err = w.Flush(); err != nil {
+				return err
+			}
+			for _, login := range unlinked {
+				fmt.Fprintf(os.Stderr, "[WARN]: Member %s has no linked SSO identity\n", login)
+			}
+
+			if hasBots(cfg) {
+				auths, err := tm.ListCredentialAuthorizations(cmd.Context())
+				if err != nil {
+					return fmt.Errorf("failed to list SSO credential authorizations: %w", err)
+				}
+				for _, login := range team.UnauthorizedBots(cfg, auths) {
+					fmt.Fprintf(os.Stderr, "[WARN]: Bot %s has no credential authorized for SSO, its automation won't be able to access %s\n", login, orgName)
+				}
+			}
+
+			if !opts.dryRun {
+				if err = deps.StoreState(configFilename, cfg); err != nil {
+					return fmt.Errorf("failed to store state to config: %w", err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Only report the SSO identities without storing them into the configuration")
+
+	return requireOperations(cmd, github.OperationReadSSOIdentities)
+}
+
+func hasBots(cfg *config.Config) bool {
+	for _, user := range cfg.Members {
+		if user.Bot {
+			return true
+		}
+	}
+	return false
+}
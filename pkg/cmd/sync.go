@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// pushOptions are the flags of the push command.
+type pushOptions struct {
+	dryRun          bool
+	force           bool
+	verifyTimeout   time.Duration
+	pruneTeams      bool
+	pruneExclusions bool
+	removeFromOrg   bool
+	overrideFreeze  bool
+	reportFormat    string
+}
+
+// pushOperations are the operations push may perform against GitHub.
+var pushOperations = []github.Operation{
+	github.OperationReadTeams,
+	github.OperationManageTeams,
+	github.OperationManageRepositoryAccess,
+	github.OperationManageOrgSettings,
+	github.OperationManageSecurityManagers,
+	github.OperationManageLabels,
+}
+
+// NewPushCommand returns the push command.
+func NewPushCommand(deps Deps) *cobra.Command {
+	var opts pushOptions
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Update team assignments in GitHub from local files",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runPush(cmd, deps, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Dry run the steps without performing any write operation to GitHub")
+	cmd.Flags().BoolVar(&opts.force, "force", false, "Force local changes into GitHub without asking for configuration")
+	cmd.Flags().BoolVar(&opts.pruneTeams, "prune-teams", false, "Delete the teams in GitHub that are not part of the configuration")
+	cmd.Flags().BoolVar(&opts.pruneExclusions, "prune-exclusions", false, "Remove the expired code review exclusions from the configuration")
+	cmd.Flags().BoolVar(&opts.removeFromOrg, "remove-from-org", false, "Also remove the users removed from their last team from the organization, after a separate confirmation unless --force is set")
+	cmd.Flags().DurationVar(&opts.verifyTimeout, "verify-timeout", 0, "Wait up to this long for membership changes to be reflected by GitHub, 0 to not verify them")
+	cmd.Flags().BoolVar(&opts.overrideFreeze, "override-freeze", false, "Apply changes even during a freeze window")
+	cmd.Flags().StringVar(&opts.reportFormat, "report-format", "text", "Format of the report of the changes, one of: text, json (requires --force), github, silent")
+
+	return requireOperations(cmd, pushOperations...)
+}
+
+// runPush pushes the local configuration to GitHub as set by the given flags
+// of the push command.
+func runPush(cmd *cobra.Command, deps Deps, opts pushOptions) error {
+	cfg, err := deps.LoadState(configFilename)
+	if err != nil {
+		return fmt.Errorf("failed to load local state: %w", err)
+	}
+
+	if err = config.SanityCheck(cfg); err != nil {
+		return fmt.Errorf("failed to perform sanity check: %w", err)
+	}
+
+	if !opts.dryRun {
+		if err = checkFreeze(cmd.Context(), cfg, opts.overrideFreeze); err != nil {
+			return err
+		}
+	}
+
+	ghClient, err := deps.NewClient()
+	if err != nil {
+		return fmt.Errorf("failed to create github client: %w", err)
+	}
+
+	ghGraphQLClient, err := deps.NewGraphQLClient()
+	if err != nil {
+		return fmt.Errorf("failed to create github graphql client: %w", err)
+	}
+	reporter, err := newReporter(opts.reportFormat, opts.force)
+	if err != nil {
+		return err
+	}
+	tm := team.NewManager(ghClient, ghGraphQLClient, orgName)
+	tm.SetReporter(reporter)
+	tm.SetCustomFields(cfg.CustomFields)
+	tm.SetVerifyTimeout(opts.verifyTimeout)
+	tm.SetRemoveFromOrg(opts.removeFromOrg)
+
+	ops := []github.Operation{github.OperationManageTeams}
+	if len(cfg.OutsideCollaborators) != 0 || hasTeamRepositories(cfg) {
+		ops = append(ops, github.OperationManageRepositoryAccess)
+	}
+	if cfg.Settings != (config.OrgSettings{}) {
+		ops = append(ops, github.OperationManageOrgSettings)
+	}
+	if len(cfg.SecurityManagers) != 0 {
+		ops = append(ops, github.OperationManageSecurityManagers)
+	}
+	if len(cfg.Labels) != 0 {
+		ops = append(ops, github.OperationManageLabels)
+	}
+	if err = preflight(cmd.Context(), ghClient, ops...); err != nil {
+		return err
+	}
+
+	var pruned []team.Exclusion
+	if opts.pruneExclusions {
+		pruned = team.PruneExpiredExclusions(cfg, time.Now())
+		for _, x := range pruned {
+			fmt.Fprintf(os.Stderr, "Pruning expired exclusion of %s from team %s, until %s\n", x.Login, x.Team, x.Until)
+		}
+	}
+
+	ids, slugs, memberIDs, applied, appliedOptions := teamIDs(cfg), teamSlugs(cfg), userIDs(cfg), appliedExclusions(cfg), appliedReviewOptions(cfg)
+	cfg, err = tm.SyncTeams(cmd.Context(), cfg, opts.force, opts.dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to sync teams to GitHub: %w", err)
+	}
+
+	// Store the metadata retrieved for the custom fields, when the
+	// pending removals were first seen, the IDs of the created teams, the
+	// slugs fetched from GitHub, the members renamed in GitHub, the pruned
+	// exclusions and the members excluded from and options of the updated
+	// code review assignments.
+	if (len(cfg.CustomFields.Team) != 0 || len(cfg.CustomFields.Member) != 0 || cfg.Policy.GraceDays != 0 || len(pruned) != 0 ||
+		!reflect.DeepEqual(ids, teamIDs(cfg)) || !reflect.DeepEqual(slugs, teamSlugs(cfg)) || !reflect.DeepEqual(memberIDs, userIDs(cfg)) ||
+		!reflect.DeepEqual(applied, appliedExclusions(cfg)) || !reflect.DeepEqual(appliedOptions, appliedReviewOptions(cfg))) && !opts.dryRun {
+		if err = deps.StoreState(configFilename, cfg); err != nil {
+			return fmt.Errorf("failed to store state to config: %w", err)
+		}
+	}
+
+	if opts.pruneTeams {
+		if err = tm.PruneTeams(cmd.Context(), cfg, opts.force, opts.dryRun); err != nil {
+			return fmt.Errorf("failed to prune teams: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// newReporter returns the reporter of the changes for the --report-format
+// flag. JSON reports require force, as the confirmation prompts would be
+// interleaved with the JSON events on stdout.
+func newReporter(reportFormat string, force bool) (team.Reporter, error) {
+	if reportFormat == "json" && !force {
+		return nil, fmt.Errorf("--report-format json requires --force")
+	}
+	switch reportFormat {
+	case "text":
+		return &team.TextReporter{Out: os.Stdout, Err: os.Stderr}, nil
+	case "json":
+		return team.NewJSONReporter(os.Stdout), nil
+	case "github":
+		return newGitHubReporter(), nil
+	case "silent":
+		return team.SilentReporter{}, nil
+	}
+	return nil, fmt.Errorf("unknown report format %q", reportFormat)
+}
+
+// teamIDs maps the names of the teams of cfg to their IDs.
+func teamIDs(cfg *config.Config) map[string]string {
+	ids := make(map[string]string, len(cfg.Teams))
+	for teamName, teamCfg := range cfg.Teams {
+		ids[teamName] = teamCfg.ID
+	}
+	return ids
+}
+
+// teamSlugs maps the names of the teams of cfg to their slugs.
+func teamSlugs(cfg *config.Config) map[string]string {
+	slugs := make(map[string]string, len(cfg.Teams))
+	for teamName, teamCfg := range cfg.Teams {
+		slugs[teamName] = teamCfg.Slug
+	}
+	return slugs
+}
+
+// userIDs maps the logins of the members of cfg to their IDs.
+func userIDs(cfg *config.Config) map[string]string {
+	ids := make(map[string]string, len(cfg.Members))
+	for login, user := range cfg.Members {
+		ids[login] = user.ID
+	}
+	return ids
+}
+
+// appliedExclusions returns a copy of the members excluded from the code
+// review assignments of the teams of cfg when push last updated them.
+func appliedExclusions(cfg *config.Config) map[string][]string {
+	applied := make(map[string][]string, len(cfg.AppliedExclusions))
+	for teamName, logins := range cfg.AppliedExclusions {
+		applied[teamName] = append([]string{}, logins...)
+	}
+	return applied
+}
+
+// appliedReviewOptions returns a copy of the options of the code review
+// assignments of the teams of cfg when push last updated them.
+func appliedReviewOptions(cfg *config.Config) map[string]config.ReviewAssignmentOptions {
+	applied := make(map[string]config.ReviewAssignmentOptions, len(cfg.AppliedReviewOptions))
+	for teamName, options := range cfg.AppliedReviewOptions {
+		applied[teamName] = options
+	}
+	return applied
+}
+
+// hasTeamRepositories returns true if the repository access of any team of
+// cfg is managed.
+func hasTeamRepositories(cfg *config.Config) bool {
+	for _, teamCfg := range cfg.Teams {
+		if len(teamCfg.Repositories) != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	gh "github.com/google/go-github/v33/github"
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// NewAddTeamCommand returns the add-team command.
+func NewAddTeamCommand(deps Deps) *cobra.Command {
+	return requireOperations(&cobra.Command{
+		Use:   "add-team TEAM [TEAM ...]",
+		Short: "Add team to local configuration by their slug name",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+
+			cfg, err := deps.LoadState(configFilename)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			if err = addTeamsToConfig(cmd.Context(), args, cfg, ghClient); err != nil {
+				return fmt.Errorf("failed to add teams to config: %w", err)
+			}
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+
+			return nil
+		},
+	}, github.OperationReadTeams)
+}
+
+// NewSetTeamCommand returns the set-team command.
+func NewSetTeamCommand(deps Deps) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-team TEAM USER [USER ...]",
+		Short: "Set members of a team in local configuration",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := deps.LoadState(configFilename)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			if err = setTeamMembers(args[0], args[1:], cfg); err != nil {
+				return fmt.Errorf("failed to set team members: %w", err)
+			}
+
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
+			}
+
+			return nil
+		},
+	}
+}
+
+func addTeamsToConfig(ctx context.Context, addTeams []string, cfg *config.Config, ghClient *gh.Client) error {
+	for _, addTeam := range addTeams {
+		t, _, err := ghClient.Teams.GetTeamBySlug(ctx, orgName, addTeam)
+		if err != nil {
+			return fmt.Errorf("failed to get GitHub team: %w", err)
+		}
+		if _, ok := cfg.Teams[t.GetName()]; ok {
+			return fmt.Errorf("team %q already exists", t.GetName())
+		}
+		cfg.Teams[t.GetName()] = config.TeamConfig{
+			ID:          t.GetNodeID(),
+			Slug:        t.GetSlug(),
+			Description: t.GetDescription(),
+			Privacy:     teamPrivacy(t.GetPrivacy()),
+		}
+	}
+
+	return nil
+}
+
+func setTeamMembers(team string, users []string, cfg *config.Config) error {
+	members, err := findUsers(cfg, users)
+	if err != nil {
+		return fmt.Errorf("unable to find users: %w", err)
+	}
+	teamConfig, ok := cfg.Teams[team]
+	if !ok {
+		return fmt.Errorf("unknown team %q", team)
+	}
+	memberSet := set.New(members...)
+	teamConfig.Members = memberSet.Elements()
+	// Maintainers must be members of the team.
+	var maintainers []string
+	for _, maintainer := range teamConfig.Maintainers {
+		if memberSet.Has(maintainer) {
+			maintainers = append(maintainers, maintainer)
+		}
+	}
+	teamConfig.Maintainers = maintainers
+	cfg.Teams[team] = teamConfig
+
+	return nil
+}
+
+func addTeamMembers(team string, users []string, cfg *config.Config) error {
+	teamConfig, ok := cfg.Teams[team]
+	if !ok {
+		return fmt.Errorf("unknown team %q", team)
+	}
+	newMembers := set.New(append(teamConfig.Members, users...)...)
+	return setTeamMembers(team, newMembers.Elements(), cfg)
+}
+
+// teamPrivacy returns the config privacy of the given REST API team privacy.
+func teamPrivacy(privacy string) config.TeamPrivacy {
+	if privacy == "secret" {
+		return config.TeamPrivacySecret
+	}
+	return config.TeamPrivacyVisible
+}
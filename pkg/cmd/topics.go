@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewCheckRepoTopicsCommand returns the check-repo-topics command.
+func NewCheckRepoTopicsCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		prefix         string
+		fix            bool
+		permission     string
+		force          bool
+		overrideFreeze bool
+	}
+	cmd := &cobra.Command{
+		Use:   "check-repo-topics",
+		Short: "Check that repository topics referencing teams are consistent with the teams' repository access",
+		Long: `Checks that every repository tagged with the topic of a team, e.g.
+'team-datapath' for the team 'datapath', grants that team access, and that
+every repository a team of the local configuration has access to is tagged
+with its topic.
+
+The issues found are printed together with a plan to fix them, which is
+applied with --fix.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			perm := config.RepositoryPermission(opts.permission)
+			if !perm.IsValid() {
+				return fmt.Errorf("invalid permission %q", opts.permission)
+			}
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			tm := team.NewManager(ghClient, nil, orgName)
+
+			issues, err := tm.CheckRepositoryTopics(cmd.Context(), cfg, opts.prefix)
+			if err != nil {
+				return fmt.Errorf("failed to check repository topics: %w", err)
+			}
+			for _, issue := range issues {
+				fmt.Println(issue)
+			}
+			if len(issues) == 0 {
+				return nil
+			}
+			if opts.fix {
+				if err = checkFreeze(cmd.Context(), cfg, opts.overrideFreeze); err != nil {
+					return err
+				}
+				if err = preflight(cmd.Context(), ghClient, github.OperationManageRepositoryAccess, github.OperationManageRepositoryTopics); err != nil {
+					return err
+				}
+				return tm.FixRepositoryTopics(cmd.Context(), issues, opts.prefix, perm, opts.force)
+			}
+			return fmt.Errorf("found %d inconsistencies between repository topics and team access", len(issues))
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.prefix, "topic-prefix", "team-", "Prefix of the repository topics referencing teams")
+	cmd.Flags().BoolVar(&opts.fix, "fix", false, "Grant access and add topics to resolve the issues found")
+	cmd.Flags().StringVar(&opts.permission, "permission", string(config.RepositoryPermissionPush), "Permission granted to teams by --fix")
+	cmd.Flags().BoolVar(&opts.force, "opts.force", false, "Do not ask for confirmation before applying the fixes")
+	cmd.Flags().BoolVar(&opts.overrideFreeze, "override-freeze", false, "Apply changes even during a freeze window")
+
+	return requireOperations(cmd, github.OperationReadRepositories, github.OperationManageRepositoryAccess, github.OperationManageRepositoryTopics)
+}
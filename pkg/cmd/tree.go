@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewTreeCommand returns the tree command.
+func NewTreeCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		format string
+	}
+	cmd := &cobra.Command{
+		Use:   "tree",
+		Short: "Print the team hierarchy with member counts, marking the teams managed by the configuration",
+		Long: `Prints the hierarchy of the teams of the organization and of the local
+configuration with their member counts. Every team is marked as managed if it
+is part of both the configuration and GitHub, as unmanaged if it only exists
+in GitHub, or as missing if it only exists in the configuration.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+
+			ghGraphQLClient, err := deps.NewGraphQLClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github graphql client: %w", err)
+			}
+			upstreamCfg, err := team.NewManager(nil, ghGraphQLClient, orgName).GetCurrentConfig(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to read config from GitHub: %w", err)
+			}
+
+			roots := team.BuildTeamTree(cfg, upstreamCfg)
+			switch opts.format {
+			case "text":
+				team.PrintTeamTree(os.Stdout, roots)
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err = enc.Encode(roots); err != nil {
+					return fmt.Errorf("failed to write tree: %w", err)
+				}
+			default:
+				return fmt.Errorf("unknown tree format %q", opts.format)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "text", "Output format, one of: text, json")
+
+	return requireOperations(cmd, github.OperationReadTeams)
+}
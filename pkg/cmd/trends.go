@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/persistence"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// NewTrendsCommand returns the trends command.
+func NewTrendsCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		snapshotsDir string
+		format       string
+		output       string
+		since        string
+		until        string
+	}
+	cmd := &cobra.Command{
+		Use:   "trends",
+		Short: "Export team size, churn and exclusion trends from the stored snapshots for plotting",
+		Long: `Exports, for every snapshot stored with 'snapshot --history-dir', the number of
+members of every team and of the whole organization, the number of members
+added and removed since the previous snapshot, the churn rate, i.e. the added
+and removed members relative to the previous number of members, and the number
+of members excluded from code review assignments. Exclusions can't be
+retrieved from GitHub, they are counted in the revision of the configuration
+committed at the time of every snapshot.`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			snapshots, err := persistence.LoadSnapshots(opts.snapshotsDir)
+			if err != nil {
+				return fmt.Errorf("failed to load snapshots: %w", err)
+			}
+			snapshots, err = snapshotsBetween(snapshots, opts.since, opts.until)
+			if err != nil {
+				return err
+			}
+			if len(snapshots) == 0 {
+				return fmt.Errorf("no snapshots found in %q", opts.snapshotsDir)
+			}
+
+			history, err := configHistory(deps, configFilename)
+			if err != nil {
+				return fmt.Errorf("failed to read configuration history: %w", err)
+			}
+			localCfgs := make([]*config.Config, len(snapshots))
+			for i, snapshot := range snapshots {
+				for _, rev := range history {
+					if rev.CommittedAt.After(snapshot.CreatedAt) {
+						break
+					}
+					localCfgs[i] = rev.Config
+				}
+			}
+			points := team.ComputeTrends(snapshots, localCfgs)
+
+			var w io.Writer = os.Stdout
+			if opts.output != "-" {
+				f, err := os.Create(opts.output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			switch opts.format {
+			case "csv":
+				err = writeTrendsCSV(w, points)
+			case "json":
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				err = enc.Encode(points)
+			default:
+				return fmt.Errorf("unknown trends format %q", opts.format)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write trends: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.snapshotsDir, "snapshots-dir", "snapshots", "Directory of the snapshots stored with 'snapshot --history-dir'")
+	cmd.Flags().StringVar(&opts.format, "format", "csv", "Output format, one of: csv, json")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "-", "File to write the trends to, '-' for stdout")
+	cmd.Flags().StringVar(&opts.since, "since", "", "Only consider snapshots taken on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.until, "until", "", "Only consider snapshots taken on or before this date (YYYY-MM-DD)")
+
+	return cmd
+}
+
+// snapshotsBetween returns the given snapshots taken between since and until,
+// both inclusive dates in the YYYY-MM-DD format, or unbounded if empty.
+func snapshotsBetween(snapshots []*config.Snapshot, since, until string) ([]*config.Snapshot, error) {
+	var sinceDate, untilDate time.Time
+	var err error
+	if since != "" {
+		if sinceDate, err = time.Parse(config.DateFormat, since); err != nil {
+			return nil, fmt.Errorf("invalid --since date: %w", err)
+		}
+	}
+	if until != "" {
+		if untilDate, err = time.Parse(config.DateFormat, until); err != nil {
+			return nil, fmt.Errorf("invalid --until date: %w", err)
+		}
+		untilDate = untilDate.AddDate(0, 0, 1)
+	}
+
+	var between []*config.Snapshot
+	for _, snapshot := range snapshots {
+		if !sinceDate.IsZero() && snapshot.CreatedAt.Before(sinceDate) {
+			continue
+		}
+		if !untilDate.IsZero() && !snapshot.CreatedAt.Before(untilDate) {
+			continue
+		}
+		between = append(between, snapshot)
+	}
+	return between, nil
+}
+
+func writeTrendsCSV(w io.Writer, points []team.TrendPoint) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "team", "members", "added", "removed", "churn", "exclusions"}); err != nil {
+		return err
+	}
+	for _, p := range points {
+		exclusions := ""
+		if p.Exclusions >= 0 {
+			exclusions = strconv.Itoa(p.Exclusions)
+		}
+		record := []string{
+			p.Date.Format(config.DateFormat),
+			p.Team,
+			strconv.Itoa(p.Members),
+			strconv.Itoa(p.Added),
+			strconv.Itoa(p.Removed),
+			strconv.FormatFloat(p.Churn, 'f', 3, 64),
+			exclusions,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
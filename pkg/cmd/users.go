@@ -1,7 +1,7 @@
 // SPDX-License-Identifier: Apache-2.0
 // Copyright Authors of Cilium
 
-package main
+package cmd
 
 import (
 	"context"
@@ -13,50 +13,49 @@ import (
 
 	"github.com/cilium/team-manager/pkg/config"
 	"github.com/cilium/team-manager/pkg/github"
-	"github.com/cilium/team-manager/pkg/persistence"
 )
 
-var (
-	addTeams []string
-)
-
-func init() {
-	rootCmd.AddCommand(addUsersCmd)
-
-	addUsersCmd.Flags().StringSliceVar(&addTeams, "teams", []string{}, "Add the users to the specified teams in the local cache")
-}
+// NewAddUserCommand returns the add-user command.
+func NewAddUserCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		teams []string
+	}
+	cmd := &cobra.Command{
+		Use:   "add-user USER [USER ...]",
+		Short: "Add user to local configuration",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
 
-var addUsersCmd = &cobra.Command{
-	Use:   "add-user USER [USER ...]",
-	Short: "Add user to local configuration",
-	Args:  cobra.MinimumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ghClient, err := github.NewClientFromEnv()
-		if err != nil {
-			return fmt.Errorf("failed to create github client: %w", err)
-		}
+			cfg, err := deps.LoadState(configFilename)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
 
-		cfg, err := persistence.LoadState(configFilename)
-		if err != nil {
-			return fmt.Errorf("failed to load local state: %w", err)
-		}
+			if err = addUsersToConfig(cmd.Context(), args, cfg, ghClient); err != nil {
+				return fmt.Errorf("failed to add user: %w", err)
+			}
 
-		if err = addUsersToConfig(cmd.Context(), args, cfg, ghClient); err != nil {
-			return fmt.Errorf("failed to add user: %w", err)
-		}
+			for _, t := range opts.teams {
+				if err = addTeamMembers(t, args, cfg); err != nil {
+					return fmt.Errorf("failed to add team members to team %q: %w", t, err)
+				}
+			}
 
-		for _, t := range addTeams {
-			if err = addTeamMembers(t, args, cfg); err != nil {
-				return fmt.Errorf("failed to add team members to team %q: %w", t, err)
+			if err = deps.StoreState(configFilename, cfg); err != nil {
+				return fmt.Errorf("failed to store state to config: %w", err)
 			}
-		}
 
-		if err = persistence.StoreState(configFilename, cfg); err != nil {
-			return fmt.Errorf("failed to store state to config: %w", err)
-		}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.teams, "teams", []string{}, "Add the users to the specified teams in the local cache")
 
-		return nil
-	},
+	return requireOperations(cmd, github.OperationReadUsers)
 }
 
 func addUsersToConfig(ctx context.Context, addUsers []string, cfg *config.Config, ghClient *gh.Client) error {
@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/persistence"
+	"github.com/cilium/team-manager/pkg/set"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// whatIfOptions are the flags shared by the what-if subcommands.
+type whatIfOptions struct {
+	format string
+}
+
+// NewWhatIfCommand returns the what-if command.
+func NewWhatIfCommand(deps Deps) *cobra.Command {
+	opts := &whatIfOptions{}
+	cmd := &cobra.Command{
+		Use:   "what-if",
+		Short: "Simulate changes of the configuration without making them",
+	}
+
+	cmd.PersistentFlags().StringVar(&opts.format, "format", "text", "Output format, one of: text, json")
+	cmd.AddCommand(
+		newWhatIfConfigCommand(deps, opts),
+		newWhatIfRemoveCommand(deps, opts),
+	)
+
+	return cmd
+}
+
+// newWhatIfConfigCommand returns the what-if config command.
+func newWhatIfConfigCommand(deps Deps, opts *whatIfOptions) *cobra.Command {
+	var configOpts struct {
+		patch            string
+		snapshotFilename string
+	}
+	cmd := &cobra.Command{
+		Use:   "config [PROPOSED]",
+		Short: "Show the changes and policy violations of a proposed configuration, computed against a snapshot",
+		Long: `Computes the changes 'push' would submit to GitHub for a proposed
+configuration, either the given configuration file or the current
+configuration with the YAML merge patch of --patch applied, against a snapshot
+taken with 'snapshot'. The policy violations the proposed configuration
+introduces compared to the current one are listed as well.
+
+It does not require any network access or GitHub token, e.g. to review large
+reorganizations offline. A merge patch only contains the keys to change, null
+removing them and lists being replaced as a whole, for example:
+
+  teams:
+    sig-foo:
+      members: [alice, bob]
+    sig-bar: null`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (len(args) == 0) == (configOpts.patch == "") {
+				return fmt.Errorf("either a proposed configuration or --patch must be given")
+			}
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			var proposed *config.Config
+			if configOpts.patch != "" {
+				patch, err := os.ReadFile(configOpts.patch)
+				if err != nil {
+					return fmt.Errorf("failed to read patch: %w", err)
+				}
+				proposed, err = persistence.PatchState(cfg, patch)
+				if err != nil {
+					return fmt.Errorf("failed to apply patch: %w", err)
+				}
+			} else {
+				proposed, err = deps.LoadState(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to load proposed configuration: %w", err)
+				}
+			}
+			if err = config.SanityCheck(proposed); err != nil {
+				return fmt.Errorf("proposed configuration is invalid: %w", err)
+			}
+
+			snapshot, err := persistence.LoadSnapshot(configOpts.snapshotFilename)
+			if err != nil {
+				return fmt.Errorf("failed to load snapshot: %w", err)
+			}
+			upstreamCfg := snapshot.Config
+
+			violations := team.NewViolations(team.EvaluatePolicy(cfg), team.EvaluatePolicy(proposed))
+			now := time.Now()
+			effectiveCfg := team.EffectiveConfig(proposed, upstreamCfg, now)
+			plan := team.ComputePlan(effectiveCfg, upstreamCfg)
+
+			switch opts.format {
+			case "text":
+				fmt.Printf("Comparing against snapshot of %s taken at %s\n", upstreamCfg.Organization, snapshot.CreatedAt)
+				plan.PrintDiffs(os.Stdout)
+				printPlan(plan, effectiveCfg)
+				if len(violations) == 0 {
+					fmt.Println("No new policy violations")
+					return nil
+				}
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "TEAM\tMEMBER\tVIOLATION")
+				for _, v := range violations {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", orDash(v.Team), orDash(v.Login), v.Reason)
+				}
+				if err = w.Flush(); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "[WARN]: the proposed configuration would cause %d new policy violations\n", len(violations))
+				return nil
+			case "json":
+				reportUnresolvedExclusions(plan)
+				planFile, err := team.NewPlanFile(plan, upstreamCfg, now)
+				if err != nil {
+					return fmt.Errorf("failed to create plan: %w", err)
+				}
+				if violations == nil {
+					violations = []team.PolicyViolation{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				err = enc.Encode(struct {
+					SnapshotCreatedAt time.Time              `json:"snapshotCreatedAt"`
+					Plan              *team.PlanFile         `json:"plan"`
+					Violations        []team.PolicyViolation `json:"violations"`
+				}{snapshot.CreatedAt, planFile, violations})
+				if err != nil {
+					return fmt.Errorf("failed to write plan: %w", err)
+				}
+				return nil
+			default:
+				return fmt.Errorf("unknown what-if format %q", opts.format)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&configOpts.patch, "patch", "", "YAML merge patch applied to the current configuration instead of a proposed configuration")
+	cmd.Flags().StringVar(&configOpts.snapshotFilename, "snapshot-filename", "upstream-snapshot.yaml", "Snapshot filename")
+
+	return cmd
+}
+
+// newWhatIfRemoveCommand returns the what-if remove command.
+func newWhatIfRemoveCommand(deps Deps, opts *whatIfOptions) *cobra.Command {
+	var removeOpts struct {
+		all bool
+	}
+	cmd := &cobra.Command{
+		Use:   "remove USER...",
+		Short: "Show the effect on every team of removing members",
+		Long: `Shows, for every team the given members belong to, the number of members
+and of reviewers eligible for its code review assignment before and after
+removing them, and the policy violations the removal would cause: teams left
+without members or maintainers, code review assignments left without enough
+eligible reviewers and the organization left without owners. Neither the
+configuration nor GitHub are changed.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			members := make([]string, 0, len(cfg.Members))
+			for login := range cfg.Members {
+				members = append(members, login)
+			}
+			if unknown := set.DifferenceFold(args, members); len(unknown) != 0 {
+				return fmt.Errorf("users %s are not members of the configuration", strings.Join(unknown, ", "))
+			}
+
+			impact := team.SimulateRemoval(cfg, args)
+			if !removeOpts.all {
+				var affected []team.TeamImpact
+				for _, i := range impact.Teams {
+					if i.Affected() {
+						affected = append(affected, i)
+					}
+				}
+				impact.Teams = affected
+			}
+
+			switch opts.format {
+			case "text":
+				if len(impact.Teams) != 0 {
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "TEAM\tMEMBERS\tELIGIBLE REVIEWERS\tVIOLATIONS")
+					for _, i := range impact.Teams {
+						eligible := "-"
+						if cfg.Teams[i.Team].CodeReviewAssignment.Enabled {
+							eligible = fmt.Sprintf("%d -> %d", i.Eligible, i.EligibleAfter)
+						}
+						fmt.Fprintf(w, "%s\t%d -> %d\t%s\t%s\n", i.Team, i.Members, i.MembersAfter, eligible, orDash(strings.Join(i.Violations, "; ")))
+					}
+					if err = w.Flush(); err != nil {
+						return err
+					}
+				} else {
+					fmt.Println("No team affected")
+				}
+				for _, v := range impact.Violations {
+					fmt.Fprintf(os.Stderr, "[WARN]: %s\n", v)
+				}
+				if n := impact.ViolationCount(); n != 0 {
+					fmt.Fprintf(os.Stderr, "[WARN]: removing %s would cause %d policy violations\n", strings.Join(args, ", "), n)
+				}
+				return nil
+			case "json":
+				if impact.Teams == nil {
+					impact.Teams = []team.TeamImpact{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err = enc.Encode(impact); err != nil {
+					return fmt.Errorf("failed to write impact: %w", err)
+				}
+				return nil
+			default:
+				return fmt.Errorf("unknown what-if format %q", opts.format)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&removeOpts.all, "all", false, "Also show the teams the members don't belong to")
+
+	return cmd
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// pullRequestURL matches the URLs of pull requests, capturing their
+// organization, repository and number.
+var pullRequestURL = regexp.MustCompile(`^https://github\.com/([^/]+)/([^/]+)/pull/(\d+)`)
+
+// NewWhoReviewsCommand returns the who-reviews command.
+func NewWhoReviewsCommand(deps Deps) *cobra.Command {
+	var opts struct {
+		format string
+	}
+	cmd := &cobra.Command{
+		Use:   "who-reviews {REPO PATH | PR_URL}",
+		Short: "Show the reviewers that can be requested for a path or a pull request",
+		Long: `Resolves the owners of a path of the default branch of a repository, or of
+the files changed by a pull request, according to CODEOWNERS, and shows for
+every owning team its code review assignment settings and the members it picks
+reviewers from, along with the members it excludes and why, as push would
+configure them today. The author of a pull request is never requested.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				repo, branch, author string
+				number               int
+				files                []string
+			)
+			if len(args) == 1 {
+				m := pullRequestURL.FindStringSubmatch(args[0])
+				if m == nil {
+					return fmt.Errorf("%q is not the URL of a pull request", args[0])
+				}
+				if !strings.EqualFold(m[1], orgName) {
+					return fmt.Errorf("pull request %s is not in organization %s", args[0], orgName)
+				}
+				repo = m[2]
+				number, _ = strconv.Atoi(m[3])
+			} else {
+				repo = args[0]
+				files = []string{strings.TrimPrefix(args[1], "/")}
+			}
+
+			cfg, err := loadCheckedState(deps)
+			if err != nil {
+				return fmt.Errorf("failed to load local state: %w", err)
+			}
+			ghClient, err := deps.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create github client: %w", err)
+			}
+			tm := team.NewManager(ghClient, nil, orgName)
+			if number != 0 {
+				// GitHub requests the owners of the base branch.
+				if branch, author, files, err = tm.GetPullRequestFiles(cmd.Context(), repo, number); err != nil {
+					return err
+				}
+			}
+			content, err := tm.GetCodeOwners(cmd.Context(), repo, branch)
+			if err != nil {
+				return err
+			}
+			if content == "" {
+				return fmt.Errorf("no CODEOWNERS file found in repository %s", repo)
+			}
+			rules, err := team.ParseCodeOwners(content)
+			if err != nil {
+				return fmt.Errorf("failed to parse CODEOWNERS: %w", err)
+			}
+
+			owners := map[string]int{}
+			var unowned []string
+			for _, file := range files {
+				fileOwners := team.CodeOwners(rules, file)
+				if len(fileOwners) == 0 {
+					unowned = append(unowned, file)
+				}
+				for _, owner := range fileOwners {
+					owners[owner]++
+				}
+			}
+			pools := team.ReviewerPools(cfg, orgName, owners, author, time.Now())
+
+			switch opts.format {
+			case "text":
+				for _, file := range unowned {
+					fmt.Printf("%s has no owner\n", file)
+				}
+				for _, pool := range pools {
+					printReviewerPool(pool, number != 0)
+				}
+				return nil
+			case "json":
+				if unowned == nil {
+					unowned = []string{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				err = enc.Encode(struct {
+					Unowned []string            `json:"unowned"`
+					Owners  []team.ReviewerPool `json:"owners"`
+				}{unowned, pools})
+				if err != nil {
+					return fmt.Errorf("failed to write reviewers: %w", err)
+				}
+				return nil
+			default:
+				return fmt.Errorf("unknown who-reviews format %q", opts.format)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "text", "Output format, one of: text, json")
+
+	return requireOperations(cmd, github.OperationReadRepositories)
+}
+
+// printReviewerPool prints the given reviewer pool, with the number of paths
+// of its owner for pull requests.
+func printReviewerPool(pool team.ReviewerPool, pullRequest bool) {
+	title := pool.Owner
+	if pool.Team != "" {
+		title += " (team " + pool.Team + ")"
+	}
+	if pullRequest {
+		title += fmt.Sprintf(", owning %d changed files", pool.Paths)
+	}
+	fmt.Println(title)
+	if cra := pool.CodeReviewAssignment; cra != nil && cra.Enabled {
+		notify := ""
+		if cra.NotifyTeam {
+			notify = ", notifying the team"
+		}
+		percentage := ""
+		if cra.TeamMemberPercentage != 0 {
+			percentage = fmt.Sprintf(" (%d%% of the eligible members)", cra.TeamMemberPercentage)
+		}
+		fmt.Printf("  Code review assignment: %s, %d reviewers%s%s\n", orDash(string(cra.Algorithm)), cra.TeamMemberCount, percentage, notify)
+	}
+	fmt.Printf("  Candidates: %s\n", orDash(strings.Join(pool.Candidates, ", ")))
+	for _, xMember := range pool.Excluded {
+		fmt.Printf("  Excluded: %s (%s)\n", xMember.Login, xMember.Reason)
+	}
+	if pool.Problem != "" {
+		fmt.Printf("  Note: %s\n", pool.Problem)
+	}
+}
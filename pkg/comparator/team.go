@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package comparator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// TeamDiff contains the differences between the local and the remote config
+// of a team.
+type TeamDiff struct {
+	// Fields are the settings of the team that differ, e.g. its description
+	// or the algorithm of its code review assignment.
+	Fields []FieldDiff `json:"fields,omitempty"`
+	// Members are the members only in the local or in the remote config.
+	Members ListDiff `json:"members,omitempty"`
+	// Maintainers are the maintainers only in the local or in the remote
+	// config.
+	Maintainers ListDiff `json:"maintainers,omitempty"`
+}
+
+// FieldDiff is a setting of a team that differs between its local and its
+// remote config.
+type FieldDiff struct {
+	// Field is the name of the setting as in the configuration file, e.g.
+	// codeReviewAssignment.enabled.
+	Field  string      `json:"field"`
+	Local  interface{} `json:"local"`
+	Remote interface{} `json:"remote"`
+}
+
+// ListDiff contains the elements of a list that are only in its local or only
+// in its remote version.
+type ListDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Empty returns true if the local and the remote lists are the same.
+func (d ListDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// Empty returns true if the local and the remote config of the team are the
+// same.
+func (d TeamDiff) Empty() bool {
+	return len(d.Fields) == 0 && d.Members.Empty() && d.Maintainers.Empty()
+}
+
+// CompareTeams returns the differences between the local and the remote
+// config of a team. The order of members and maintainers is ignored, as well
+// as the excluded members of the code review assignment and the metadata,
+// which aren't retrieved from GitHub.
+func CompareTeams(local, remote config.TeamConfig) TeamDiff {
+	var diff TeamDiff
+	field := func(name string, l, r interface{}) {
+		if l != r {
+			diff.Fields = append(diff.Fields, FieldDiff{
+				Field:  name,
+				Local:  l,
+				Remote: r,
+			})
+		}
+	}
+	field("id", local.ID, remote.ID)
+	field("slug", local.Slug, remote.Slug)
+	field("description", local.Description, remote.Description)
+	field("privacy", local.Privacy, remote.Privacy)
+	field("notificationSetting", local.NotificationSetting, remote.NotificationSetting)
+	field("parent", local.Parent, remote.Parent)
+	localCRA, remoteCRA := local.CodeReviewAssignment, remote.CodeReviewAssignment
+	field("codeReviewAssignment.algorithm", localCRA.Algorithm, remoteCRA.Algorithm)
+	field("codeReviewAssignment.enabled", localCRA.Enabled, remoteCRA.Enabled)
+	field("codeReviewAssignment.notifyTeam", localCRA.NotifyTeam, remoteCRA.NotifyTeam)
+	field("codeReviewAssignment.teamMemberCount", localCRA.TeamMemberCount, remoteCRA.TeamMemberCount)
+
+	diff.Members = ListDiff{
+		Added:   set.DifferenceFold(local.Members, remote.Members),
+		Removed: set.DifferenceFold(remote.Members, local.Members),
+	}
+	diff.Maintainers = ListDiff{
+		Added:   set.DifferenceFold(local.Maintainers, remote.Maintainers),
+		Removed: set.DifferenceFold(remote.Maintainers, local.Maintainers),
+	}
+	return diff
+}
+
+// Print prints the differences, the remote value of every setting followed by
+// its local value.
+func (d TeamDiff) Print(w io.Writer) {
+	for _, f := range d.Fields {
+		fmt.Fprintf(w, " %s: %#v -> %#v\n", f.Field, f.Remote, f.Local)
+	}
+	d.Members.print(w, "members")
+	d.Maintainers.print(w, "maintainers")
+}
+
+func (d ListDiff) print(w io.Writer, name string) {
+	if d.Empty() {
+		return
+	}
+	var elems []string
+	for _, e := range d.Added {
+		elems = append(elems, "+"+e)
+	}
+	for _, e := range d.Removed {
+		elems = append(elems, "-"+e)
+	}
+	fmt.Fprintf(w, " %s: %s\n", name, strings.Join(elems, " "))
+}
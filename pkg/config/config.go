@@ -14,6 +14,13 @@
 
 package config
 
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
 type Config struct {
 	// Organization being managed.
 	Organization string `json:"organization,omitempty" yaml:"organization,omitempty"`
@@ -27,20 +34,280 @@ type Config struct {
 	// Teams maps the github team name to a TeamConfig.
 	Teams map[string]TeamConfig `json:"teams,omitempty" yaml:"teams,omitempty"`
 
+	// Owners are the logins of the owners of the organization, promoted
+	// and demoted by push. If empty, the owners are not managed.
+	Owners []string `json:"owners,omitempty" yaml:"owners,omitempty"`
+
+	// OutsideCollaborators maps the names of repositories to the logins of
+	// their outside collaborators and the permission they are granted,
+	// added and removed by push. The outside collaborators of repositories
+	// missing here are not managed.
+	OutsideCollaborators map[string]map[string]RepositoryPermission `json:"outsideCollaborators,omitempty" yaml:"outsideCollaborators,omitempty"`
+
+	// Settings contains the settings of the organization, updated by push.
+	Settings OrgSettings `json:"settings,omitempty" yaml:"settings,omitempty"`
+
+	// SecurityManagers are the names of the teams granted the security
+	// manager role of the organization, designated and revoked by push. If
+	// empty, the security managers are not managed.
+	SecurityManagers []string `json:"securityManagers,omitempty" yaml:"securityManagers,omitempty"`
+
+	// Labels are the sets of labels of repositories, created, updated and
+	// deleted by push. The labels of repositories missing here are not
+	// managed.
+	Labels []LabelSet `json:"labels,omitempty" yaml:"labels,omitempty"`
+
 	// Slice of github logins that should be excluded from all team reviews
 	// assignments.
 	ExcludeCRAFromAllTeams []string `json:"excludeCodeReviewAssignmentFromAllTeams" yaml:"excludeCodeReviewAssignmentFromAllTeams"`
+
+	// OnboardingRules maps the answers given during the onboarding of a new
+	// member to the teams that member should be added to.
+	OnboardingRules []OnboardingRule `json:"onboardingRules,omitempty" yaml:"onboardingRules,omitempty"`
+
+	// RepositoryTemplates contains the team permissions that are granted to
+	// newly created repositories.
+	RepositoryTemplates []RepositoryTemplate `json:"repositoryTemplates,omitempty" yaml:"repositoryTemplates,omitempty"`
+
+	// ProjectsMigration configures how the team permissions of classic
+	// projects are migrated to Projects with `migrate-projects`.
+	ProjectsMigration ProjectsMigration `json:"projectsMigration,omitempty" yaml:"projectsMigration,omitempty"`
+
+	// Policy contains optional rules enforced on this configuration.
+	Policy Policy `json:"policy,omitempty" yaml:"policy,omitempty"`
+
+	// Releases are the release cycles of the organization, exported with the
+	// members of their responsible team and their release shepherd by
+	// `releases`.
+	Releases []ReleaseCycle `json:"releases,omitempty" yaml:"releases,omitempty"`
+
+	// Retired maps the names of the teams retired with `retire-team
+	// --archive` to their last configuration.
+	Retired map[string]RetiredTeam `json:"retired,omitempty" yaml:"retired,omitempty"`
+
+	// Freeze contains the periods during which no changes are pushed to
+	// GitHub.
+	Freeze Freeze `json:"freeze,omitempty" yaml:"freeze,omitempty"`
+
+	// Drift configures the severities of the drifts between the local and
+	// the upstream configuration.
+	Drift DriftPolicy `json:"drift,omitempty" yaml:"drift,omitempty"`
+
+	// CustomFields contains additional GraphQL fields that are retrieved
+	// for teams and members and stored into their Metadata.
+	CustomFields CustomFields `json:"customFields,omitempty" yaml:"customFields,omitempty"`
+
+	// PendingRemovals are the members removed from teams in this
+	// configuration that are kept in these teams until the grace period set
+	// in Policy.GraceDays elapsed. They are tracked by team-manager.
+	PendingRemovals []PendingRemoval `json:"pendingRemovals,omitempty" yaml:"pendingRemovals,omitempty"`
+
+	// AppliedExclusions maps the names of the teams to the sorted logins of
+	// the members excluded from their code review assignment when push last
+	// updated it, as the excluded members can't be read from GitHub. It is
+	// tracked by team-manager to only update the code review assignments
+	// that changed.
+	AppliedExclusions map[string][]string `json:"appliedExclusions,omitempty" yaml:"appliedExclusions,omitempty"`
+
+	// AppliedReviewOptions maps the names of the teams to the options of
+	// their code review assignment when push last updated it, as these
+	// options can't be read from GitHub either. It is tracked by
+	// team-manager.
+	AppliedReviewOptions map[string]ReviewAssignmentOptions `json:"appliedReviewOptions,omitempty" yaml:"appliedReviewOptions,omitempty"`
+}
+
+type ReleaseCycle struct {
+	// Name is the name of the release, e.g. "v1.15".
+	Name string `json:"name" yaml:"name"`
+
+	// Team is the name of the team responsible for the release.
+	Team string `json:"team" yaml:"team"`
+
+	// Start is the first day, in the YYYY-MM-DD format, of the release
+	// cycle.
+	Start string `json:"start" yaml:"start"`
+
+	// End is the last day, in the YYYY-MM-DD format, of the release cycle,
+	// if known.
+	End string `json:"end,omitempty" yaml:"end,omitempty"`
+
+	// Shepherd is the login of the member of Team shepherding the release.
+	// If empty, the members of Team take turns, in the order of the start
+	// of the release cycles of Team.
+	Shepherd string `json:"shepherd,omitempty" yaml:"shepherd,omitempty"`
+}
+
+type Freeze struct {
+	// Windows are the freeze windows of the organization.
+	Windows []FreezeWindow `json:"windows,omitempty" yaml:"windows,omitempty"`
+
+	// CalendarURL is the URL of an iCal calendar whose events are freeze
+	// windows, in addition to Windows.
+	CalendarURL string `json:"calendarURL,omitempty" yaml:"calendarURL,omitempty"`
+}
+
+type FreezeWindow struct {
+	// Name describes the reason of the freeze, e.g. "v1.14 release".
+	Name string `json:"name" yaml:"name"`
+
+	// Start is the first day, in the YYYY-MM-DD format, of the freeze.
+	Start string `json:"start" yaml:"start"`
+
+	// End is the last day, in the YYYY-MM-DD format, of the freeze.
+	End string `json:"end" yaml:"end"`
+}
+
+type CustomFields struct {
+	// Team is a list of dot separated paths of fields of the GraphQL Team
+	// object, e.g. "updatedAt" or "parentTeam.slug".
+	Team []string `json:"team,omitempty" yaml:"team,omitempty"`
+
+	// Member is a list of dot separated paths of fields of the GraphQL User
+	// object, e.g. "email" or "company".
+	Member []string `json:"member,omitempty" yaml:"member,omitempty"`
+}
+
+// DateFormat is the format of all dates in the configuration.
+const DateFormat = "2006-01-02"
+
+type Policy struct {
+	// RequireExclusionReason should be set to true to require a reason for
+	// every member excluded from a CodeReviewAssignment.
+	RequireExclusionReason bool `json:"requireExclusionReason,omitempty" yaml:"requireExclusionReason,omitempty"`
+
+	// GraceDays is the number of days members removed from a team in this
+	// configuration are kept in that team, excluded from its
+	// CodeReviewAssignment, before being removed from it in GitHub. This
+	// gives maintainers a window to object to the removal.
+	GraceDays int `json:"graceDays,omitempty" yaml:"graceDays,omitempty"`
+
+	// MaxTeams is the maximum number of teams a member should belong to,
+	// reported by the memberships command. 0 means no limit.
+	MaxTeams int `json:"maxTeams,omitempty" yaml:"maxTeams,omitempty"`
+
+	// ExclusiveTeams are groups of mutually exclusive teams: a member should
+	// belong to at most one team of every group, which is reported by the
+	// memberships command.
+	ExclusiveTeams [][]string `json:"exclusiveTeams,omitempty" yaml:"exclusiveTeams,omitempty"`
+
+	// InviteRole is the role users that aren't members of the organization
+	// are invited with before being added to teams. If empty, GitHub invites
+	// them as direct members when they are added to a team.
+	InviteRole OrgRole `json:"inviteRole,omitempty" yaml:"inviteRole,omitempty"`
+
+	// OmitMemberNames should be set to true to not store the names of the
+	// members, only their logins and IDs.
+	OmitMemberNames bool `json:"omitMemberNames,omitempty" yaml:"omitMemberNames,omitempty"`
+
+	// RedactNames should be set to true to omit the names, email addresses
+	// and SSO identities of the members from reports, exports and
+	// snapshots, as with the --redact-names flag.
+	RedactNames bool `json:"redactNames,omitempty" yaml:"redactNames,omitempty"`
+}
+
+type PendingRemoval struct {
+	// Team is the name of the team the member is removed from.
+	Team string `json:"team" yaml:"team"`
+
+	// Login is the login of the removed member.
+	Login string `json:"login" yaml:"login"`
+
+	// Since is the date, in the YYYY-MM-DD format, the removal was first
+	// seen.
+	Since string `json:"since" yaml:"since"`
 }
 
 type TeamConfig struct {
 	// ID is the GitHub ID of this team.
 	ID string `json:"id" yaml:"id"`
 
+	// Slug is the GitHub slug of this team, retrieved from GitHub. It is
+	// used to refer to the team in REST API calls and CODEOWNERS files.
+	Slug string `json:"slug,omitempty" yaml:"slug,omitempty"`
+
+	// Description is the description of this team. It is left untouched in
+	// GitHub if empty.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Privacy can only be SECRET or VISIBLE. It is left untouched in GitHub
+	// if empty.
+	Privacy TeamPrivacy `json:"privacy,omitempty" yaml:"privacy,omitempty"`
+
+	// NotificationSetting can only be NOTIFICATIONS_ENABLED or
+	// NOTIFICATIONS_DISABLED, the latter preventing @-mentions of this team
+	// from notifying its members. It is left untouched in GitHub if empty.
+	NotificationSetting TeamNotificationSetting `json:"notificationSetting,omitempty" yaml:"notificationSetting,omitempty"`
+
+	// Parent is the name of the parent team of this team, if any.
+	Parent string `json:"parent,omitempty" yaml:"parent,omitempty"`
+
 	// Members is a list of users that belong to this team.
 	Members []string `json:"members,omitempty" yaml:"members,omitempty"`
 
+	// Maintainers is the list of members of this team with the maintainer
+	// role. Team roles are left untouched in GitHub if empty.
+	Maintainers []string `json:"maintainers,omitempty" yaml:"maintainers,omitempty"`
+
 	// CodeReviewAssignment is the code review assignment configuration of this team
 	CodeReviewAssignment CodeReviewAssignment `json:"codeReviewAssignment,omitempty" yaml:"codeReviewAssignment,omitempty"`
+
+	// Repositories maps the names of the repositories this team has access
+	// to to its permission on them, granted, updated and revoked by push.
+	// The repository access of this team is left untouched in GitHub if
+	// empty.
+	Repositories map[string]RepositoryPermission `json:"repositories,omitempty" yaml:"repositories,omitempty"`
+
+	// OwnedPaths are the CODEOWNERS patterns of the paths this team owns,
+	// e.g. "/bpf/" or "*.go", rendered by `codeowners generate`.
+	OwnedPaths []string `json:"ownedPaths,omitempty" yaml:"ownedPaths,omitempty"`
+
+	// Metadata maps the CustomFields.Team of this team to their values,
+	// retrieved from GitHub.
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+type RetiredTeam struct {
+	// RetiredAt is the date, in the YYYY-MM-DD format, the team was retired.
+	RetiredAt string `json:"retiredAt" yaml:"retiredAt"`
+
+	TeamConfig `yaml:",inline"`
+}
+
+// ExcludedMembers returns the members excluded from the CodeReviewAssignment
+// of the given team, including the ones inherited from its parent teams. The
+// exclusions of teams are expanded to their current members.
+func (c *Config) ExcludedMembers(teamName string) []ExcludedMember {
+	var excluded []ExcludedMember
+	visited := map[string]bool{}
+	for name, inherited := teamName, false; name != "" && !visited[name]; inherited = true {
+		visited[name] = true
+		team, ok := c.Teams[name]
+		if !ok {
+			break
+		}
+		for _, xMember := range team.CodeReviewAssignment.ExcludedMembers {
+			if inherited {
+				xMember.Reason = fmt.Sprintf("inherited from team %s: %s", name, xMember.Reason)
+			}
+			if xTeam, ok := xMember.Team(); ok {
+				for _, login := range c.Teams[xTeam].Members {
+					excluded = append(excluded, ExcludedMember{
+						Login:  login,
+						Reason: fmt.Sprintf("member of team %s: %s", xTeam, xMember.Reason),
+						Since:  xMember.Since,
+						Until:  xMember.Until,
+					})
+				}
+				continue
+			}
+			excluded = append(excluded, xMember)
+		}
+		if !team.CodeReviewAssignment.InheritExclusions {
+			break
+		}
+		name = team.Parent
+	}
+	return excluded
 }
 
 type User struct {
@@ -53,15 +320,162 @@ type User struct {
 	// SlackID is the Slack user ID of the person behind this GH account.
 	// The user ID can be found in the UI, under the profile of each user, under "More".
 	SlackID string `json:"slackID,omitempty" yaml:"slackID,omitempty"`
+
+	// Email is the email address of the person behind this GH account, used
+	// when exporting teams to mailing lists.
+	Email string `json:"email,omitempty" yaml:"email,omitempty"`
+
+	// Bot marks machine users and bots, whose credentials are used by
+	// automation and need to be authorized for SAML single sign-on.
+	Bot bool `json:"bot,omitempty" yaml:"bot,omitempty"`
+
+	// ReviewCapacity is the share, in percent, of the code review
+	// assignments this user should receive compared to other team members.
+	// As GitHub can't weight members, users with a capacity below 100 are
+	// excluded from the CodeReviewAssignments of their teams on some days,
+	// in rotation. Unset means full capacity.
+	ReviewCapacity int `json:"reviewCapacity,omitempty" yaml:"reviewCapacity,omitempty"`
+
+	// Metadata maps the CustomFields.Member of this user to their values,
+	// retrieved from GitHub.
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 }
 
+// ExcludedTeamPrefix prefixes the Login of the ExcludedMembers excluding all
+// the members of a team, e.g. team:emeritus.
+const ExcludedTeamPrefix = "team:"
+
 type ExcludedMember struct {
-	// Login the login of this GH user.
+	// Login the login of this GH user, or the name of a team of the
+	// configuration prefixed with ExcludedTeamPrefix to exclude all its
+	// members.
 	Login string `json:"login" yaml:"login"`
 
 	// Reason states the reason why this user is excluded from the
 	// CodeReviewAssignment.
 	Reason string `json:"reason" yaml:"reason"`
+
+	// Since is the date, in the YYYY-MM-DD format, from which this user is
+	// excluded. It is used to report exclusions that should be reviewed.
+	Since string `json:"since,omitempty" yaml:"since,omitempty"`
+
+	// Until is the last date, in the YYYY-MM-DD format, on which this user
+	// is excluded, e.g. the end of a vacation. Expired exclusions are
+	// ignored when syncing.
+	Until string `json:"until,omitempty" yaml:"until,omitempty"`
+}
+
+// Team returns the name of the team whose members are excluded, if the
+// exclusion is of a team.
+func (m ExcludedMember) Team() (string, bool) {
+	return strings.CutPrefix(m.Login, ExcludedTeamPrefix)
+}
+
+// SinceDate returns the parsed Since date, or the zero time if it is not set.
+func (m ExcludedMember) SinceDate() (time.Time, error) {
+	if m.Since == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(DateFormat, m.Since)
+}
+
+// UntilDate returns the parsed Until date, or the zero time if it is not set.
+func (m ExcludedMember) UntilDate() (time.Time, error) {
+	if m.Until == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(DateFormat, m.Until)
+}
+
+// Expired returns true if the Until date of the exclusion is before the day
+// of now.
+func (m ExcludedMember) Expired(now time.Time) bool {
+	return m.Until != "" && m.Until < now.Format(DateFormat)
+}
+
+type OnboardingRule struct {
+	// Role matches the role given during onboarding, e.g. "maintainer". An
+	// empty value matches any role.
+	Role string `json:"role,omitempty" yaml:"role,omitempty"`
+
+	// Area matches the area of work given during onboarding, e.g.
+	// "datapath". An empty value matches any area.
+	Area string `json:"area,omitempty" yaml:"area,omitempty"`
+
+	// Manager matches the github login of the manager given during
+	// onboarding. An empty value matches any manager.
+	Manager string `json:"manager,omitempty" yaml:"manager,omitempty"`
+
+	// Teams is the list of teams the new member is added to if the rule
+	// matches.
+	Teams []string `json:"teams" yaml:"teams"`
+}
+
+// Matches returns true if the given onboarding answers satisfy the rule.
+// Comparisons are case insensitive.
+func (r OnboardingRule) Matches(role, area, manager string) bool {
+	return matchesRuleField(r.Role, role) &&
+		matchesRuleField(r.Area, area) &&
+		matchesRuleField(r.Manager, manager)
+}
+
+func matchesRuleField(ruleValue, value string) bool {
+	return ruleValue == "" || strings.EqualFold(ruleValue, value)
+}
+
+type RepositoryTemplate struct {
+	// Pattern is matched against the name of newly created repositories,
+	// using the syntax of path.Match, e.g. "cilium-*".
+	Pattern string `json:"pattern" yaml:"pattern"`
+
+	// Teams maps the github team name to the permission granted to that team
+	// on the matching repositories.
+	Teams map[string]RepositoryPermission `json:"teams" yaml:"teams"`
+
+	// CodeOwners should be set to true to open a pull request adding a
+	// CODEOWNERS file, that assigns all files to the teams with write
+	// access, when a matching repository is created.
+	CodeOwners bool `json:"codeOwners,omitempty" yaml:"codeOwners,omitempty"`
+
+	// Properties maps the names of custom properties of the organization to
+	// the values set on matching repositories when they are created, e.g.
+	// the team owning them. Later templates take precedence over earlier
+	// ones setting the same property.
+	Properties map[string]string `json:"properties,omitempty" yaml:"properties,omitempty"`
+}
+
+// Matches returns true if the given repository name matches the pattern of
+// the template.
+func (t RepositoryTemplate) Matches(repo string) bool {
+	ok, _ := path.Match(t.Pattern, repo)
+	return ok
+}
+
+type ProjectsMigration struct {
+	// Projects maps the numbers of classic organization projects to the
+	// numbers of the Projects they are migrated to. The team permissions of
+	// classic projects that aren't mapped are not migrated.
+	Projects map[int]int `json:"projects,omitempty" yaml:"projects,omitempty"`
+
+	// Roles maps the permissions of classic projects, read, write or admin,
+	// to the roles of Projects. Permissions that aren't mapped are migrated
+	// to the role of the same level, e.g. write to WRITER.
+	Roles map[ClassicProjectPermission]ProjectRole `json:"roles,omitempty" yaml:"roles,omitempty"`
+}
+
+// Role returns the Projects role the given classic project permission is
+// migrated to.
+func (m ProjectsMigration) Role(perm ClassicProjectPermission) ProjectRole {
+	if role, ok := m.Roles[perm]; ok {
+		return role
+	}
+	switch perm {
+	case ClassicProjectPermissionAdmin:
+		return ProjectRoleAdmin
+	case ClassicProjectPermissionWrite:
+		return ProjectRoleWriter
+	}
+	return ProjectRoleReader
 }
 
 type CodeReviewAssignment struct {
@@ -75,12 +489,39 @@ type CodeReviewAssignment struct {
 	// review requests.
 	ExcludedMembers []ExcludedMember `json:"excludedMembers,omitempty" yaml:"excludedMembers,omitempty"`
 
+	// InheritExclusions should be set to true to also exclude the members
+	// excluded from the CodeReviewAssignment of the parent team.
+	InheritExclusions bool `json:"inheritExclusions,omitempty" yaml:"inheritExclusions,omitempty"`
+
 	// NotifyTeam will notify the entire team if assigning team members.
 	NotifyTeam bool `json:"notifyTeam,omitempty" yaml:"notifyTeam,omitempty"`
 
 	// TeamMemberCount specifies the number of team members that should be
 	// assigned to review.
 	TeamMemberCount int `json:"teamMemberCount,omitempty" yaml:"teamMemberCount,omitempty"`
+
+	// TeamMemberPercentage can be set instead of TeamMemberCount to assign
+	// the given percentage, rounded up, of the members that aren't excluded
+	// from review, computed whenever the CodeReviewAssignment is synced.
+	TeamMemberPercentage int `json:"teamMemberPercentage,omitempty" yaml:"teamMemberPercentage,omitempty"`
+
+	ReviewAssignmentOptions `yaml:",inline"`
+}
+
+// ReviewAssignmentOptions are the options of a CodeReviewAssignment that can't
+// be read from GitHub. Options that are nil are left untouched in GitHub.
+type ReviewAssignmentOptions struct {
+	// CountMembersAlreadyRequested should be set to true to count the
+	// members already requested to review towards TeamMemberCount.
+	CountMembersAlreadyRequested *bool `json:"countMembersAlreadyRequested,omitempty" yaml:"countMembersAlreadyRequested,omitempty"`
+
+	// IncludeChildTeamMembers should be set to true to also assign the
+	// members of the child teams.
+	IncludeChildTeamMembers *bool `json:"includeChildTeamMembers,omitempty" yaml:"includeChildTeamMembers,omitempty"`
+
+	// RemoveTeamRequest should be set to true to remove the review request
+	// of the team once members were assigned.
+	RemoveTeamRequest *bool `json:"removeTeamRequest,omitempty" yaml:"removeTeamRequest,omitempty"`
 }
 
 type TeamReviewAssignmentAlgorithm string
@@ -89,3 +530,135 @@ const (
 	TeamReviewAssignmentAlgorithmLoadBalance TeamReviewAssignmentAlgorithm = "LOAD_BALANCE"
 	TeamReviewAssignmentAlgorithmRoundRobin  TeamReviewAssignmentAlgorithm = "ROUND_ROBIN"
 )
+
+type TeamPrivacy string
+
+const (
+	TeamPrivacySecret  TeamPrivacy = "SECRET"
+	TeamPrivacyVisible TeamPrivacy = "VISIBLE"
+)
+
+type TeamNotificationSetting string
+
+const (
+	TeamNotificationsEnabled  TeamNotificationSetting = "NOTIFICATIONS_ENABLED"
+	TeamNotificationsDisabled TeamNotificationSetting = "NOTIFICATIONS_DISABLED"
+)
+
+type OrgSettings struct {
+	// DefaultRepositoryPermission is the base permission of the members of
+	// the organization on all its repositories. It is left untouched in
+	// GitHub if empty.
+	DefaultRepositoryPermission BasePermission `json:"defaultRepositoryPermission,omitempty" yaml:"defaultRepositoryPermission,omitempty"`
+}
+
+type LabelSet struct {
+	// Repositories are the names of the repositories the labels are
+	// applied to.
+	Repositories []string `json:"repositories" yaml:"repositories"`
+
+	// Labels are the labels of the repositories. A repository of several
+	// sets has the labels of all of them.
+	Labels []Label `json:"labels" yaml:"labels"`
+
+	// Prune deletes the labels of the repositories that are not part of
+	// any of their sets. Otherwise these labels are left untouched.
+	Prune bool `json:"prune,omitempty" yaml:"prune,omitempty"`
+}
+
+type Label struct {
+	// Name is the name of the label, matched case-insensitively.
+	Name string `json:"name" yaml:"name"`
+
+	// Color is the hexadecimal RGB color of the label, without the leading
+	// '#', e.g. "d73a4a".
+	Color string `json:"color" yaml:"color"`
+
+	// Description is the short description of the label.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// RepositoryLabels maps the names of the repositories of the label sets of c
+// to their labels, keyed by lower case name, and returns the repositories
+// whose other labels are pruned.
+func (c *Config) RepositoryLabels() (map[string]map[string]Label, map[string]bool) {
+	labels := map[string]map[string]Label{}
+	prune := map[string]bool{}
+	for _, labelSet := range c.Labels {
+		for _, repo := range labelSet.Repositories {
+			if labels[repo] == nil {
+				labels[repo] = map[string]Label{}
+			}
+			for _, label := range labelSet.Labels {
+				labels[repo][strings.ToLower(label.Name)] = label
+			}
+			prune[repo] = prune[repo] || labelSet.Prune
+		}
+	}
+	return labels, prune
+}
+
+type BasePermission string
+
+const (
+	BasePermissionNone  BasePermission = "none"
+	BasePermissionRead  BasePermission = "read"
+	BasePermissionWrite BasePermission = "write"
+	BasePermissionAdmin BasePermission = "admin"
+)
+
+type OrgRole string
+
+const (
+	OrgRoleDirectMember   OrgRole = "direct_member"
+	OrgRoleAdmin          OrgRole = "admin"
+	OrgRoleBillingManager OrgRole = "billing_manager"
+)
+
+type ClassicProjectPermission string
+
+const (
+	ClassicProjectPermissionRead  ClassicProjectPermission = "read"
+	ClassicProjectPermissionWrite ClassicProjectPermission = "write"
+	ClassicProjectPermissionAdmin ClassicProjectPermission = "admin"
+)
+
+type ProjectRole string
+
+const (
+	// ProjectRoleNone doesn't grant any access, permissions mapped to it
+	// are not migrated.
+	ProjectRoleNone   ProjectRole = "NONE"
+	ProjectRoleReader ProjectRole = "READER"
+	ProjectRoleWriter ProjectRole = "WRITER"
+	ProjectRoleAdmin  ProjectRole = "ADMIN"
+)
+
+type RepositoryPermission string
+
+const (
+	RepositoryPermissionPull     RepositoryPermission = "pull"
+	RepositoryPermissionTriage   RepositoryPermission = "triage"
+	RepositoryPermissionPush     RepositoryPermission = "push"
+	RepositoryPermissionMaintain RepositoryPermission = "maintain"
+	RepositoryPermissionAdmin    RepositoryPermission = "admin"
+)
+
+// CanWrite returns true if p grants at least write access to a repository.
+func (p RepositoryPermission) CanWrite() bool {
+	switch p {
+	case RepositoryPermissionPush, RepositoryPermissionMaintain, RepositoryPermissionAdmin:
+		return true
+	}
+	return false
+}
+
+// IsValid returns true if p is one of the permissions supported by GitHub.
+func (p RepositoryPermission) IsValid() bool {
+	switch p {
+	case RepositoryPermissionPull, RepositoryPermissionTriage, RepositoryPermissionPush,
+		RepositoryPermissionMaintain, RepositoryPermissionAdmin:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package config
+
+// Severity is the severity of a drift between the local and the upstream
+// configuration.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+var severityLevels = map[Severity]int{
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityCritical: 3,
+}
+
+// IsValid returns whether s is a known severity.
+func (s Severity) IsValid() bool {
+	_, ok := severityLevels[s]
+	return ok
+}
+
+// AtLeast returns whether s is as severe as or more severe than other.
+func (s Severity) AtLeast(other Severity) bool {
+	return severityLevels[s] >= severityLevels[other]
+}
+
+// DriftKind is a kind of drift between the local and the upstream
+// configuration.
+type DriftKind string
+
+const (
+	// DriftMemberMissing is a member of a team in the local configuration
+	// that is not a member of the team upstream.
+	DriftMemberMissing DriftKind = "member-missing"
+	// DriftExtraMember is a member of a team upstream that is not a member
+	// of the team in the local configuration.
+	DriftExtraMember DriftKind = "extra-member"
+	// DriftReviewAssignment is a code review assignment that differs
+	// between the local configuration and upstream.
+	DriftReviewAssignment DriftKind = "review-assignment-mismatch"
+	// DriftUnmanagedTeam is a team that exists upstream but not in the local
+	// configuration.
+	DriftUnmanagedTeam DriftKind = "unmanaged-team"
+	// DriftTeamMissing is a team of the local configuration that does not
+	// exist upstream.
+	DriftTeamMissing DriftKind = "team-missing"
+)
+
+// DefaultDriftSeverities are the severities of the drift kinds that are not
+// set in DriftPolicy.Severities.
+var DefaultDriftSeverities = map[DriftKind]Severity{
+	DriftMemberMissing:    SeverityWarning,
+	DriftExtraMember:      SeverityCritical,
+	DriftReviewAssignment: SeverityInfo,
+	DriftUnmanagedTeam:    SeverityWarning,
+	DriftTeamMissing:      SeverityWarning,
+}
+
+type DriftPolicy struct {
+	// Severities overrides the DefaultDriftSeverities of drift kinds.
+	Severities map[DriftKind]Severity `json:"severities,omitempty" yaml:"severities,omitempty"`
+
+	// FailOn is the minimum severity of drift for which `check` fails and
+	// `serve` alerts. Defaults to warning.
+	FailOn Severity `json:"failOn,omitempty" yaml:"failOn,omitempty"`
+}
+
+// Severity returns the severity of the given drift kind.
+func (p DriftPolicy) Severity(kind DriftKind) Severity {
+	if s, ok := p.Severities[kind]; ok {
+		return s
+	}
+	return DefaultDriftSeverities[kind]
+}
+
+// Threshold returns the minimum severity of drift that fails checks.
+func (p DriftPolicy) Threshold() Severity {
+	if p.FailOn == "" {
+		return SeverityWarning
+	}
+	return p.FailOn
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package config
+
+import (
+	"strings"
+	"unicode"
+)
+
+// compositions maps combining marks to the Latin letters they compose with
+// and to the resulting precomposed letters, at the same positions.
+var compositions = func() map[[2]rune]rune {
+	m := map[[2]rune]rune{}
+	for mark, letters := range map[rune][2]string{
+		// Grave accent
+		'\u0300': {"AEIOUaeiouÜüNnĒēŌōWwÂâĂăÊêÔôƠơƯưYy", "ÀÈÌÒÙàèìòùǛǜǸǹḔḕṐṑẀẁẦầẰằỀềỒồỜờỪừỲỳ"},
+		// Acute accent
+		'\u0301': {"AEIOUYaeiouyCcLlNnRrSsZzÜüGgÅåÆæØøÇçĒēÏïKkMmÕõŌōPpŨũWwÂâĂăÊêÔôƠơƯư", "ÁÉÍÓÚÝáéíóúýĆćĹĺŃńŔŕŚśŹźǗǘǴǵǺǻǼǽǾǿḈḉḖḗḮḯḰḱḾḿṌṍṒṓṔṕṸṹẂẃẤấẮắẾếỐốỚớỨứ"},
+		// Circumflex accent
+		'\u0302': {"AEIOUaeiouCcGgHhJjSsWwYyZzẠạẸẹỌọ", "ÂÊÎÔÛâêîôûĈĉĜĝĤĥĴĵŜŝŴŵŶŷẐẑẬậỆệỘộ"},
+		// Tilde
+		'\u0303': {"ANOanoIiUuVvÂâĂăEeÊêÔôƠơƯưYy", "ÃÑÕãñõĨĩŨũṼṽẪẫẴẵẼẽỄễỖỗỠỡỮữỸỹ"},
+		// Macron
+		'\u0304': {"AaEeIiOoUuÜüÄäȦȧÆæǪǫÖöÕõȮȯYyGgḶḷṚṛ", "ĀāĒēĪīŌōŪūǕǖǞǟǠǡǢǣǬǭȪȫȬȭȰȱȲȳḠḡḸḹṜṝ"},
+		// Breve
+		'\u0306': {"AaEeGgIiOoUuȨȩẠạ", "ĂăĔĕĞğĬĭŎŏŬŭḜḝẶặ"},
+		// Dot above
+		'\u0307': {"CcEeGgIZzAaOoBbDdFfHhMmNnPpRrSsŚśŠšṢṣTtWwXxYyſ", "ĊċĖėĠġİŻżȦȧȮȯḂḃḊḋḞḟḢḣṀṁṄṅṖṗṘṙṠṡṤṥṦṧṨṩṪṫẆẇẊẋẎẏẛ"},
+		// Diaeresis
+		'\u0308': {"AEIOUaeiouyYHhÕõŪūWwXxt", "ÄËÏÖÜäëïöüÿŸḦḧṎṏṺṻẄẅẌẍẗ"},
+		// Hook above
+		'\u0309': {"AaÂâĂăEeÊêIiOoÔôƠơUuƯưYy", "ẢảẨẩẲẳẺẻỂểỈỉỎỏỔổỞởỦủỬửỶỷ"},
+		// Ring above
+		'\u030a': {"AaUuwy", "ÅåŮůẘẙ"},
+		// Double acute accent
+		'\u030b': {"OoUu", "ŐőŰű"},
+		// Caron
+		'\u030c': {"CcDdEeLlNnRrSsTtZzAaIiOoUuÜüGgKkƷʒjHh", "ČčĎďĚěĽľŇňŘřŠšŤťŽžǍǎǏǐǑǒǓǔǙǚǦǧǨǩǮǯǰȞȟ"},
+		// Double grave accent
+		'\u030f': {"AaEeIiOoRrUu", "ȀȁȄȅȈȉȌȍȐȑȔȕ"},
+		// Inverted breve
+		'\u0311': {"AaEeIiOoRrUu", "ȂȃȆȇȊȋȎȏȒȓȖȗ"},
+		// Horn
+		'\u031b': {"OoUu", "ƠơƯư"},
+		// Dot below
+		'\u0323': {"BbDdHhKkLlMmNnRrSsTtVvWwZzAaEeIiOoƠơUuƯưYy", "ḄḅḌḍḤḥḲḳḶḷṂṃṆṇṚṛṢṣṬṭṾṿẈẉẒẓẠạẸẹỊịỌọỢợỤụỰựỴỵ"},
+		// Diaeresis below
+		'\u0324': {"Uu", "Ṳṳ"},
+		// Ring below
+		'\u0325': {"Aa", "Ḁḁ"},
+		// Comma below
+		'\u0326': {"SsTt", "ȘșȚț"},
+		// Cedilla
+		'\u0327': {"CcGgKkLlNnRrSsTtEeDdHh", "ÇçĢģĶķĻļŅņŖŗŞşŢţȨȩḐḑḨḩ"},
+		// Ogonek
+		'\u0328': {"AaEeIiUuOo", "ĄąĘęĮįŲųǪǫ"},
+		// Circumflex accent below
+		'\u032d': {"DdEeLlNnTtUu", "ḒḓḘḙḼḽṊṋṰṱṶṷ"},
+		// Breve below
+		'\u032e': {"Hh", "Ḫḫ"},
+		// Tilde below
+		'\u0330': {"EeIiUu", "ḚḛḬḭṴṵ"},
+		// Macron below
+		'\u0331': {"BbDdKkLlNnRrTtZzh", "ḆḇḎḏḴḵḺḻṈṉṞṟṮṯẔẕẖ"},
+	} {
+		composed := []rune(letters[1])
+		for i, base := range []rune(letters[0]) {
+			m[[2]rune{base, mark}] = composed[i]
+		}
+	}
+	return m
+}()
+
+// NormalizeName returns the given display name normalized so that it is
+// stored the same way whatever the way it was entered: Latin letters
+// decomposed into a base letter and combining marks, as entered e.g. on
+// macOS, are composed into a single letter, control and invisible
+// formatting characters are removed, and whitespace is collapsed into single
+// spaces and trimmed.
+func NormalizeName(name string) string {
+	var normalized []rune
+	space := false
+	for _, r := range strings.ToValidUTF8(name, "") {
+		switch {
+		case unicode.IsSpace(r):
+			space = len(normalized) != 0
+			continue
+		case r == '\u200c', r == '\u200d':
+			// Zero width (non-)joiners are part of emoji sequences and of
+			// the spelling of some languages.
+		case unicode.In(r, unicode.Cc, unicode.Cf), r == '\ufffe', r == '\uffff':
+			continue
+		}
+		if space {
+			normalized = append(normalized, ' ')
+			space = false
+		} else if n := len(normalized); n != 0 {
+			if composed, ok := compositions[[2]rune{normalized[n-1], r}]; ok {
+				normalized[n-1] = composed
+				continue
+			}
+		}
+		normalized = append(normalized, r)
+	}
+	return string(normalized)
+}
+
+// NormalizeMemberNames normalizes the names of the members of cfg with
+// NormalizeName, or removes them if the policy of cfg omits member names.
+func NormalizeMemberNames(cfg *Config) {
+	for login, user := range cfg.Members {
+		name := NormalizeName(user.Name)
+		if cfg.Policy.OmitMemberNames {
+			name = ""
+		}
+		if name != user.Name {
+			user.Name = name
+			cfg.Members[login] = user
+		}
+	}
+}
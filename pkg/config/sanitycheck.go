@@ -14,22 +14,191 @@
 
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// fieldPathRegex matches a dot separated path of GraphQL field names.
+var fieldPathRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
 
 // SanityCheck checks if the all team members belong to the organization.
 func SanityCheck(cfg *Config) error {
 	// Check if all users in the CodeReviewAssignment belong to the list of
 	// members
 	for teamName, team := range cfg.Teams {
+		teamMembers := make(map[string]struct{}, len(team.Members))
 		for _, member := range team.Members {
 			if _, ok := cfg.Members[member]; !ok {
 				return fmt.Errorf("member %q from team %q does not belong to organization", member, teamName)
 			}
+			teamMembers[member] = struct{}{}
+		}
+		for _, maintainer := range team.Maintainers {
+			if _, ok := teamMembers[maintainer]; !ok {
+				return fmt.Errorf("maintainer %q of team %q is not a member of the team", maintainer, teamName)
+			}
 		}
 		for _, xMember := range team.CodeReviewAssignment.ExcludedMembers {
-			if _, ok := cfg.Members[xMember.Login]; !ok {
+			if xTeam, ok := xMember.Team(); ok {
+				if _, ok := cfg.Teams[xTeam]; !ok {
+					return fmt.Errorf("unknown team %q excluded from code review assignment of team %q", xTeam, teamName)
+				}
+			} else if _, ok := cfg.Members[xMember.Login]; !ok {
 				return fmt.Errorf("member %q from code review assignment of team %q does not belong to organization", xMember.Login, teamName)
 			}
+			if cfg.Policy.RequireExclusionReason && strings.TrimSpace(xMember.Reason) == "" {
+				return fmt.Errorf("member %q excluded from code review assignment of team %q without a reason", xMember.Login, teamName)
+			}
+			if _, err := xMember.SinceDate(); err != nil {
+				return fmt.Errorf("invalid date for member %q excluded from code review assignment of team %q: %w", xMember.Login, teamName, err)
+			}
+			if _, err := xMember.UntilDate(); err != nil {
+				return fmt.Errorf("invalid expiry date for member %q excluded from code review assignment of team %q: %w", xMember.Login, teamName, err)
+			}
+			if xMember.Since != "" && xMember.Until != "" && xMember.Until < xMember.Since {
+				return fmt.Errorf("member %q excluded from code review assignment of team %q until %s, before %s", xMember.Login, teamName, xMember.Until, xMember.Since)
+			}
+		}
+		for repo, perm := range team.Repositories {
+			if !perm.IsValid() {
+				return fmt.Errorf("invalid permission %q of team %q for repository %q", perm, teamName, repo)
+			}
+		}
+		for _, path := range team.OwnedPaths {
+			if path == "" || strings.ContainsAny(path, " \t#") {
+				return fmt.Errorf("invalid owned path %q of team %q, must be a CODEOWNERS pattern without spaces", path, teamName)
+			}
+		}
+	}
+	for _, owner := range cfg.Owners {
+		if _, ok := cfg.Members[owner]; !ok {
+			return fmt.Errorf("owner %q does not belong to organization", owner)
+		}
+	}
+	for login, user := range cfg.Members {
+		if user.ReviewCapacity < 0 || user.ReviewCapacity > 100 {
+			return fmt.Errorf("invalid review capacity %d of member %q, must be between 1 and 100", user.ReviewCapacity, login)
+		}
+	}
+	if cfg.Policy.GraceDays < 0 {
+		return fmt.Errorf("invalid grace days %d, must not be negative", cfg.Policy.GraceDays)
+	}
+	if cfg.Policy.MaxTeams < 0 {
+		return fmt.Errorf("invalid max teams %d, must not be negative", cfg.Policy.MaxTeams)
+	}
+	for _, group := range cfg.Policy.ExclusiveTeams {
+		for _, teamName := range group {
+			if _, ok := cfg.Teams[teamName]; !ok {
+				return fmt.Errorf("unknown team %q in exclusive teams %q", teamName, group)
+			}
+		}
+	}
+	for _, teamName := range cfg.SecurityManagers {
+		if _, ok := cfg.Teams[teamName]; !ok {
+			return fmt.Errorf("unknown team %q in security managers", teamName)
+		}
+	}
+	if err := checkLabels(cfg); err != nil {
+		return err
+	}
+	if err := checkReleases(cfg); err != nil {
+		return err
+	}
+	switch cfg.Settings.DefaultRepositoryPermission {
+	case "", BasePermissionNone, BasePermissionRead, BasePermissionWrite, BasePermissionAdmin:
+	default:
+		return fmt.Errorf("invalid default repository permission %q, must be %s, %s, %s or %s", cfg.Settings.DefaultRepositoryPermission,
+			BasePermissionNone, BasePermissionRead, BasePermissionWrite, BasePermissionAdmin)
+	}
+	switch cfg.Policy.InviteRole {
+	case "", OrgRoleDirectMember, OrgRoleAdmin, OrgRoleBillingManager:
+	default:
+		return fmt.Errorf("invalid invite role %q, must be %s, %s or %s", cfg.Policy.InviteRole, OrgRoleDirectMember, OrgRoleAdmin, OrgRoleBillingManager)
+	}
+	for classic, project := range cfg.ProjectsMigration.Projects {
+		if classic <= 0 || project <= 0 {
+			return fmt.Errorf("invalid migration of classic project %d to project %d, project numbers must be positive", classic, project)
+		}
+	}
+	for perm, role := range cfg.ProjectsMigration.Roles {
+		switch perm {
+		case ClassicProjectPermissionRead, ClassicProjectPermissionWrite, ClassicProjectPermissionAdmin:
+		default:
+			return fmt.Errorf("invalid classic project permission %q, must be %s, %s or %s", perm, ClassicProjectPermissionRead, ClassicProjectPermissionWrite, ClassicProjectPermissionAdmin)
+		}
+		switch role {
+		case ProjectRoleNone, ProjectRoleReader, ProjectRoleWriter, ProjectRoleAdmin:
+		default:
+			return fmt.Errorf("invalid project role %q of classic project permission %q, must be %s, %s, %s or %s", role, perm, ProjectRoleNone, ProjectRoleReader, ProjectRoleWriter, ProjectRoleAdmin)
+		}
+	}
+	for _, r := range cfg.PendingRemovals {
+		if _, err := time.Parse(DateFormat, r.Since); err != nil {
+			return fmt.Errorf("invalid date of pending removal of member %q from team %q: %w", r.Login, r.Team, err)
+		}
+	}
+	for teamName, team := range cfg.Teams {
+		switch team.Privacy {
+		case "", TeamPrivacySecret, TeamPrivacyVisible:
+		default:
+			return fmt.Errorf("invalid privacy %q of team %q, must be %s or %s", team.Privacy, teamName, TeamPrivacySecret, TeamPrivacyVisible)
+		}
+		switch team.NotificationSetting {
+		case "", TeamNotificationsEnabled, TeamNotificationsDisabled:
+		default:
+			return fmt.Errorf("invalid notification setting %q of team %q, must be %s or %s", team.NotificationSetting, teamName, TeamNotificationsEnabled, TeamNotificationsDisabled)
+		}
+	}
+	for teamName, team := range cfg.Teams {
+		cra := team.CodeReviewAssignment
+		if cra.TeamMemberPercentage < 0 || cra.TeamMemberPercentage > 100 {
+			return fmt.Errorf("invalid code review assignment member percentage %d of team %q, must be between 0 and 100", cra.TeamMemberPercentage, teamName)
+		}
+		if cra.TeamMemberPercentage != 0 && cra.TeamMemberCount != 0 {
+			return fmt.Errorf("code review assignment of team %q sets both a member count and a member percentage", teamName)
+		}
+	}
+	for teamName, team := range cfg.Teams {
+		if !team.CodeReviewAssignment.InheritExclusions {
+			continue
+		}
+		if _, ok := cfg.Teams[team.Parent]; !ok {
+			return fmt.Errorf("team %q inherits code review assignment exclusions but its parent team %q does not exist", teamName, team.Parent)
+		}
+	}
+	for _, w := range cfg.Freeze.Windows {
+		start, err := time.Parse(DateFormat, w.Start)
+		if err != nil {
+			return fmt.Errorf("invalid start of freeze window %q: %w", w.Name, err)
+		}
+		end, err := time.Parse(DateFormat, w.End)
+		if err != nil {
+			return fmt.Errorf("invalid end of freeze window %q: %w", w.Name, err)
+		}
+		if end.Before(start) {
+			return fmt.Errorf("freeze window %q ends before it starts", w.Name)
+		}
+	}
+	for kind, severity := range cfg.Drift.Severities {
+		if _, ok := DefaultDriftSeverities[kind]; !ok {
+			return fmt.Errorf("unknown drift kind %q", kind)
+		}
+		if !severity.IsValid() {
+			return fmt.Errorf("invalid severity %q of drift kind %q", severity, kind)
+		}
+	}
+	if cfg.Drift.FailOn != "" && !cfg.Drift.FailOn.IsValid() {
+		return fmt.Errorf("invalid drift severity threshold %q", cfg.Drift.FailOn)
+	}
+	for _, fields := range [][]string{cfg.CustomFields.Team, cfg.CustomFields.Member} {
+		for _, field := range fields {
+			if !fieldPathRegex.MatchString(field) {
+				return fmt.Errorf("invalid custom field %q, must be a dot separated path of GraphQL field names", field)
+			}
 		}
 	}
 	for _, xMember := range cfg.ExcludeCRAFromAllTeams {
@@ -37,5 +206,123 @@ func SanityCheck(cfg *Config) error {
 			return fmt.Errorf("member %q from globally excluded reviews, does not belong to the organization", xMember)
 		}
 	}
+	for i, rule := range cfg.OnboardingRules {
+		for _, teamName := range rule.Teams {
+			if _, ok := cfg.Teams[teamName]; !ok {
+				return fmt.Errorf("team %q from onboarding rule #%d does not exist", teamName, i+1)
+			}
+		}
+	}
+	for repo, collaborators := range cfg.OutsideCollaborators {
+		for login, perm := range collaborators {
+			if !perm.IsValid() {
+				return fmt.Errorf("invalid permission %q for outside collaborator %q of repository %q", perm, login, repo)
+			}
+			if _, ok := cfg.Members[login]; ok {
+				return fmt.Errorf("outside collaborator %q of repository %q is a member of the organization", login, repo)
+			}
+		}
+	}
+	for _, tmpl := range cfg.RepositoryTemplates {
+		if _, err := path.Match(tmpl.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid repository template pattern %q: %w", tmpl.Pattern, err)
+		}
+		for teamName, perm := range tmpl.Teams {
+			if _, ok := cfg.Teams[teamName]; !ok {
+				return fmt.Errorf("team %q from repository template %q does not exist", teamName, tmpl.Pattern)
+			}
+			if !perm.IsValid() {
+				return fmt.Errorf("invalid permission %q for team %q in repository template %q", perm, teamName, tmpl.Pattern)
+			}
+		}
+		for name := range tmpl.Properties {
+			if name == "" {
+				return fmt.Errorf("custom property without name in repository template %q", tmpl.Pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// labelColorRegex matches the colors of labels.
+var labelColorRegex = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// checkLabels checks that the labels of every label set are valid and that
+// the labels of a repository are defined consistently across its sets.
+func checkLabels(cfg *Config) error {
+	defined := map[string]map[string]Label{}
+	for i, labelSet := range cfg.Labels {
+		if len(labelSet.Repositories) == 0 {
+			return fmt.Errorf("label set %d has no repositories", i+1)
+		}
+		names := map[string]bool{}
+		for _, label := range labelSet.Labels {
+			name := strings.ToLower(label.Name)
+			switch {
+			case strings.TrimSpace(label.Name) == "":
+				return fmt.Errorf("label without name in label set %d", i+1)
+			case names[name]:
+				return fmt.Errorf("duplicate label %q in label set %d", label.Name, i+1)
+			case !labelColorRegex.MatchString(label.Color):
+				return fmt.Errorf("invalid color %q of label %q, must be 6 hexadecimal digits without '#'", label.Color, label.Name)
+			case len([]rune(label.Description)) > 100:
+				return fmt.Errorf("description of label %q longer than 100 characters", label.Name)
+			}
+			names[name] = true
+		}
+		for _, repo := range labelSet.Repositories {
+			if defined[repo] == nil {
+				defined[repo] = map[string]Label{}
+			}
+			for _, label := range labelSet.Labels {
+				name := strings.ToLower(label.Name)
+				if other, ok := defined[repo][name]; ok && (!strings.EqualFold(other.Color, label.Color) || other.Description != label.Description) {
+					return fmt.Errorf("label %q of repository %q defined differently in several label sets", label.Name, repo)
+				}
+				defined[repo][name] = label
+			}
+		}
+	}
+	return nil
+}
+
+// checkReleases checks that the release cycles have unique names, valid dates
+// and that their teams and shepherds exist.
+func checkReleases(cfg *Config) error {
+	names := map[string]bool{}
+	for _, r := range cfg.Releases {
+		if r.Name == "" {
+			return fmt.Errorf("release cycle without name")
+		}
+		if names[r.Name] {
+			return fmt.Errorf("duplicate release cycle %q", r.Name)
+		}
+		names[r.Name] = true
+		teamCfg, ok := cfg.Teams[r.Team]
+		if !ok {
+			return fmt.Errorf("unknown team %q of release cycle %q", r.Team, r.Name)
+		}
+		if _, err := time.Parse(DateFormat, r.Start); err != nil {
+			return fmt.Errorf("invalid start of release cycle %q: %w", r.Name, err)
+		}
+		if r.End != "" {
+			if _, err := time.Parse(DateFormat, r.End); err != nil {
+				return fmt.Errorf("invalid end of release cycle %q: %w", r.Name, err)
+			}
+			if r.End < r.Start {
+				return fmt.Errorf("release cycle %q ends before it starts", r.Name)
+			}
+		}
+		if r.Shepherd == "" {
+			continue
+		}
+		member := false
+		for _, login := range teamCfg.Members {
+			member = member || login == r.Shepherd
+		}
+		if !member {
+			return fmt.Errorf("shepherd %q of release cycle %q is not a member of team %q", r.Shepherd, r.Name, r.Team)
+		}
+	}
 	return nil
 }
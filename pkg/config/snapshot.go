@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package config
+
+import "time"
+
+// Snapshot is the upstream configuration of an organization at a point in
+// time.
+type Snapshot struct {
+	// CreatedAt is the time the snapshot was taken.
+	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
+
+	// TeamsUpdatedAt maps the GitHub ID of a team to the last time the team
+	// was updated. It is used to only re-fetch the teams that changed since
+	// the snapshot was taken.
+	TeamsUpdatedAt map[string]time.Time `json:"teamsUpdatedAt,omitempty" yaml:"teamsUpdatedAt,omitempty"`
+
+	// Config is the configuration of the organization as seen upstream.
+	Config *Config `json:"config" yaml:"config"`
+}
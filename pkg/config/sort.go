@@ -33,6 +33,7 @@ func SortConfig(cfg *Config) {
 			team.Members = append(team.Members, teamMember)
 		}
 		sort.Strings(team.Members)
+		sort.Strings(team.Maintainers)
 
 		// sort excluded members as well
 		sort.Slice(team.CodeReviewAssignment.ExcludedMembers, func(i, j int) bool {
@@ -45,4 +46,12 @@ func SortConfig(cfg *Config) {
 	}
 	// Sort excluded team members
 	sort.Strings(cfg.ExcludeCRAFromAllTeams)
+	sort.Strings(cfg.Owners)
+
+	sort.Slice(cfg.PendingRemovals, func(i, j int) bool {
+		if cfg.PendingRemovals[i].Team != cfg.PendingRemovals[j].Team {
+			return cfg.PendingRemovals[i].Team < cfg.PendingRemovals[j].Team
+		}
+		return cfg.PendingRemovals[i].Login < cfg.PendingRemovals[j].Login
+	})
 }
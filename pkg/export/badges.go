@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/renameio"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// TeamEndpoint is the public information of a team, served as JSON.
+type TeamEndpoint struct {
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description,omitempty"`
+	Parent      string    `json:"parent,omitempty"`
+	Members     int       `json:"members"`
+	Maintainers int       `json:"maintainers"`
+	SyncedAt    time.Time `json:"syncedAt"`
+}
+
+// Badge is the JSON of a shields.io endpoint badge, see
+// https://shields.io/badges/endpoint-badge.
+type Badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// TeamEndpoints returns the endpoints of the teams of cfg, sorted by slug,
+// synced with GitHub at syncedAt. Secret teams are left out since they are
+// only visible to the members of the organization.
+func TeamEndpoints(cfg *config.Config, syncedAt time.Time) []TeamEndpoint {
+	endpoints := []TeamEndpoint{}
+	for teamName, teamCfg := range cfg.Teams {
+		if teamCfg.Privacy == config.TeamPrivacySecret {
+			continue
+		}
+		endpoint := TeamEndpoint{
+			Name:        teamName,
+			Slug:        team.TeamSlug(cfg, teamName),
+			Description: teamCfg.Description,
+			Members:     len(teamCfg.Members),
+			Maintainers: len(teamCfg.Maintainers),
+			SyncedAt:    syncedAt.UTC().Truncate(time.Second),
+		}
+		if teamCfg.Parent != "" {
+			endpoint.Parent = team.TeamSlug(cfg, teamCfg.Parent)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].Slug < endpoints[j].Slug
+	})
+	return endpoints
+}
+
+// TeamBadge returns the shields.io badge of the given team, showing its
+// number of members.
+func TeamBadge(endpoint TeamEndpoint) Badge {
+	message := fmt.Sprintf("%d members", endpoint.Members)
+	if endpoint.Members == 1 {
+		message = "1 member"
+	}
+	return Badge{SchemaVersion: 1, Label: endpoint.Name, Message: message, Color: "blue"}
+}
+
+// WriteTeamEndpoints writes the given endpoints into dir for static hosting:
+// <slug>.json for the endpoint of every team, <slug>.badge.json for its
+// shields.io badge, and index.json for the endpoints of all the teams. dir is
+// created if it doesn't exist.
+func WriteTeamEndpoints(dir string, endpoints []TeamEndpoint) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, endpoint := range endpoints {
+		if err := writeJSON(filepath.Join(dir, endpoint.Slug+".json"), endpoint); err != nil {
+			return err
+		}
+		if err := writeJSON(filepath.Join(dir, endpoint.Slug+".badge.json"), TeamBadge(endpoint)); err != nil {
+			return err
+		}
+	}
+	return writeJSON(filepath.Join(dir, "index.json"), endpoints)
+}
+
+func writeJSON(file string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return renameio.WriteFile(file, append(data, '\n'), 0o644)
+}
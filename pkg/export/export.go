@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package export translates the team membership of a config into formats
+// understood by mailing list and directory services.
+package export
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// WriteLDIF writes an inetOrgPerson entry for every member of the given teams
+// and a groupOfNames entry for every team, below the given base DN. It returns
+// the first error writing to w.
+func WriteLDIF(w io.Writer, cfg *config.Config, teams []string, baseDN string) error {
+	ew := &errWriter{w: w}
+	members := set.New[string]()
+	for _, teamName := range teams {
+		members.Add(cfg.Teams[teamName].Members...)
+	}
+
+	for _, login := range members.Elements() {
+		user := cfg.Members[login]
+		cn := user.Name
+		if cn == "" {
+			cn = login
+		}
+		writeLDIFAttr(ew, "dn", personDN(login, baseDN))
+		fmt.Fprintf(ew, "objectClass: top\nobjectClass: person\nobjectClass: inetOrgPerson\n")
+		writeLDIFAttr(ew, "uid", login)
+		writeLDIFAttr(ew, "cn", cn)
+		writeLDIFAttr(ew, "sn", login)
+		if user.Email != "" {
+			writeLDIFAttr(ew, "mail", user.Email)
+		}
+		fmt.Fprintln(ew)
+	}
+
+	for _, teamName := range teams {
+		teamCfg := cfg.Teams[teamName]
+		writeLDIFAttr(ew, "dn", fmt.Sprintf("cn=%s,ou=teams,%s", escapeRDNValue(team.Slug(teamName)), baseDN))
+		fmt.Fprintf(ew, "objectClass: top\nobjectClass: groupOfNames\n")
+		writeLDIFAttr(ew, "cn", team.Slug(teamName))
+		writeLDIFAttr(ew, "description", teamName)
+		if len(teamCfg.Members) == 0 {
+			// groupOfNames requires at least one member.
+			writeLDIFAttr(ew, "member", baseDN)
+		}
+		for _, m := range teamCfg.Members {
+			writeLDIFAttr(ew, "member", personDN(m, baseDN))
+		}
+		fmt.Fprintln(ew)
+	}
+	return ew.err
+}
+
+// errWriter writes to w until a write fails, keeping the first error.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	var n int
+	n, ew.err = ew.w.Write(p)
+	return n, ew.err
+}
+
+func personDN(login, baseDN string) string {
+	return fmt.Sprintf("uid=%s,ou=people,%s", escapeRDNValue(login), baseDN)
+}
+
+// escapeRDNValue escapes the given attribute value of a relative
+// distinguished name as defined by RFC 4514.
+func escapeRDNValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == 0:
+			b.WriteString(`\00`)
+			continue
+		case strings.IndexByte(`"+,;<>\=`, c) >= 0,
+			i == 0 && (c == ' ' || c == '#'),
+			i == len(value)-1 && c == ' ':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// writeLDIFAttr writes the given attribute, base64 encoded if it is not a
+// safe string as defined by RFC 2849.
+func writeLDIFAttr(w io.Writer, attr, value string) {
+	safe := utf8.ValidString(value)
+	for i := 0; i < len(value) && safe; i++ {
+		c := value[i]
+		if c >= 0x80 || c == 0 || c == '\n' || c == '\r' ||
+			(i == 0 && (c == ' ' || c == ':' || c == '<')) {
+			safe = false
+		}
+	}
+	if safe && (len(value) == 0 || value[len(value)-1] != ' ') {
+		fmt.Fprintf(w, "%s: %s\n", attr, value)
+		return
+	}
+	fmt.Fprintf(w, "%s:: %s\n", attr, base64.StdEncoding.EncodeToString([]byte(value)))
+}
+
+// WriteGoogleGroupsCSV writes the members of the given teams in the bulk
+// upload CSV format of Google Groups. Every team is mapped to the group
+// <team-slug>@<domain>. Members without an email address are skipped.
+func WriteGoogleGroupsCSV(w io.Writer, cfg *config.Config, teams []string, domain string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"Group Email [Required]", "Member Email", "Member Type", "Member Role"}); err != nil {
+		return err
+	}
+	for _, teamName := range teams {
+		group := fmt.Sprintf("%s@%s", team.Slug(teamName), domain)
+		for _, m := range cfg.Teams[teamName].Members {
+			email := cfg.Members[m].Email
+			if email == "" {
+				fmt.Fprintf(os.Stderr, "[WARN]: Skipping member %s of team %s without email address\n", m, teamName)
+				continue
+			}
+			if err := cw.Write([]string{group, email, "USER", "MEMBER"}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
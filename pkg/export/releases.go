@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package export
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/team"
+)
+
+// Release is a release cycle with its responsible team and shepherd, for
+// release tooling.
+type Release struct {
+	Name  string `json:"name"`
+	Start string `json:"start"`
+	End   string `json:"end,omitempty"`
+	// Active is true if the release cycle started and didn't end yet.
+	// Cycles without an end end when the next cycle of their team starts.
+	Active bool `json:"active"`
+	// Team is the slug of the responsible team.
+	Team        string   `json:"team"`
+	Members     []string `json:"members"`
+	Maintainers []string `json:"maintainers"`
+	// Shepherd is the login of the release shepherd, empty if no member of
+	// the team is available.
+	Shepherd string `json:"shepherd,omitempty"`
+	// Rotated is true if the shepherd was selected by rotation rather than
+	// set in the configuration.
+	Rotated bool `json:"rotated"`
+}
+
+// Releases returns the release cycles of cfg at now, sorted by start date,
+// with the current members of their responsible teams and their shepherds,
+// see team.ReleaseShepherds.
+func Releases(cfg *config.Config, now time.Time) []Release {
+	today := now.Format(config.DateFormat)
+	shepherds := team.ReleaseShepherds(cfg)
+	releases := make([]Release, 0, len(cfg.Releases))
+	for _, r := range cfg.Releases {
+		teamCfg := cfg.Teams[r.Team]
+		releases = append(releases, Release{
+			Name:        r.Name,
+			Start:       r.Start,
+			End:         r.End,
+			Active:      r.Start <= today && today <= releaseEnd(cfg, r),
+			Team:        team.TeamSlug(cfg, r.Team),
+			Members:     sortedCopy(teamCfg.Members),
+			Maintainers: sortedCopy(teamCfg.Maintainers),
+			Shepherd:    shepherds[r.Name],
+			Rotated:     r.Shepherd == "",
+		})
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Start < releases[j].Start
+	})
+	return releases
+}
+
+// releaseEnd returns the last day of the given release cycle of cfg, the day
+// before the start of the next cycle of its team if it has no end, or the
+// last day representable in the YYYY-MM-DD format if there is no such cycle
+// either.
+func releaseEnd(cfg *config.Config, r config.ReleaseCycle) string {
+	if r.End != "" {
+		return r.End
+	}
+	end := "9999-12-31"
+	for _, next := range cfg.Releases {
+		if next.Team == r.Team && next.Start > r.Start && next.Start <= end {
+			start, _ := time.Parse(config.DateFormat, next.Start)
+			end = start.AddDate(0, 0, -1).Format(config.DateFormat)
+		}
+	}
+	return end
+}
+
+// sortedCopy returns a sorted copy of the given logins, never nil.
+func sortedCopy(logins []string) []string {
+	sorted := append([]string{}, logins...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package freeze determines whether changes to the organization are frozen,
+// e.g. during release weeks.
+package freeze
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// Window is a period of time during which changes are frozen.
+type Window struct {
+	Name  string
+	Start time.Time
+	// End is exclusive.
+	End time.Time
+}
+
+func (w Window) String() string {
+	return fmt.Sprintf("%q from %s until %s", w.Name, w.Start.Format(time.RFC3339), w.End.Format(time.RFC3339))
+}
+
+// Contains returns whether t is within w.
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// Active returns the freeze window of cfg that contains t, or nil if changes
+// are not frozen at t. The windows of the iCal calendar of cfg, if any, are
+// downloaded.
+func Active(ctx context.Context, cfg config.Freeze, t time.Time) (*Window, error) {
+	windows, err := configWindows(cfg.Windows)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CalendarURL != "" {
+		calWindows, err := fetchCalendar(ctx, cfg.CalendarURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read freeze calendar: %w", err)
+		}
+		windows = append(windows, calWindows...)
+	}
+	for _, w := range windows {
+		if w.Contains(t) {
+			return &w, nil
+		}
+	}
+	return nil, nil
+}
+
+// configWindows converts the windows of the configuration, whose end dates
+// are inclusive, into Windows.
+func configWindows(cfgWindows []config.FreezeWindow) ([]Window, error) {
+	windows := make([]Window, 0, len(cfgWindows))
+	for _, cw := range cfgWindows {
+		start, err := time.Parse(config.DateFormat, cw.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of freeze window %q: %w", cw.Name, err)
+		}
+		end, err := time.Parse(config.DateFormat, cw.End)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end of freeze window %q: %w", cw.Name, err)
+		}
+		windows = append(windows, Window{
+			Name:  cw.Name,
+			Start: start,
+			End:   end.AddDate(0, 0, 1),
+		})
+	}
+	return windows, nil
+}
+
+func fetchCalendar(ctx context.Context, url string) ([]Window, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return ParseICal(resp.Body)
+}
+
+// ParseICal returns the events of the given iCalendar (RFC 5545) as windows.
+// Only the SUMMARY, DTSTART and DTEND properties of events are considered;
+// recurring events are not expanded.
+func ParseICal(r io.Reader) ([]Window, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		windows []Window
+		current *Window
+	)
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				current = &Window{}
+			}
+		case "END":
+			if strings.EqualFold(value, "VEVENT") && current != nil {
+				if current.Start.IsZero() {
+					return nil, fmt.Errorf("event %q without start", current.Name)
+				}
+				if current.End.IsZero() {
+					// Events without an end last one day.
+					current.End = current.Start.AddDate(0, 0, 1)
+				}
+				windows = append(windows, *current)
+				current = nil
+			}
+		case "SUMMARY":
+			if current != nil {
+				current.Name = value
+			}
+		case "DTSTART", "DTEND":
+			if current == nil {
+				continue
+			}
+			t, err := parseICalTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", name, value, err)
+			}
+			if strings.EqualFold(name, "DTSTART") {
+				current.Start = t
+			} else {
+				current.End = t
+			}
+		}
+	}
+	return windows, nil
+}
+
+// unfoldLines returns the lines of r, joining the continuation lines that
+// start with a space or a tab.
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) != 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICalTime parses a DATE or DATE-TIME value. Times without a time zone
+// are interpreted in the time zone of the TZID parameter, if any, or in UTC.
+func parseICalTime(value, params string) (time.Time, error) {
+	switch {
+	case len(value) == len("20060102"):
+		return time.Parse("20060102", value)
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse("20060102T150405Z", value)
+	}
+	loc := time.UTC
+	for _, param := range strings.Split(params, ";") {
+		if k, v, _ := strings.Cut(param, "="); strings.EqualFold(k, "TZID") {
+			var err error
+			if loc, err = time.LoadLocation(v); err != nil {
+				return time.Time{}, err
+			}
+		}
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
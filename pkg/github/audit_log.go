@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	gh "github.com/google/go-github/v33/github"
+)
+
+// AuditLogEntry is an entry of the organization audit log. Only the fields
+// that are relevant for team management are decoded.
+type AuditLogEntry struct {
+	// Timestamp is the time of the event in milliseconds since the epoch.
+	Timestamp int64 `json:"@timestamp"`
+
+	// Action is the audit log action, e.g. "team.add_member".
+	Action string `json:"action"`
+
+	// Actor is the login of the user that performed the action.
+	Actor string `json:"actor"`
+
+	// Team is the team affected by the action, in the form "org/team-slug".
+	Team string `json:"team"`
+
+	// User is the login of the user affected by the action.
+	User string `json:"user"`
+}
+
+// CreatedAt returns the time of the event.
+func (e AuditLogEntry) CreatedAt() time.Time {
+	return time.UnixMilli(e.Timestamp)
+}
+
+var linkNextRE = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// ListAuditLog returns all entries of the organization audit log that match
+// the given search phrase. The audit log API is only available to
+// organizations on GitHub Enterprise Cloud.
+func ListAuditLog(ctx context.Context, client *gh.Client, org, phrase string) ([]AuditLogEntry, error) {
+	u := fmt.Sprintf("orgs/%s/audit-log?phrase=%s&per_page=100", org, url.QueryEscape(phrase))
+
+	var entries []AuditLogEntry
+	for u != "" {
+		req, err := client.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []AuditLogEntry
+		resp, err := client.Do(ctx, req, &page)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page...)
+
+		// The audit log uses cursor based pagination which is not
+		// supported by go-github, so follow the "next" link directly.
+		u = ""
+		if m := linkNextRE.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+			u = m[1]
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/cilium/team-manager/pkg/keyring"
+)
+
+// errNoCredentials is returned by the auth providers that have no credentials
+// to obtain a token with, e.g. because their environment variables aren't set.
+var errNoCredentials = errors.New("no credentials")
+
+// AuthProvider obtains a GitHub token from a source of credentials.
+type AuthProvider struct {
+	Name        string
+	Description string
+	// Token returns the token, or an error wrapping errNoCredentials if the
+	// source has no credentials. Tokens with an expiry are obtained again
+	// from the provider once they expire.
+	Token func(ctx context.Context) (*oauth2.Token, error)
+}
+
+// AuthProviders are the available auth providers, in the default order they
+// are tried in.
+var AuthProviders = []AuthProvider{
+	{
+		Name:        "env",
+		Description: "the GITHUB_TOKEN environment variable",
+		Token: staticToken(func(context.Context) (string, error) {
+			if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+				return token, nil
+			}
+			return "", errNoCredentials
+		}),
+	},
+	{
+		Name:        "gh-cli",
+		Description: "the token of the GitHub CLI (gh)",
+		Token: staticToken(func(context.Context) (string, error) {
+			token, err := tokenFromGHCLI()
+			if err != nil {
+				return "", fmt.Errorf("%w: %s", errNoCredentials, err)
+			}
+			return token, nil
+		}),
+	},
+	{
+		Name:        "keyring",
+		Description: "the token stored in the OS keyring with 'team-manager login'",
+		Token: staticToken(func(context.Context) (string, error) {
+			token, err := keyring.Get(KeyringUser)
+			if err != nil {
+				return "", fmt.Errorf("%w: %s", errNoCredentials, err)
+			}
+			return token, nil
+		}),
+	},
+	{
+		Name:        "app",
+		Description: "an installation token of the GitHub App of GITHUB_APP_ID",
+		Token:       tokenFromApp,
+	},
+	{
+		Name:        "device",
+		Description: "the OAuth device flow of the OAuth app of TEAM_MANAGER_OAUTH_CLIENT_ID",
+		Token:       staticToken(tokenFromDeviceFlow),
+	},
+}
+
+// staticToken returns the Token function of an auth provider obtaining tokens
+// that don't expire with the given function.
+func staticToken(token func(ctx context.Context) (string, error)) func(context.Context) (*oauth2.Token, error) {
+	return func(ctx context.Context) (*oauth2.Token, error) {
+		t, err := token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &oauth2.Token{AccessToken: t}, nil
+	}
+}
+
+// DefaultAuthProviders are the names of AuthProviders, in order.
+func DefaultAuthProviders() []string {
+	names := make([]string, 0, len(AuthProviders))
+	for _, p := range AuthProviders {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// ValidateAuthProviders returns an error if any of the given names isn't the
+// name of one of AuthProviders.
+func ValidateAuthProviders(names []string) error {
+	for _, name := range names {
+		if _, ok := authProvider(name); !ok {
+			return fmt.Errorf("unknown auth provider %q, must be one of: %s", name, strings.Join(DefaultAuthProviders(), ", "))
+		}
+	}
+	return nil
+}
+
+func authProvider(name string) (AuthProvider, bool) {
+	for _, p := range AuthProviders {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return AuthProvider{}, false
+}
+
+// auth caches the token source obtained by tokenSourceFromEnv, so that the
+// REST and GraphQL clients share it and interactive providers only run once.
+var auth struct {
+	mu     sync.Mutex
+	source oauth2.TokenSource
+}
+
+// providerTokenSource obtains the tokens of an auth provider.
+type providerTokenSource struct {
+	provider AuthProvider
+}
+
+func (s providerTokenSource) Token() (*oauth2.Token, error) {
+	return s.provider.Token(context.Background())
+}
+
+// tokenSourceFromEnv returns the token source of the first of the auth
+// providers of httpOptions, by default AuthProviders, having credentials,
+// obtaining a new token from the provider once the current one expires. The
+// provider used is logged to stderr.
+func tokenSourceFromEnv() (oauth2.TokenSource, error) {
+	if httpOptions.ReplayCassette != "" {
+		return staticTokenSource(os.Getenv("GITHUB_TOKEN")), nil
+	}
+	if httpOptions.Offline {
+		return nil, ErrOffline
+	}
+
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+	if auth.source != nil {
+		return auth.source, nil
+	}
+	names := httpOptions.AuthProviders
+	if len(names) == 0 {
+		names = DefaultAuthProviders()
+	}
+	var failures []string
+	for _, name := range names {
+		p, ok := authProvider(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown auth provider %q", name)
+		}
+		token, err := p.Token(context.Background())
+		if errors.Is(err, errNoCredentials) {
+			continue
+		} else if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", name, err))
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Authenticated to GitHub with %s\n", p.Description)
+		auth.source = oauth2.ReuseTokenSource(token, providerTokenSource{p})
+		return auth.source, nil
+	}
+	if len(failures) != 0 {
+		return nil, fmt.Errorf("%w (%s)", errGithubToken, strings.Join(failures, "; "))
+	}
+	return nil, errGithubToken
+}
+
+var appTokenURL = "https://api.github.com/app/installations/%s/access_tokens"
+
+// tokenFromApp returns an installation token of the GitHub App of
+// GITHUB_APP_ID, for its installation GITHUB_APP_INSTALLATION_ID, authenticated
+// with the private key GITHUB_APP_PRIVATE_KEY or read from
+// GITHUB_APP_PRIVATE_KEY_FILE. Installation tokens expire after an hour, the
+// token carries its expiry so that a new one is created once it expired.
+func tokenFromApp(ctx context.Context) (*oauth2.Token, error) {
+	appID, installationID := os.Getenv("GITHUB_APP_ID"), os.Getenv("GITHUB_APP_INSTALLATION_ID")
+	if appID == "" || installationID == "" {
+		return nil, errNoCredentials
+	}
+	key := []byte(os.Getenv("GITHUB_APP_PRIVATE_KEY"))
+	if file := os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE"); len(key) == 0 && file != "" {
+		var err error
+		if key, err = os.ReadFile(file); err != nil {
+			return nil, fmt.Errorf("failed to read private key of GitHub App: %w", err)
+		}
+	}
+	if len(key) == 0 {
+		return nil, errNoCredentials
+	}
+
+	jwt, err := appJWT(appID, key, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(appTokenURL, installationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	transport, err := newTransport()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status %s creating installation token of GitHub App %s", resp.Status, appID)
+	}
+	var out struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: out.Token, Expiry: out.ExpiresAt}, nil
+}
+
+// appJWT returns the JSON Web Token authenticating as the GitHub App with the
+// given ID and PEM private key, valid for 10 minutes.
+func appJWT(appID string, pemKey []byte, now time.Time) (string, error) {
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		return "", fmt.Errorf("no PEM private key found for GitHub App %s", appID)
+	}
+	var key *rsa.PrivateKey
+	if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		key = k
+	} else if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		var ok bool
+		if key, ok = k.(*rsa.PrivateKey); !ok {
+			return "", fmt.Errorf("private key of GitHub App %s is not an RSA key", appID)
+		}
+	} else {
+		return "", fmt.Errorf("failed to parse private key of GitHub App %s: %w", appID, err)
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		// Backdated to allow for clock drift, as recommended by GitHub.
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token of GitHub App %s: %w", appID, err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// tokenFromDeviceFlow obtains a token interactively with the OAuth device
+// flow of the OAuth app of TEAM_MANAGER_OAUTH_CLIENT_ID.
+func tokenFromDeviceFlow(ctx context.Context) (string, error) {
+	clientID := os.Getenv("TEAM_MANAGER_OAUTH_CLIENT_ID")
+	if clientID == "" {
+		return "", errNoCredentials
+	}
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return "", fmt.Errorf("%w: stdin is not a terminal", errNoCredentials)
+	}
+	code, err := RequestDeviceCode(ctx, clientID, []string{"admin:org", "repo"})
+	if err != nil {
+		return "", fmt.Errorf("failed to request device code: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Open %s and enter the code %s\n", code.VerificationURI, code.UserCode)
+	return WaitForDeviceToken(ctx, clientID, code)
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/google/renameio"
+)
+
+// Interaction is a recorded HTTP request and its response.
+type Interaction struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	RequestBody  string      `json:"requestBody,omitempty"`
+	StatusCode   int         `json:"statusCode"`
+	Header       http.Header `json:"header,omitempty"`
+	ResponseBody string      `json:"responseBody,omitempty"`
+}
+
+// Cassette is a list of recorded interactions with the GitHub APIs.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// recordedHeaders are the only response headers stored in cassettes, all
+// other headers are dropped so that cassettes do not contain any
+// credentials or session data. Request headers are never recorded.
+var recordedHeaders = []string{"Content-Type", "Deprecation", "Link", "Sunset"}
+
+// LoadCassette loads a cassette from the given file.
+func LoadCassette(file string) (*Cassette, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %q: %w", file, err)
+	}
+	return &c, nil
+}
+
+// recorder is a http.RoundTripper that performs requests with the next
+// transport and records them into a cassette file.
+type recorder struct {
+	next http.RoundTripper
+	file string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Values(h); len(v) != 0 {
+			header[h] = v
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Method:       req.Method,
+		URL:          req.URL.String(),
+		RequestBody:  string(reqBody),
+		StatusCode:   resp.StatusCode,
+		Header:       header,
+		ResponseBody: string(respBody),
+	})
+	// The cassette is stored after every interaction as there is no hook
+	// to flush it when the command ends.
+	data, err := json.MarshalIndent(&r.cassette, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := renameio.WriteFile(r.file, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to store cassette: %w", err)
+	}
+	return resp, nil
+}
+
+// replayer is a http.RoundTripper that replies to requests with the
+// interactions of a cassette, without performing any network request.
+// Every interaction is replayed at most once, in the recorded order.
+type replayer struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+func newReplayer(c *Cassette) *replayer {
+	return &replayer{
+		cassette: c,
+		used:     make([]bool, len(c.Interactions)),
+	}
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Method != req.Method || in.URL != req.URL.String() || in.RequestBody != string(reqBody) {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.StatusCode, http.StatusText(in.StatusCode)),
+			StatusCode:    in.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewBufferString(in.ResponseBody)),
+			ContentLength: int64(len(in.ResponseBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded interaction for %s %s", req.Method, req.URL)
+}
+
+// readBody reads and replaces the given body so that it can be read again.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
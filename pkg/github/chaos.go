@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ChaosFault is a failure of the GitHub API simulated by the chaos mode.
+type ChaosFault string
+
+const (
+	// ChaosServerError fails the request with a 502 Bad Gateway response,
+	// without performing it.
+	ChaosServerError ChaosFault = "server error"
+	// ChaosRateLimit fails the request with an exhausted primary rate
+	// limit, without performing it.
+	ChaosRateLimit ChaosFault = "rate limit"
+	// ChaosSecondaryRateLimit fails the request with a secondary rate
+	// limit, without performing it.
+	ChaosSecondaryRateLimit ChaosFault = "secondary rate limit"
+	// ChaosConnectionReset fails the request with a connection reset,
+	// without performing it.
+	ChaosConnectionReset ChaosFault = "connection reset"
+	// ChaosPartialResults performs a GET request and drops the pagination
+	// links of its response, so that only its first page is returned.
+	ChaosPartialResults ChaosFault = "partial results"
+	// ChaosLostResponse performs a write request and fails it with a 502
+	// Bad Gateway response, as if its response was lost.
+	ChaosLostResponse ChaosFault = "lost response"
+)
+
+// chaosRateLimitReset is the time after which the rate limits simulated by
+// ChaosRateLimit and ChaosSecondaryRateLimit are reset. It is short since
+// clients stop performing any request until primary rate limits are reset.
+const chaosRateLimitReset = 10 * time.Second
+
+// chaosTransport is a http.RoundTripper injecting simulated failures into a
+// share of the requests of the next transport.
+type chaosTransport struct {
+	next http.RoundTripper
+	rate float64
+	log  io.Writer
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// withChaos returns next injecting simulated failures into the share of its
+// requests set by httpOptions.ChaosRate, or next itself if the chaos mode is
+// disabled.
+func withChaos(next http.RoundTripper) http.RoundTripper {
+	if httpOptions.ChaosRate <= 0 {
+		return next
+	}
+	seed := httpOptions.ChaosSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &chaosTransport{
+		next: next,
+		rate: httpOptions.ChaosRate,
+		log:  os.Stderr,
+		rng:  rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (t *chaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault, ok := t.pick(req)
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+	fmt.Fprintf(t.log, "[CHAOS]: Injecting %s into %s %s\n", fault, req.Method, req.URL.Path)
+
+	switch fault {
+	case ChaosServerError:
+		return chaosResponse(req, http.StatusBadGateway, nil, "Server Error"), nil
+	case ChaosRateLimit:
+		header := http.Header{}
+		header.Set("X-RateLimit-Limit", "5000")
+		header.Set("X-RateLimit-Remaining", "0")
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(chaosRateLimitReset).Unix(), 10))
+		return chaosResponse(req, http.StatusForbidden, header, "API rate limit exceeded"), nil
+	case ChaosSecondaryRateLimit:
+		header := http.Header{}
+		header.Set("Retry-After", strconv.Itoa(int(chaosRateLimitReset.Seconds())))
+		return chaosResponse(req, http.StatusForbidden, header, "You have exceeded a secondary rate limit. Please wait a few minutes before you try again."), nil
+	case ChaosConnectionReset:
+		return nil, fmt.Errorf("simulated failure: %w", syscall.ECONNRESET)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if fault == ChaosPartialResults {
+		resp.Header = resp.Header.Clone()
+		resp.Header.Del("Link")
+		return resp, nil
+	}
+	resp.Body.Close()
+	return chaosResponse(req, http.StatusBadGateway, nil, "Server Error"), nil
+}
+
+// pick returns the fault to inject into req, if any. Partial results and lost
+// responses are only injected into REST requests, as GraphQL paginates in the
+// response bodies and its queries and mutations are both POST requests.
+func (t *chaosTransport) pick(req *http.Request) (ChaosFault, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rng.Float64() >= t.rate {
+		return "", false
+	}
+	faults := []ChaosFault{ChaosServerError, ChaosRateLimit, ChaosSecondaryRateLimit, ChaosConnectionReset}
+	switch {
+	case req.Method == http.MethodGet:
+		faults = append(faults, ChaosPartialResults)
+	case !strings.HasSuffix(req.URL.Path, "/graphql"):
+		faults = append(faults, ChaosLostResponse)
+	}
+	return faults[t.rng.Intn(len(faults))], true
+}
+
+// chaosResponse returns a simulated response to req with the given status,
+// headers and error message.
+func chaosResponse(req *http.Request, status int, header http.Header, message string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	body := fmt.Sprintf(`{"message":%q}`, message)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -16,60 +16,259 @@ package github
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"time"
 
 	gh "github.com/google/go-github/v33/github"
-	"github.com/shurcooL/githubv4"
 	"golang.org/x/oauth2"
 )
 
-var errGithubToken = fmt.Errorf("environment variable GITHUB_TOKEN must be set to interact with GitHub APIs")
+var errGithubToken = fmt.Errorf("environment variable GITHUB_TOKEN must be set, or the GitHub CLI (gh) authenticated, or a token stored with 'team-manager login', or a GitHub App configured, to interact with GitHub APIs")
+
+// KeyringUser is the user the GitHub token is stored for in the OS keyring.
+const KeyringUser = "github.com"
+
+// HTTPOptions configures the HTTP client shared by the REST and GraphQL
+// clients.
+type HTTPOptions struct {
+	// RecordCassette, if set, is the file all interactions with GitHub are
+	// recorded into.
+	RecordCassette string
+
+	// ReplayCassette, if set, is the file the interactions with GitHub are
+	// replayed from, instead of performing any network request.
+	ReplayCassette string
+
+	// CABundle, if set, is a PEM file of additional certificate authorities
+	// trusted to verify the GitHub servers, e.g. of GHES with a private CA.
+	CABundle string
+
+	// ClientCert and ClientKey, if set, are the PEM files of the TLS client
+	// certificate presented to the servers.
+	ClientCert string
+	ClientKey  string
+
+	// DialTimeout is the timeout of establishing connections, 0 for the
+	// default of 30s.
+	DialTimeout time.Duration
+
+	// FallbackDelay is the delay after which a connection is attempted over
+	// IPv4 if the IPv6 one didn't succeed yet ("Happy Eyeballs"), 0 for the
+	// default of 300ms. A negative value disables the fallback.
+	FallbackDelay time.Duration
+
+	// DNSRetries is the number of times a connection is retried after a DNS
+	// resolution failure.
+	DNSRetries int
+
+	// AuthProviders are the names of the auth providers tried in order to
+	// obtain a token, nil for all AuthProviders in their default order.
+	AuthProviders []string
+
+	// ChaosRate, if positive, is the share of the requests into which
+	// simulated failures are injected, to validate the alerting, resume
+	// and rollback procedures with staging organizations.
+	ChaosRate float64
+
+	// ChaosSeed is the seed of the random injection of failures, 0 for a
+	// random seed.
+	ChaosSeed int64
+
+	// Offline, if set, fails every access to the network with ErrOffline.
+	// Interactions replayed from ReplayCassette are still allowed.
+	Offline bool
+}
+
+// ErrOffline is the error of all accesses to the network in offline mode.
+var ErrOffline = errors.New("network access disabled in offline mode")
+
+// OfflineTransport is a http.RoundTripper failing all requests with
+// ErrOffline, e.g. for the clients of other services in offline mode.
+var OfflineTransport http.RoundTripper = errTransport{ErrOffline}
+
+var httpOptions HTTPOptions
+
+// SetHTTPOptions sets the options of all clients created afterwards.
+func SetHTTPOptions(opts HTTPOptions) {
+	httpOptions = opts
+}
 
 func NewClientFromEnv() (*gh.Client, error) {
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		return nil, errGithubToken
+	source, err := tokenSourceFromEnv()
+	if err != nil {
+		return nil, err
 	}
 
-	return NewClient(token), nil
+	return gh.NewClient(newHTTPClient(source)), nil
 }
 
 func NewClient(ghToken string) *gh.Client {
-	return gh.NewClient(
-		oauth2.NewClient(
-			context.Background(),
-			oauth2.StaticTokenSource(
-				&oauth2.Token{
-					AccessToken: ghToken,
-				},
-			),
-		),
-	)
+	return gh.NewClient(newHTTPClient(staticTokenSource(ghToken)))
+}
+
+// NewClientWithHTTPClient returns a client of the REST API performing the
+// requests with httpClient, e.g. with a transport authenticating as a GitHub
+// App. The options of SetHTTPOptions don't apply to it, except Offline.
+func NewClientWithHTTPClient(httpClient *http.Client) *gh.Client {
+	return gh.NewClient(withDeprecations(httpClient))
 }
 
-func NewClientGraphQLFromEnv() (*githubv4.Client, error) {
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		return nil, errGithubToken
+func NewClientGraphQLFromEnv() (*GraphQLClient, error) {
+	source, err := tokenSourceFromEnv()
+	if err != nil {
+		return nil, err
 	}
 
-	return NewClientGraphQL(token), nil
-}
-
-func NewClientGraphQL(ghToken string) *githubv4.Client {
-	return githubv4.NewClientWithAcceptHeaders(
-		oauth2.NewClient(
-			context.Background(),
-			oauth2.StaticTokenSource(
-				&oauth2.Token{
-					AccessToken: ghToken,
-				},
-			),
-		),
-		[]string{
-			// Set header for team review assignments preview: https://docs.github.com/en/graphql/overview/schema-previews#team-review-assignments-preview
-			"application/vnd.github.stone-crop-preview+json",
-		},
+	return newGraphQLClient(newHTTPClient(source), graphQLAcceptHeaders), nil
+}
+
+func NewClientGraphQL(ghToken string) *GraphQLClient {
+	return newGraphQLClient(newHTTPClient(staticTokenSource(ghToken)), graphQLAcceptHeaders)
+}
+
+// NewClientGraphQLWithHTTPClient returns a client of the GraphQL API
+// performing the requests with httpClient, see NewClientWithHTTPClient.
+func NewClientGraphQLWithHTTPClient(httpClient *http.Client) *GraphQLClient {
+	return newGraphQLClient(withDeprecations(httpClient), graphQLAcceptHeaders)
+}
+
+var graphQLAcceptHeaders = []string{
+	// Set header for team review assignments preview: https://docs.github.com/en/graphql/overview/schema-previews#team-review-assignments-preview
+	"application/vnd.github.stone-crop-preview+json",
+}
+
+// withDeprecations returns a copy of httpClient recording the deprecation
+// notices of the responses, see Deprecations.
+func withDeprecations(httpClient *http.Client) *http.Client {
+	c := *httpClient
+	transport := c.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if httpOptions.Offline {
+		transport = OfflineTransport
+	}
+	c.Transport = deprecationTransport{transport}
+	return &c
+}
+
+// staticTokenSource returns the token source of the given token.
+func staticTokenSource(ghToken string) oauth2.TokenSource {
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: ghToken})
+}
+
+// newHTTPClient returns the HTTP client authenticating with the tokens of the
+// given token source, configured according to httpOptions.
+func newHTTPClient(source oauth2.TokenSource) *http.Client {
+	if httpOptions.ReplayCassette != "" {
+		c, err := LoadCassette(httpOptions.ReplayCassette)
+		if err != nil {
+			// Fail every request rather than silently falling back to the
+			// network.
+			return &http.Client{Transport: errTransport{err}}
+		}
+		return &http.Client{Transport: deprecationTransport{withChaos(newReplayer(c))}}
+	}
+
+	transport, err := newTransport()
+	if err != nil {
+		return &http.Client{Transport: errTransport{err}}
+	}
+	client := oauth2.NewClient(
+		context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: transport}),
+		source,
 	)
+	if httpOptions.RecordCassette != "" {
+		client.Transport = &recorder{
+			next: client.Transport,
+			file: httpOptions.RecordCassette,
+		}
+	}
+	client.Transport = deprecationTransport{withChaos(client.Transport)}
+	return client
+}
+
+// newTransport returns the unauthenticated transport configured according to
+// httpOptions, or ErrOffline in offline mode. Proxies are configured with the HTTPS_PROXY and NO_PROXY
+// environment variables.
+func newTransport() (*http.Transport, error) {
+	if httpOptions.Offline {
+		return nil, ErrOffline
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.DialContext = newDialContext()
+	if httpOptions.CABundle == "" && httpOptions.ClientCert == "" {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if httpOptions.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(httpOptions.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA bundle %q", httpOptions.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if httpOptions.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(httpOptions.ClientCert, httpOptions.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// newDialContext returns the dial function of the transport, retrying on DNS
+// resolution failures so that long running commands survive flaky egress.
+func newDialContext() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:       30 * time.Second,
+		KeepAlive:     30 * time.Second,
+		FallbackDelay: httpOptions.FallbackDelay,
+	}
+	if httpOptions.DialTimeout != 0 {
+		dialer.Timeout = httpOptions.DialTimeout
+	}
+	retries := httpOptions.DNSRetries
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		backoff := time.Second
+		for attempt := 0; ; attempt++ {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			var dnsErr *net.DNSError
+			if err == nil || attempt >= retries || !errors.As(err, &dnsErr) {
+				return conn, err
+			}
+			select {
+			case <-ctx.Done():
+				return nil, err
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+	}
+}
+
+// errTransport is a http.RoundTripper failing all requests with err.
+type errTransport struct {
+	err error
+}
+
+func (t errTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
 }
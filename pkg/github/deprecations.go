@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Deprecation is a deprecation notice of the GitHub API, from the Deprecation
+// and Sunset headers of a REST response or from the warnings of a GraphQL
+// response.
+type Deprecation struct {
+	// Endpoint is the method and path of the first REST request the notice
+	// was received for, or GraphQL.
+	Endpoint string `json:"endpoint"`
+	// Message is the message of a GraphQL notice.
+	Message string `json:"message,omitempty"`
+	// Deprecated is the Deprecation header, e.g. the date the endpoint was
+	// deprecated at.
+	Deprecated string `json:"deprecated,omitempty"`
+	// Sunset is the date the endpoint stops working at, if known.
+	Sunset string `json:"sunset,omitempty"`
+	// Link is the documentation of the deprecation, if any.
+	Link string `json:"link,omitempty"`
+	// Count is the number of responses the notice was received in.
+	Count int `json:"count"`
+}
+
+func (d Deprecation) String() string {
+	var details []string
+	if d.Message != "" {
+		details = append(details, d.Message)
+	}
+	if d.Deprecated != "" && d.Deprecated != "true" {
+		details = append(details, "deprecated since "+d.Deprecated)
+	} else if d.Message == "" {
+		details = append(details, "deprecated")
+	}
+	if d.Sunset != "" {
+		details = append(details, "removed on "+d.Sunset)
+	}
+	if d.Link != "" {
+		details = append(details, "see "+d.Link)
+	}
+	return fmt.Sprintf("%s (%d responses): %s", d.Endpoint, d.Count, strings.Join(details, ", "))
+}
+
+// deprecations are the deprecation notices received by all clients, in the
+// order they were first received in.
+var deprecations struct {
+	mu    sync.Mutex
+	notes []Deprecation
+	index map[Deprecation]int
+}
+
+// Deprecations returns the deprecation notices received by all clients so
+// far. The notices of REST responses are grouped by method and headers, since
+// deprecated endpoints are usually requested for many resources.
+func Deprecations() []Deprecation {
+	deprecations.mu.Lock()
+	defer deprecations.mu.Unlock()
+	return append([]Deprecation(nil), deprecations.notes...)
+}
+
+// recordDeprecation records the given notice, keyed by key.
+func recordDeprecation(key, d Deprecation) {
+	deprecations.mu.Lock()
+	defer deprecations.mu.Unlock()
+	if i, ok := deprecations.index[key]; ok {
+		deprecations.notes[i].Count++
+		return
+	}
+	if deprecations.index == nil {
+		deprecations.index = map[Deprecation]int{}
+	}
+	d.Count = 1
+	deprecations.index[key] = len(deprecations.notes)
+	deprecations.notes = append(deprecations.notes, d)
+}
+
+// deprecationLink matches the Link header entries documenting a deprecation,
+// as specified by RFC 8594.
+var deprecationLink = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?(?:deprecation|sunset)"?`)
+
+// deprecationTransport is a http.RoundTripper recording the deprecation
+// notices of the responses of the next transport.
+type deprecationTransport struct {
+	next http.RoundTripper
+}
+
+func (t deprecationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	deprecated, sunset := resp.Header.Get("Deprecation"), resp.Header.Get("Sunset")
+	if deprecated != "" || sunset != "" {
+		var link string
+		if m := deprecationLink.FindStringSubmatch(strings.Join(resp.Header.Values("Link"), ",")); m != nil {
+			link = m[1]
+		}
+		d := Deprecation{
+			Endpoint:   req.Method + " " + req.URL.Path,
+			Deprecated: deprecated,
+			Sunset:     sunset,
+			Link:       link,
+		}
+		key := d
+		key.Endpoint = req.Method
+		recordDeprecation(key, d)
+	}
+
+	if strings.HasSuffix(req.URL.Path, "/graphql") && resp.StatusCode == http.StatusOK {
+		body, err := readBody(&resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		var out struct {
+			Extensions struct {
+				Warnings []struct {
+					Type    string `json:"type"`
+					Message string `json:"message"`
+					Link    string `json:"link"`
+					Data    struct {
+						EffectiveDate string `json:"effective_date"`
+					} `json:"data"`
+				} `json:"warnings"`
+			} `json:"extensions"`
+		}
+		// Malformed responses are left to the GraphQL client to report.
+		if json.Unmarshal(body, &out) == nil {
+			for _, w := range out.Extensions.Warnings {
+				if w.Type != "" && w.Type != "DEPRECATION" {
+					continue
+				}
+				d := Deprecation{
+					Endpoint: "GraphQL",
+					Message:  w.Message,
+					Sunset:   w.Data.EffectiveDate,
+					Link:     w.Link,
+				}
+				recordDeprecation(d, d)
+			}
+		}
+	}
+	return resp, nil
+}
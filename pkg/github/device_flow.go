@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	deviceCodeURL  = "https://github.com/login/device/code"
+	accessTokenURL = "https://github.com/login/oauth/access_token"
+)
+
+// DeviceCode is the code the user has to enter at VerificationURI to
+// authorize a device flow login.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// RequestDeviceCode starts the OAuth device authorization flow of the OAuth
+// app with the given client ID, requesting the given scopes.
+func RequestDeviceCode(ctx context.Context, clientID string, scopes []string) (*DeviceCode, error) {
+	var code DeviceCode
+	err := postForm(ctx, deviceCodeURL, url.Values{
+		"client_id": {clientID},
+		"scope":     {strings.Join(scopes, " ")},
+	}, &code)
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// WaitForDeviceToken polls GitHub until the user authorized the given device
+// code, and returns the resulting access token.
+func WaitForDeviceToken(ctx context.Context, clientID string, code *DeviceCode) (string, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(interval):
+		}
+
+		var resp struct {
+			AccessToken      string `json:"access_token"`
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+			Interval         int    `json:"interval"`
+		}
+		err := postForm(ctx, accessTokenURL, url.Values{
+			"client_id":   {clientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}, &resp)
+		if err != nil {
+			return "", err
+		}
+		switch resp.Error {
+		case "":
+			return resp.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			// RFC 8628 requires increasing the interval by 5 seconds.
+			interval += 5 * time.Second
+			if i := time.Duration(resp.Interval) * time.Second; i > interval {
+				interval = i
+			}
+		default:
+			return "", fmt.Errorf("device authorization failed: %s: %s", resp.Error, resp.ErrorDescription)
+		}
+	}
+	return "", fmt.Errorf("device code expired")
+}
+
+func postForm(ctx context.Context, u string, values url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	transport, err := newTransport()
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, u)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
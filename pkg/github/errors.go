@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v33/github"
+)
+
+// Error is an error returned by GitHub together with a hint on how to
+// resolve it.
+type Error struct {
+	Err  error
+	Hint string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s\nhint: %s", e.Err, e.Hint)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+const (
+	hintUnauthorized = "The GitHub token is invalid or expired, set a new one in GITHUB_TOKEN or run 'team-manager login'."
+	hintSAML         = "The GitHub token is not authorized for the SAML single sign-on of the organization, authorize it with 'Configure SSO' in the token settings on github.com."
+	hintForbidden    = "The GitHub token lacks the permissions for this operation, check its scopes and whether its owner is an organization owner."
+	hintNotFound     = "The team, repository or user does not exist, or the GitHub token can't see it, e.g. because the team is secret or the token lacks the read:org scope."
+	hintAlreadyDone  = "The change was already made in GitHub, re-run the command to work with the current upstream state."
+	hintInvalid      = "GitHub rejected the request, check the team names and logins in the configuration file."
+	hintRateLimit    = "The GitHub API rate limit is exhausted, retry later or use a token with a higher rate limit."
+	hintSecondary    = "The GitHub secondary rate limit was hit, retry in a few minutes."
+)
+
+// TranslateError returns err with a hint on how to resolve it if it is
+// caused by a common GitHub API error, e.g. a missing team, a token not
+// authorized for SAML single sign-on or an exhausted rate limit. Other errors
+// are returned as is.
+func TranslateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var hinted *Error
+	if errors.As(err, &hinted) {
+		return err
+	}
+	if hint := errorHint(err); hint != "" {
+		return &Error{Err: err, Hint: hint}
+	}
+	return err
+}
+
+func errorHint(err error) string {
+	var rateLimitErr *gh.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return fmt.Sprintf("The GitHub API rate limit is exhausted until %s, retry afterwards or use a token with a higher rate limit.",
+			rateLimitErr.Rate.Reset.Format(time.RFC3339))
+	}
+	var abuseErr *gh.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return fmt.Sprintf("The GitHub secondary rate limit was hit, retry in %s.", abuseErr.RetryAfter)
+		}
+		return hintSecondary
+	}
+	var respErr *gh.ErrorResponse
+	if errors.As(err, &respErr) && respErr.Response != nil {
+		switch respErr.Response.StatusCode {
+		case http.StatusUnauthorized:
+			return hintUnauthorized
+		case http.StatusForbidden:
+			if strings.Contains(respErr.Message, "SAML") {
+				return hintSAML
+			}
+			// go-github only detects secondary rate limits by their
+			// former documentation URL.
+			if strings.Contains(respErr.Message, "secondary rate limit") {
+				return hintSecondary
+			}
+			return hintForbidden
+		case http.StatusNotFound:
+			return hintNotFound
+		case http.StatusUnprocessableEntity:
+			for _, e := range respErr.Errors {
+				if strings.Contains(e.Message, "already") {
+					return hintAlreadyDone
+				}
+			}
+			if strings.Contains(respErr.Message, "already") {
+				return hintAlreadyDone
+			}
+			return hintInvalid
+		}
+		return ""
+	}
+
+	// The GraphQL API reports errors as plain messages.
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "SAML"):
+		return hintSAML
+	case strings.Contains(msg, "401 Unauthorized"), strings.Contains(msg, "Bad credentials"):
+		return hintUnauthorized
+	case strings.Contains(msg, "Could not resolve to"):
+		return hintNotFound
+	case strings.Contains(msg, "rate limit"):
+		return hintRateLimit
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ghCLIHost is the host the token of the GitHub CLI is read for.
+const ghCLIHost = "github.com"
+
+// tokenFromGHCLI returns the token the GitHub CLI (gh) is authenticated with.
+// It first asks gh itself, which also covers tokens stored in the OS
+// keyring, and falls back to the hosts file of older gh versions that store
+// the token in plain text.
+func tokenFromGHCLI() (string, error) {
+	out, err := exec.Command("gh", "auth", "token", "--hostname", ghCLIHost).Output()
+	if err == nil {
+		if token := string(bytes.TrimSpace(out)); token != "" {
+			return token, nil
+		}
+	}
+
+	file, err := ghCLIHostsFile()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if token := hosts[ghCLIHost].OAuthToken; token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("no token for %s in %s", ghCLIHost, file)
+}
+
+// ghCLIHostsFile returns the path of the hosts file of the GitHub CLI,
+// following the same lookup order as gh.
+func ghCLIHostsFile() (string, error) {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml"), nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gh", "hosts.yml"), nil
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shurcooL/githubv4"
+)
+
+const graphQLURL = "https://api.github.com/graphql"
+
+// GraphQLClient is a GitHub GraphQL API v4 client that, besides the queries
+// derived from structs of githubv4.Client, can perform queries built at
+// runtime with Field.
+type GraphQLClient struct {
+	*githubv4.Client
+
+	httpClient    *http.Client
+	url           string
+	acceptHeaders []string
+}
+
+func newGraphQLClient(httpClient *http.Client, acceptHeaders []string) *GraphQLClient {
+	return &GraphQLClient{
+		Client:        githubv4.NewClientWithAcceptHeaders(httpClient, acceptHeaders),
+		httpClient:    httpClient,
+		url:           graphQLURL,
+		acceptHeaders: acceptHeaders,
+	}
+}
+
+// QueryRaw performs the given query and decodes its data into v with
+// encoding/json.
+func (c *GraphQLClient) QueryRaw(ctx context.Context, query string, variables map[string]interface{}, v interface{}) error {
+	in := struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}{
+		Query:     query,
+		Variables: variables,
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(in); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, h := range c.acceptHeaders {
+		req.Header.Add("Accept", h)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-200 OK status code: %s", resp.Status)
+	}
+
+	var out struct {
+		Data   json.RawMessage
+		Errors QueryErrors
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return err
+	}
+	if len(out.Errors) != 0 {
+		// The data is still decoded, the fields with errors being null,
+		// for the callers expecting some of them to fail.
+		if len(out.Data) != 0 {
+			_ = json.Unmarshal(out.Data, v)
+		}
+		return out.Errors
+	}
+	return json.Unmarshal(out.Data, v)
+}
+
+// QueryError is an error of a field of a GraphQL query.
+type QueryError struct {
+	// Type is the type of the error, e.g. NOT_FOUND.
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// QueryErrors are the errors of a GraphQL query.
+type QueryErrors []QueryError
+
+func (e QueryErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, qe := range e {
+		msgs = append(msgs, qe.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// OnlyNotFound returns true if all the errors are NOT_FOUND errors, e.g. of
+// users that don't exist.
+func (e QueryErrors) OnlyNotFound() bool {
+	for _, qe := range e {
+		if qe.Type != "NOT_FOUND" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	gh "github.com/google/go-github/v33/github"
+)
+
+// Operation is a kind of operation commands perform against GitHub.
+type Operation struct {
+	Name string
+	// Scopes are the OAuth scopes of which the token needs at least one.
+	Scopes []string
+	// Permissions are the permissions fine-grained tokens and GitHub Apps
+	// need, as permission:access with the names of the GitHub App manifests.
+	Permissions []string
+	// OrgAdmin is set if the token owner needs to be an organization owner.
+	OrgAdmin bool
+}
+
+var (
+	OperationReadTeams = Operation{
+		Name:        "read teams and members",
+		Scopes:      []string{"read:org"},
+		Permissions: []string{"members:read"},
+	}
+	OperationReadUsers = Operation{
+		Name: "read public user profiles",
+	}
+	OperationReadRepositories = Operation{
+		Name:        "read repositories and their files",
+		Scopes:      []string{"repo"},
+		Permissions: []string{"metadata:read", "contents:read"},
+	}
+	OperationReadBranchProtections = Operation{
+		Name:        "read branch protection rules",
+		Scopes:      []string{"repo"},
+		Permissions: []string{"administration:read"},
+	}
+	OperationReadTwoFactorStatus = Operation{
+		Name:        "read the two-factor authentication status of members",
+		Scopes:      []string{"read:org"},
+		Permissions: []string{"members:read"},
+		OrgAdmin:    true,
+	}
+	OperationReadAuditLog = Operation{
+		Name:        "read the organization audit log",
+		Scopes:      []string{"read:audit_log"},
+		Permissions: []string{"organization_administration:read"},
+		OrgAdmin:    true,
+	}
+	OperationReadSSOIdentities = Operation{
+		Name:        "read SAML SSO identities and credential authorizations",
+		Scopes:      []string{"admin:org"},
+		Permissions: []string{"organization_administration:read"},
+		OrgAdmin:    true,
+	}
+	OperationManageTeams = Operation{
+		Name:        "manage team members and code review assignments",
+		Scopes:      []string{"admin:org"},
+		Permissions: []string{"members:write"},
+		OrgAdmin:    true,
+	}
+	OperationManageOrgSettings = Operation{
+		Name:        "change organization settings",
+		Scopes:      []string{"admin:org"},
+		Permissions: []string{"organization_administration:write"},
+		OrgAdmin:    true,
+	}
+	OperationManageSecurityManagers = Operation{
+		Name:        "designate security manager teams",
+		Scopes:      []string{"admin:org"},
+		Permissions: []string{"organization_administration:write"},
+		OrgAdmin:    true,
+	}
+	OperationManageRepositoryAccess = Operation{
+		Name:        "grant teams access to repositories",
+		Scopes:      []string{"repo"},
+		Permissions: []string{"administration:write"},
+		OrgAdmin:    true,
+	}
+	OperationManageRepositoryTopics = Operation{
+		Name:        "change repository topics",
+		Scopes:      []string{"repo", "public_repo"},
+		Permissions: []string{"administration:write"},
+	}
+	OperationManageRepositoryProperties = Operation{
+		Name:        "set repository custom properties",
+		Scopes:      []string{"repo"},
+		Permissions: []string{"repository_custom_properties:write"},
+	}
+	OperationManageLabels = Operation{
+		Name:        "manage repository labels",
+		Scopes:      []string{"repo", "public_repo"},
+		Permissions: []string{"issues:write"},
+	}
+	OperationOpenPullRequests = Operation{
+		Name:        "open pull requests",
+		Scopes:      []string{"repo", "public_repo"},
+		Permissions: []string{"contents:write", "pull_requests:write"},
+	}
+	OperationManageProjects = Operation{
+		Name:        "grant teams access to projects",
+		Scopes:      []string{"project"},
+		Permissions: []string{"organization_projects:admin"},
+	}
+)
+
+// impliedScopes maps OAuth scopes to the scopes they include.
+var impliedScopes = map[string][]string{
+	"admin:org": {"write:org", "read:org"},
+	"write:org": {"read:org"},
+	"repo":      {"public_repo", "repo:status", "repo_deployment", "repo:invite", "security_events"},
+}
+
+// RequiredScopes returns the OAuth scopes a classic token needs to perform all
+// the given operations, sorted, leaving out the scopes implied by others.
+func RequiredScopes(ops ...Operation) []string {
+	required := map[string]bool{}
+	for _, op := range ops {
+		if len(op.Scopes) != 0 && !hasAnyScope(withImpliedScopes(required), op.Scopes) {
+			required[op.Scopes[0]] = true
+		}
+	}
+	var scopes []string
+	for scope := range required {
+		implied := false
+		for other := range required {
+			for _, s := range impliedScopes[other] {
+				implied = implied || s == scope
+			}
+		}
+		if !implied {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+func withImpliedScopes(scopes map[string]bool) map[string]bool {
+	all := map[string]bool{}
+	for scope := range scopes {
+		all[scope] = true
+		for _, implied := range impliedScopes[scope] {
+			all[implied] = true
+		}
+	}
+	return all
+}
+
+// accessLevels orders the access levels of permissions.
+var accessLevels = map[string]int{"read": 1, "write": 2, "admin": 3}
+
+// RequiredPermissions returns the permissions a fine-grained token or a GitHub
+// App needs to perform all the given operations, sorted, with the highest
+// access level needed for every permission.
+func RequiredPermissions(ops ...Operation) []string {
+	access := map[string]string{}
+	for _, op := range ops {
+		for _, permission := range op.Permissions {
+			name, level, _ := strings.Cut(permission, ":")
+			if accessLevels[level] > accessLevels[access[name]] {
+				access[name] = level
+			}
+		}
+	}
+	permissions := make([]string, 0, len(access))
+	for name, level := range access {
+		permissions = append(permissions, name+":"+level)
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// MissingPermission is a permission the token lacks to perform an operation.
+type MissingPermission struct {
+	Operation Operation
+	Reason    string
+}
+
+func (m MissingPermission) String() string {
+	return fmt.Sprintf("cannot %s: %s", m.Operation.Name, m.Reason)
+}
+
+// Preflight checks that the token of client can perform the given operations
+// in org, and returns the permissions it lacks. Scopes can only be verified
+// for OAuth app and classic personal access tokens, fine-grained tokens are
+// only checked for organization ownership. The permissions of installation
+// tokens of GitHub Apps, which can't get the authenticated user, aren't
+// checked.
+func Preflight(ctx context.Context, client *gh.Client, org string, ops ...Operation) ([]MissingPermission, error) {
+	user, resp, err := client.Users.Get(ctx, "")
+	if resp != nil && resp.StatusCode == http.StatusForbidden {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+
+	var scopes map[string]bool
+	if header, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; ok {
+		scopes = map[string]bool{}
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			scope = strings.TrimSpace(scope)
+			if scope == "" {
+				continue
+			}
+			scopes[scope] = true
+			for _, implied := range impliedScopes[scope] {
+				scopes[implied] = true
+			}
+		}
+	}
+
+	isAdmin := false
+	membership, resp, err := client.Organizations.GetOrgMembership(ctx, "", org)
+	switch {
+	case err == nil:
+		isAdmin = membership.GetRole() == "admin" && membership.GetState() == "active"
+	case resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden):
+	default:
+		return nil, fmt.Errorf("failed to get organization membership: %w", err)
+	}
+
+	var missing []MissingPermission
+	for _, op := range ops {
+		if scopes != nil && !hasAnyScope(scopes, op.Scopes) {
+			missing = append(missing, MissingPermission{
+				Operation: op,
+				Reason:    fmt.Sprintf("token is missing the %s scope", strings.Join(op.Scopes, " or ")),
+			})
+		}
+		if op.OrgAdmin && !isAdmin {
+			missing = append(missing, MissingPermission{
+				Operation: op,
+				Reason:    fmt.Sprintf("user %s is not an owner of organization %s", user.GetLogin(), org),
+			})
+		}
+	}
+	return missing, nil
+}
+
+func hasAnyScope(scopes map[string]bool, want []string) bool {
+	for _, scope := range want {
+		if scopes[scope] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"github.com/cilium/team-manager/pkg/config"
+
+	"github.com/shurcooL/githubv4"
+)
+
+type UpdateProjectV2CollaboratorsInput struct {
+	ClientMutationID githubv4.String `json:"clientMutationId,omitempty"`
+
+	Collaborators []ProjectV2Collaborator `json:"collaborators"`
+
+	ProjectID githubv4.ID `json:"projectId"`
+}
+
+type ProjectV2Collaborator struct {
+	Role config.ProjectRole `json:"role"`
+
+	TeamID githubv4.ID `json:"teamId,omitempty"`
+
+	UserID githubv4.ID `json:"userId,omitempty"`
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package github
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Field is a field of a GraphQL query built at runtime.
+type Field struct {
+	Name string
+	// Args are the arguments of the field, e.g. "first: 100".
+	Args   string
+	Fields []*Field
+}
+
+// NewField returns the field with the given name selecting the given fields.
+func NewField(name string, fields ...*Field) *Field {
+	return &Field{Name: name, Fields: fields}
+}
+
+// WithArgs sets the arguments of f.
+func (f *Field) WithArgs(args string) *Field {
+	f.Args = args
+	return f
+}
+
+// Add selects the given fields in f.
+func (f *Field) Add(fields ...*Field) *Field {
+	f.Fields = append(f.Fields, fields...)
+	return f
+}
+
+// AddPath selects the field with the given dot separated path in f, reusing
+// the fields of the path that are already selected.
+func (f *Field) AddPath(path string) *Field {
+	parent := f
+	for _, name := range strings.Split(path, ".") {
+		var next *Field
+		for _, sub := range parent.Fields {
+			if sub.Name == name && sub.Args == "" {
+				next = sub
+				break
+			}
+		}
+		if next == nil {
+			next = NewField(name)
+			parent.Add(next)
+		}
+		parent = next
+	}
+	return f
+}
+
+func (f *Field) String() string {
+	var sb strings.Builder
+	f.write(&sb)
+	return sb.String()
+}
+
+func (f *Field) write(sb *strings.Builder) {
+	sb.WriteString(f.Name)
+	if f.Args != "" {
+		fmt.Fprintf(sb, "(%s)", f.Args)
+	}
+	if len(f.Fields) == 0 {
+		return
+	}
+	sb.WriteString("{")
+	for i, sub := range f.Fields {
+		if i != 0 {
+			sb.WriteString(" ")
+		}
+		sub.write(sb)
+	}
+	sb.WriteString("}")
+}
+
+// BuildQuery returns the query selecting the given fields, declaring the
+// given variables, which map the variable names to their GraphQL types.
+func BuildQuery(variables map[string]string, fields ...*Field) string {
+	var sb strings.Builder
+	sb.WriteString("query")
+	if len(variables) != 0 {
+		names := make([]string, 0, len(variables))
+		for name := range variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		decls := make([]string, 0, len(names))
+		for _, name := range names {
+			decls = append(decls, fmt.Sprintf("$%s:%s", name, variables[name]))
+		}
+		fmt.Fprintf(&sb, "(%s)", strings.Join(decls, ","))
+	}
+	sb.WriteString(NewField("", fields...).String())
+	return sb.String()
+}
+
+// ValueAt returns the value at the given dot separated path of a node decoded
+// from a query built with AddPath, formatted as a string. ok is false if the
+// value is null or missing.
+func ValueAt(node map[string]interface{}, path string) (value string, ok bool) {
+	var v interface{} = node
+	for _, name := range strings.Split(path, ".") {
+		m, isMap := v.(map[string]interface{})
+		if !isMap {
+			return "", false
+		}
+		v = m[name]
+	}
+	switch v := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// Fields returns the fields with the given names, without any selection.
+func Fields(names ...string) []*Field {
+	fields := make([]*Field, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, NewField(name))
+	}
+	return fields
+}
+
+// Connection returns the field of the GraphQL connection with the given name
+// and arguments, selecting the given fields of its nodes and its page info.
+// Its result can be decoded into a ConnectionResult.
+func Connection(name, args string, nodeFields ...*Field) *Field {
+	return NewField(name,
+		NewField("nodes", nodeFields...),
+		NewField("pageInfo", Fields("endCursor", "hasNextPage")...),
+	).WithArgs(args)
+}
+
+// ConnectionResult is the result of a field built with Connection.
+type ConnectionResult[T any] struct {
+	Nodes    []T
+	PageInfo PageInfo
+}
+
+// PageInfo is the page info of a GraphQL connection.
+type PageInfo struct {
+	EndCursor   string
+	HasNextPage bool
+}
@@ -25,13 +25,31 @@ type UpdateTeamReviewAssignmentInput struct {
 
 	ClientMutationID githubv4.String `json:"clientMutationId,omitempty"`
 
+	// CountMembersAlreadyRequested, IncludeChildTeamMembers and
+	// RemoveTeamRequest are left untouched if nil.
+	CountMembersAlreadyRequested *githubv4.Boolean `json:"countMembersAlreadyRequested,omitempty"`
+
 	Enabled githubv4.Boolean `json:"enabled"`
 
 	ExcludedTeamMemberIDs []githubv4.ID `json:"excludedTeamMemberIds,omitempty"`
 
 	ID githubv4.ID `json:"id"`
 
+	IncludeChildTeamMembers *githubv4.Boolean `json:"includeChildTeamMembers,omitempty"`
+
 	NotifyTeam githubv4.Boolean `json:"notifyTeam"`
 
+	RemoveTeamRequest *githubv4.Boolean `json:"removeTeamRequest,omitempty"`
+
 	TeamMemberCount githubv4.Int `json:"teamMemberCount"`
 }
+
+// NewReviewAssignmentOption returns the value of the given option of a
+// config.CodeReviewAssignment for an UpdateTeamReviewAssignmentInput, nil if
+// the option is unset.
+func NewReviewAssignmentOption(option *bool) *githubv4.Boolean {
+	if option == nil {
+		return nil
+	}
+	return githubv4.NewBoolean(githubv4.Boolean(*option))
+}
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package keyring stores secrets in the credential store of the operating
+// system: the Keychain on macOS, the Secret Service on Linux and the
+// Credential Manager on Windows.
+package keyring
+
+import (
+	"errors"
+)
+
+// service is the name under which all secrets are stored.
+const service = "team-manager"
+
+var (
+	// ErrNotFound is returned when no secret is stored for the given user.
+	ErrNotFound = errors.New("secret not found in keyring")
+
+	// ErrUnsupported is returned on operating systems without a supported
+	// credential store.
+	ErrUnsupported = errors.New("keyring not supported on this operating system")
+)
+
+// Set stores the secret of the given user, replacing any existing one.
+func Set(user, secret string) error {
+	return set(user, secret)
+}
+
+// Get returns the secret of the given user, or ErrNotFound.
+func Get(user string) (string, error) {
+	return get(user)
+}
+
+// Delete removes the secret of the given user, or returns ErrNotFound.
+func Delete(user string) error {
+	return del(user)
+}
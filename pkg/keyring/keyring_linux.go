@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package keyring
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// The Secret Service is accessed through secret-tool(1), shipped with
+// libsecret, which reads secrets from stdin so that they don't show up in
+// the process list.
+
+func set(user, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", service+" "+user, "service", service, "user", user)
+	cmd.Stdin = strings.NewReader(secret)
+	return cmd.Run()
+}
+
+func get(user string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", service, "user", user).Output()
+	if err != nil || len(out) == 0 {
+		// secret-tool doesn't distinguish missing secrets from other
+		// errors.
+		return "", ErrNotFound
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func del(user string) error {
+	if _, err := get(user); err != nil {
+		return err
+	}
+	return exec.Command("secret-tool", "clear", "service", service, "user", user).Run()
+}
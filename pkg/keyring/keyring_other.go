@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+//go:build !darwin && !linux && !windows
+
+package keyring
+
+func set(string, string) error {
+	return ErrUnsupported
+}
+
+func get(string) (string, error) {
+	return "", ErrUnsupported
+}
+
+func del(string) error {
+	return ErrUnsupported
+}
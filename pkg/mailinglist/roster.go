@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package mailinglist reads the member rosters of mailing lists.
+package mailinglist
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+)
+
+// ParseGoogleGroupsCSV returns the email addresses of the members listed in a
+// member export of Google Groups. The column holding the addresses is looked
+// up by its header, which differs between the export formats of Google
+// Groups and of the Google Admin console.
+func ParseGoogleGroupsCSV(r io.Reader) ([]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	column := -1
+	var emails []string
+	for _, record := range records {
+		if column == -1 {
+			for i, field := range record {
+				switch strings.ToLower(strings.TrimSpace(field)) {
+				case "email address", "member email", "email":
+					column = i
+				}
+			}
+			// Google Groups exports start with a few lines of metadata
+			// before the header.
+			continue
+		}
+		if column < len(record) && strings.Contains(record[column], "@") {
+			emails = append(emails, strings.TrimSpace(record[column]))
+		}
+	}
+	if column == -1 {
+		return nil, fmt.Errorf("no email column found")
+	}
+	return emails, nil
+}
+
+// ParseMailmanRoster returns the email addresses of a mailman roster as
+// printed by 'list_members', one member per line either as a plain address or
+// as "Name <address>". Empty lines and lines starting with '#' are ignored.
+func ParseMailmanRoster(r io.Reader) ([]string, error) {
+	var emails []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addr, err := mail.ParseAddress(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid roster entry %q: %w", line, err)
+		}
+		emails = append(emails, addr.Address)
+	}
+	return emails, scanner.Err()
+}
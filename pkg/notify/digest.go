@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// Digest batches notifications into a periodic digest, to avoid sending one
+// notification per event. Notifications with at least the immediate severity
+// are sent right away. Identical notifications are only sent once per digest
+// period.
+type Digest struct {
+	notifier  Notifier
+	interval  time.Duration
+	immediate config.Severity
+
+	mu      sync.Mutex
+	pending []string
+	seen    map[string]bool
+}
+
+// NewDigest returns a Digest sending its notifications with notifier every
+// interval.
+func NewDigest(notifier Notifier, interval time.Duration, immediate config.Severity) *Digest {
+	return &Digest{
+		notifier:  notifier,
+		interval:  interval,
+		immediate: immediate,
+		seen:      map[string]bool{},
+	}
+}
+
+// Add adds a notification with the given severity to the digest.
+func (d *Digest) Add(ctx context.Context, severity config.Severity, text string) {
+	d.mu.Lock()
+	if d.seen[text] {
+		d.mu.Unlock()
+		return
+	}
+	d.seen[text] = true
+	if !severity.AtLeast(d.immediate) {
+		d.pending = append(d.pending, text)
+		d.mu.Unlock()
+		return
+	}
+	d.mu.Unlock()
+
+	if err := d.notifier.Notify(ctx, text); err != nil {
+		log.Printf("[ERROR]: Unable to send notification: %s", err)
+	}
+}
+
+// Run sends the digest every interval until ctx is done.
+func (d *Digest) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		d.flush(ctx)
+	}
+}
+
+func (d *Digest) flush(ctx context.Context) {
+	d.mu.Lock()
+	pending := d.pending
+	d.pending = nil
+	d.seen = map[string]bool{}
+	d.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+	text := fmt.Sprintf("team-manager digest of the last %s, %d notifications:\n%s", d.interval, len(pending), strings.Join(pending, "\n"))
+	if err := d.notifier.Notify(ctx, text); err != nil {
+		log.Printf("[ERROR]: Unable to send notification digest: %s", err)
+	}
+}
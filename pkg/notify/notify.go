@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package notify sends notifications about the changes and the drift
+// detected in daemon mode.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// Notifier sends notifications.
+type Notifier interface {
+	Notify(ctx context.Context, text string) error
+}
+
+// SlackWebhook posts notifications to a Slack incoming webhook.
+type SlackWebhook struct {
+	URL string
+}
+
+func (s SlackWebhook) Notify(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s from slack webhook", resp.Status)
+	}
+	return nil
+}
+
+// Log logs notifications.
+type Log struct{}
+
+func (Log) Notify(_ context.Context, text string) error {
+	log.Printf("[NOTIFY]: %s", text)
+	return nil
+}
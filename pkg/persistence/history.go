@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package persistence
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// Revision is a configuration file as it was committed at a point in time.
+type Revision struct {
+	CommittedAt time.Time
+	Config      *config.Config
+}
+
+// LoadStateHistory returns all revisions of the given configuration file
+// committed to git, oldest first. It returns no revision if the file isn't
+// tracked by git. Revisions that can't be parsed, e.g. because they don't
+// follow the current format, are skipped.
+func LoadStateHistory(file string) ([]Revision, error) {
+	dir, base := filepath.Split(file)
+	if dir == "" {
+		dir = "."
+	}
+	out, err := exec.Command("git", "-C", dir, "log", "--reverse", "--format=%H %ct", "--", base).Output()
+	if err != nil || len(bytes.TrimSpace(out)) == 0 {
+		return nil, nil
+	}
+
+	var history []Revision
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		hash, ts, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		secs, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit time %q: %w", ts, err)
+		}
+		data, err := exec.Command("git", "-C", dir, "show", hash+":./"+base).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at %s: %w", file, hash, err)
+		}
+		cfg, err := ParseState(data)
+		if err != nil {
+			continue
+		}
+		history = append(history, Revision{
+			CommittedAt: time.Unix(secs, 0),
+			Config:      cfg,
+		})
+	}
+	return history, nil
+}
@@ -15,7 +15,15 @@
 package persistence
 
 import (
+	"bytes"
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode/utf8"
 
 	"github.com/cilium/team-manager/pkg/config"
 
@@ -24,18 +32,25 @@ import (
 )
 
 func StoreState(file string, cfg *config.Config) error {
-	if err := config.SanityCheck(cfg); err != nil {
+	data, err := FormatState(cfg)
+	if err != nil {
 		return err
 	}
 
-	config.SortConfig(cfg)
+	return renameio.WriteFile(file, data, 0o666)
+}
 
-	data, err := yaml.Marshal(cfg)
-	if err != nil {
-		return err
+// FormatState returns the configuration file contents of cfg as written by
+// StoreState, after checking, normalizing and sorting it.
+func FormatState(cfg *config.Config) ([]byte, error) {
+	if err := config.SanityCheck(cfg); err != nil {
+		return nil, err
 	}
 
-	return renameio.WriteFile(file, data, 0o666)
+	config.NormalizeMemberNames(cfg)
+	config.SortConfig(cfg)
+
+	return marshal(cfg)
 }
 
 func LoadState(file string) (*config.Config, error) {
@@ -52,3 +67,155 @@ func LoadState(file string) (*config.Config, error) {
 	}
 	return &storedConfig, nil
 }
+
+// ParseState parses the given configuration file contents.
+func ParseState(data []byte) (*config.Config, error) {
+	storedConfig := config.Config{}
+	if err := yaml.Unmarshal(data, &storedConfig); err != nil {
+		return nil, err
+	}
+	return &storedConfig, nil
+}
+
+// PatchState returns a copy of the given configuration with the given YAML
+// merge patch applied, as described by RFC 7396: the mappings of the patch
+// are merged into the ones of the configuration, null values remove the
+// corresponding keys, and any other value, including lists, replaces the
+// existing one.
+func PatchState(cfg *config.Config, patch []byte) (*config.Config, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var doc, patchDoc interface{}
+	if err = yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(patch, &patchDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse patch: %w", err)
+	}
+	if _, ok := patchDoc.(map[interface{}]interface{}); !ok {
+		return nil, fmt.Errorf("patch is not a mapping")
+	}
+	data, err = yaml.Marshal(mergePatch(doc, patchDoc))
+	if err != nil {
+		return nil, err
+	}
+	return ParseState(data)
+}
+
+func mergePatch(doc, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[interface{}]interface{})
+	if !ok {
+		return patch
+	}
+	docMap, ok := doc.(map[interface{}]interface{})
+	if !ok {
+		docMap = map[interface{}]interface{}{}
+	}
+	for k, v := range patchMap {
+		if v == nil {
+			delete(docMap, k)
+			continue
+		}
+		docMap[k] = mergePatch(docMap[k], v)
+	}
+	return docMap
+}
+
+// StoreSnapshot stores the given snapshot of the upstream configuration.
+// Unlike StoreState, no sanity check is performed since upstream state is
+// stored as is.
+func StoreSnapshot(file string, snapshot *config.Snapshot) error {
+	config.NormalizeMemberNames(snapshot.Config)
+	config.SortConfig(snapshot.Config)
+
+	data, err := marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return renameio.WriteFile(file, data, 0o666)
+}
+
+// LoadSnapshot loads a snapshot of the upstream configuration.
+func LoadSnapshot(file string) (*config.Snapshot, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := config.Snapshot{}
+	if err := yaml.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.Config == nil {
+		return nil, fmt.Errorf("snapshot %q does not contain any configuration", file)
+	}
+	return &snapshot, nil
+}
+
+// LoadSnapshots loads all snapshots stored as YAML files in the given
+// directory, oldest first.
+func LoadSnapshots(dir string) ([]*config.Snapshot, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*config.Snapshot, 0, len(files))
+	for _, file := range files {
+		snapshot, err := LoadSnapshot(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load snapshot %q: %w", file, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// quotedLineRegex matches the lines whose value is a double-quoted YAML
+// scalar, the only ones backslashes start escapes in.
+var quotedLineRegex = regexp.MustCompile(`(?m)^ *(- )?([^ "#\n][^"\n]*: )?".*$`)
+
+// astralEscapeRegex matches the escaped characters outside of the Basic
+// Multilingual Plane, e.g. emoji, and escaped backslashes so that escapes
+// aren't matched after them.
+var astralEscapeRegex = regexp.MustCompile(`\\(\\|U[0-9A-F]{8})`)
+
+// marshal returns the YAML encoding of v. yaml.v2 escapes all characters
+// outside of the Basic Multilingual Plane, such as the emoji of member names,
+// which makes hand-edited files churn, so these are written as is.
+func marshal(v interface{}) ([]byte, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	unescaped := quotedLineRegex.ReplaceAllFunc(data, func(line []byte) []byte {
+		return astralEscapeRegex.ReplaceAllFunc(line, func(escape []byte) []byte {
+			if escape[1] == '\\' {
+				return escape
+			}
+			r, err := strconv.ParseUint(string(escape[2:]), 16, 32)
+			if err != nil || r < 0x10000 || !utf8.ValidRune(rune(r)) {
+				return escape
+			}
+			return []byte(string(rune(r)))
+		})
+	})
+	if bytes.Equal(unescaped, data) {
+		return data, nil
+	}
+
+	// Make sure that nothing else than the escapes was changed.
+	var before, after interface{}
+	if err := yaml.Unmarshal(data, &before); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(unescaped, &after); err != nil || !reflect.DeepEqual(before, after) {
+		return data, nil
+	}
+	return unescaped, nil
+}
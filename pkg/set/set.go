@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package set provides sets and set operations on slices. GitHub logins and
+// team names are case-insensitive, so they should be compared with the Fold
+// variants of the operations.
+package set
+
+import (
+	"sort"
+	"strings"
+)
+
+// Ordered are the types of the elements of a Set.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// A Set is a set of elements.
+type Set[T Ordered] map[T]struct{}
+
+// New returns a new Set containing elements.
+func New[T Ordered](elements ...T) Set[T] {
+	s := make(Set[T], len(elements))
+	s.Add(elements...)
+	return s
+}
+
+// Add adds elements to s.
+func (s Set[T]) Add(elements ...T) {
+	for _, element := range elements {
+		s[element] = struct{}{}
+	}
+}
+
+// Elements returns all the elements of s, sorted.
+func (s Set[T]) Elements() []T {
+	elements := make([]T, 0, len(s))
+	for element := range s {
+		elements = append(elements, element)
+	}
+	sort.Slice(elements, func(i, j int) bool { return elements[i] < elements[j] })
+	return elements
+}
+
+// Has returns whether s contains element.
+func (s Set[T]) Has(element T) bool {
+	_, ok := s[element]
+	return ok
+}
+
+// Remove removes elements from s.
+func (s Set[T]) Remove(elements ...T) {
+	for _, element := range elements {
+		delete(s, element)
+	}
+}
+
+// Union returns a new set with the elements of s and other.
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	union := make(Set[T], len(s)+len(other))
+	for element := range s {
+		union[element] = struct{}{}
+	}
+	for element := range other {
+		union[element] = struct{}{}
+	}
+	return union
+}
+
+// Intersection returns a new set with the elements of s that are in other.
+func (s Set[T]) Intersection(other Set[T]) Set[T] {
+	intersection := make(Set[T])
+	for element := range s {
+		if other.Has(element) {
+			intersection[element] = struct{}{}
+		}
+	}
+	return intersection
+}
+
+// Difference returns a new set with the elements of s that aren't in other.
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	difference := make(Set[T])
+	for element := range s {
+		if !other.Has(element) {
+			difference[element] = struct{}{}
+		}
+	}
+	return difference
+}
+
+// Difference returns the elements of a that are not in b, in the order of a.
+func Difference[T comparable](a, b []T) []T {
+	return filter(a, b, identity[T], false)
+}
+
+// DifferenceFold is like Difference but compares the elements
+// case-insensitively.
+func DifferenceFold(a, b []string) []string {
+	return filter(a, b, strings.ToLower, false)
+}
+
+// Intersection returns the elements of a that are in b, in the order of a.
+func Intersection[T comparable](a, b []T) []T {
+	return filter(a, b, identity[T], true)
+}
+
+// IntersectionFold is like Intersection but compares the elements
+// case-insensitively.
+func IntersectionFold(a, b []string) []string {
+	return filter(a, b, strings.ToLower, true)
+}
+
+// Union returns the elements of a followed by the elements of b that are not
+// in a, without duplicates.
+func Union[T comparable](a, b []T) []T {
+	return union(a, b, identity[T])
+}
+
+// UnionFold is like Union but compares the elements case-insensitively. The
+// first occurrence of every element is kept.
+func UnionFold(a, b []string) []string {
+	return union(a, b, strings.ToLower)
+}
+
+// ContainsFold returns whether s contains element, compared
+// case-insensitively.
+func ContainsFold(s []string, element string) bool {
+	for _, e := range s {
+		if strings.EqualFold(e, element) {
+			return true
+		}
+	}
+	return false
+}
+
+func identity[T any](t T) T { return t }
+
+// filter returns the elements of a whose key is in b if in is true, or isn't
+// in b otherwise.
+func filter[T any, K comparable](a, b []T, key func(T) K, in bool) []T {
+	keys := make(map[K]struct{}, len(b))
+	for _, e := range b {
+		keys[key(e)] = struct{}{}
+	}
+	var filtered []T
+	for _, e := range a {
+		if _, ok := keys[key(e)]; ok == in {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+func union[T any, K comparable](a, b []T, key func(T) K) []T {
+	seen := make(map[K]struct{}, len(a)+len(b))
+	var union []T
+	for _, s := range [][]T{a, b} {
+		for _, e := range s {
+			if _, ok := seen[key(e)]; ok {
+				continue
+			}
+			seen[key(e)] = struct{}{}
+			union = append(union, e)
+		}
+	}
+	return union
+}
@@ -1,32 +0,0 @@
-// Copyright 2021 Authors of Cilium
-//
-// Licensed under the Apache License, Version 2.0 (the "License");
-// you may not use this file except in compliance with the License.
-// You may obtain a copy of the License at
-//
-//     http://www.apache.org/licenses/LICENSE-2.0
-//
-// Unless required by applicable law or agreed to in writing, software
-// distributed under the License is distributed on an "AS IS" BASIS,
-// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-// See the License for the specific language governing permissions and
-// limitations under the License.
-
-package slices
-
-// NotIn returns the list of elements from 'a' that are not in 'b'.
-func NotIn(a, b []string) (notInt []string) {
-	for _, aMember := range a {
-		var found bool
-		for _, bMember := range b {
-			if aMember == bMember {
-				found = true
-				break
-			}
-		}
-		if !found {
-			notInt = append(notInt, aMember)
-		}
-	}
-	return notInt
-}
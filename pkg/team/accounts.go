@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// accountsPerQuery is the number of accounts resolved by each query of
+// FindMissingAccounts.
+const accountsPerQuery = 50
+
+// FindMissingAccounts returns the given logins whose GitHub account doesn't
+// exist anymore, sorted. GitHub doesn't tell deleted accounts from suspended
+// ones, both aren't found.
+//
+//	{
+//	 u0: user(login: "foo") { login }
+//	 u1: user(login: "bar") { login }
+//	}
+func (tm *Manager) FindMissingAccounts(ctx context.Context, logins []string) ([]string, error) {
+	var missing []string
+	for start := 0; start < len(logins); start += accountsPerQuery {
+		end := start + accountsPerQuery
+		if end > len(logins) {
+			end = len(logins)
+		}
+		batch := logins[start:end]
+
+		variables := map[string]interface{}{}
+		types := map[string]string{}
+		fields := make([]*github.Field, 0, len(batch))
+		for i, login := range batch {
+			name := fmt.Sprintf("l%d", i)
+			variables[name] = login
+			types[name] = "String!"
+			fields = append(fields, github.NewField(fmt.Sprintf("u%d: user", i), github.NewField("login")).WithArgs("login: $"+name))
+		}
+
+		var q map[string]*struct {
+			Login string
+		}
+		err := tm.gqlGHClient.QueryRaw(ctx, github.BuildQuery(types, fields...), variables, &q)
+		var qErrs github.QueryErrors
+		if err != nil && !(errors.As(err, &qErrs) && qErrs.OnlyNotFound()) {
+			return nil, err
+		}
+		for i, login := range batch {
+			if q[fmt.Sprintf("u%d", i)] == nil {
+				missing = append(missing, login)
+			}
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return strings.ToLower(missing[i]) < strings.ToLower(missing[j])
+	})
+	return missing, nil
+}
+
+// dropMissingAccounts removes the given missing accounts from the members
+// added to teams by plan, and from its invitations.
+func (p *Plan) dropMissingAccounts(missing []string) {
+	for teamName, change := range p.TeamChanges {
+		change.Add = set.DifferenceFold(change.Add, missing)
+		change.Promote = set.DifferenceFold(change.Promote, missing)
+		if len(change.Add) == 0 && len(change.Remove) == 0 && len(change.Promote) == 0 && len(change.Demote) == 0 {
+			delete(p.TeamChanges, teamName)
+			continue
+		}
+		p.TeamChanges[teamName] = change
+	}
+	for _, login := range missing {
+		delete(p.Invitations, login)
+	}
+}
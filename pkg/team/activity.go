@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// TeamActivity is the number of issues and pull requests referencing a team
+// over a period of time.
+type TeamActivity struct {
+	Team string
+	// Mentions is the number of issues and pull requests mentioning the team.
+	Mentions int
+	// ReviewRequests is the number of pull requests requesting a review from
+	// the team.
+	ReviewRequests int
+}
+
+// Inactive returns whether the team wasn't referenced at all.
+func (a TeamActivity) Inactive() bool {
+	return a.Mentions == 0 && a.ReviewRequests == 0
+}
+
+// GetTeamActivity returns the activity of the given teams between since and
+// until, both inclusive, using the search API.
+func (tm *Manager) GetTeamActivity(ctx context.Context, teamNames []string, since, until time.Time) ([]TeamActivity, error) {
+	period := fmt.Sprintf("created:%s..%s", since.Format(config.DateFormat), until.Format(config.DateFormat))
+	activities := make([]TeamActivity, 0, len(teamNames))
+	for _, teamName := range teamNames {
+		ref := tm.owner + "/" + tm.teamSlug(teamName)
+		mentions, err := tm.searchCount(ctx, fmt.Sprintf("team:%s %s", ref, period))
+		if err != nil {
+			return nil, fmt.Errorf("failed to search mentions of team %q: %w", teamName, err)
+		}
+		reviewRequests, err := tm.searchCount(ctx, fmt.Sprintf("type:pr team-review-requested:%s %s", ref, period))
+		if err != nil {
+			return nil, fmt.Errorf("failed to search review requests of team %q: %w", teamName, err)
+		}
+		activities = append(activities, TeamActivity{
+			Team:           teamName,
+			Mentions:       mentions,
+			ReviewRequests: reviewRequests,
+		})
+	}
+	return activities, nil
+}
+
+// searchCount returns the number of issues and pull requests matching query.
+func (tm *Manager) searchCount(ctx context.Context, query string) (int, error) {
+	result, err := tm.searchIssues(ctx, query, &gh.SearchOptions{
+		ListOptions: gh.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.GetTotal(), nil
+}
+
+// searchIssues returns the issues and pull requests matching query. As the
+// search API has a low rate limit, it waits for the rate limit to reset
+// instead of failing.
+func (tm *Manager) searchIssues(ctx context.Context, query string, opts *gh.SearchOptions) (*gh.IssuesSearchResult, error) {
+	for {
+		result, _, err := tm.ghClient.Search.Issues(ctx, query, opts)
+		if err == nil {
+			return result, nil
+		}
+
+		var (
+			rateLimitErr  *gh.RateLimitError
+			abuseLimitErr *gh.AbuseRateLimitError
+			wait          time.Duration
+		)
+		switch {
+		case errors.As(err, &rateLimitErr):
+			wait = time.Until(rateLimitErr.Rate.Reset.Time) + time.Second
+		case errors.As(err, &abuseLimitErr):
+			wait = abuseLimitErr.GetRetryAfter()
+			if wait == 0 {
+				wait = time.Minute
+			}
+		default:
+			return nil, err
+		}
+		tm.reporter.Progress("Search rate limit exceeded, waiting %s...", wait.Round(time.Second))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// LastActivity returns the last time an issue or a pull request of the
+// organization the given user is involved in, e.g. as author, commenter or
+// assignee, was updated, or the zero time if there is none.
+func (tm *Manager) LastActivity(ctx context.Context, login string) (time.Time, error) {
+	result, err := tm.searchIssues(ctx, fmt.Sprintf("org:%s involves:%s", tm.owner, login), &gh.SearchOptions{
+		Sort:        "updated",
+		Order:       "desc",
+		ListOptions: gh.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(result.Issues) == 0 {
+		return time.Time{}, nil
+	}
+	return result.Issues[0].GetUpdatedAt(), nil
+}
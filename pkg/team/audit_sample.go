@@ -0,0 +1,411 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/renameio"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// Statuses of the checks of a SampleAudit.
+const (
+	CheckPassed  = "passed"
+	CheckFailed  = "failed"
+	CheckUnknown = "unknown"
+)
+
+// AuditCheck is the result of verifying an aspect of a member or a team.
+type AuditCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// AuditSubject is a member or a team checked by a SampleAudit.
+type AuditSubject struct {
+	Name string `json:"name"`
+	// Weight is the weight the subject was sampled with.
+	Weight int          `json:"weight"`
+	Checks []AuditCheck `json:"checks"`
+}
+
+// Failed returns the number of failed checks of the subject.
+func (s AuditSubject) Failed() int {
+	failed := 0
+	for _, c := range s.Checks {
+		if c.Status == CheckFailed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// SampleAudit is the report of the deep verification of a random sample of
+// the members and teams of the configuration against GitHub. It is signed
+// with a shared secret so that it can serve as audit evidence.
+type SampleAudit struct {
+	Organization string    `json:"organization"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// Seed is the seed of the random sample, to reproduce it.
+	Seed      int64          `json:"seed"`
+	Members   []AuditSubject `json:"members"`
+	Teams     []AuditSubject `json:"teams"`
+	Signature string         `json:"signature,omitempty"`
+}
+
+// Failed returns the number of failed checks of the audit.
+func (a *SampleAudit) Failed() int {
+	failed := 0
+	for _, s := range append(append([]AuditSubject(nil), a.Members...), a.Teams...) {
+		failed += s.Failed()
+	}
+	return failed
+}
+
+// RedactIdentities omits the SAML SSO identities of the members from the
+// details of their checks.
+func (a *SampleAudit) RedactIdentities() {
+	for _, s := range a.Members {
+		for i, c := range s.Checks {
+			if c.Name == "sso identity" && c.Status != CheckUnknown {
+				s.Checks[i].Detail = "[redacted]"
+			}
+		}
+	}
+}
+
+// MemberWeights returns the weights of the members of cfg in audit samples:
+// members with more privileges, i.e. owners, maintainers and members of many
+// teams, are more likely to be sampled.
+func MemberWeights(cfg *config.Config) map[string]int {
+	weights := make(map[string]int, len(cfg.Members))
+	for login := range cfg.Members {
+		weights[login] = 1
+	}
+	for _, teamCfg := range cfg.Teams {
+		for _, login := range teamCfg.Members {
+			weights[login]++
+		}
+		for _, login := range teamCfg.Maintainers {
+			weights[login] += 2
+		}
+	}
+	for _, login := range cfg.Owners {
+		weights[login] += 5
+	}
+	return weights
+}
+
+// TeamWeights returns the weights of the teams of cfg in audit samples: teams
+// with more members and repositories are more likely to be sampled.
+func TeamWeights(cfg *config.Config) map[string]int {
+	weights := make(map[string]int, len(cfg.Teams))
+	for teamName, teamCfg := range cfg.Teams {
+		weights[teamName] = 1 + len(teamCfg.Members) + len(teamCfg.Repositories)
+	}
+	return weights
+}
+
+// WeightedSample returns up to n of the keys of weights, sorted, picked at
+// random without replacement with a probability proportional to their weight.
+func WeightedSample(weights map[string]int, n int, rng *rand.Rand) []string {
+	type keyed struct {
+		name string
+		key  float64
+	}
+	var keys []keyed
+	for _, name := range sortedKeys(weights) {
+		if w := weights[name]; w > 0 {
+			keys = append(keys, keyed{name: name, key: math.Pow(rng.Float64(), 1/float64(w))})
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].key > keys[j].key
+	})
+	if n > len(keys) {
+		n = len(keys)
+	}
+	sample := make([]string, 0, n)
+	for _, k := range keys[:n] {
+		sample = append(sample, k.name)
+	}
+	sort.Strings(sample)
+	return sample
+}
+
+// AuditSample verifies the given members and teams of cfg against GitHub:
+// the organization and team memberships and roles, the SAML SSO identity, the
+// two-factor authentication and the last activity of the members, considered
+// inactive after inactiveDays, and the members and maintainers of the teams.
+// The checks that can't be performed, e.g. because the organization has no
+// SAML identity provider, are reported as unknown.
+func (tm *Manager) AuditSample(ctx context.Context, cfg *config.Config, members, teams []string, inactiveDays int, now time.Time) (*SampleAudit, error) {
+	upstreamCfg, err := tm.GetCurrentConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config from GitHub: %w", err)
+	}
+	orgMembers, err := tm.ListOrgMembers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	roles := make(map[string]string, len(orgMembers))
+	for _, m := range orgMembers {
+		roles[strings.ToLower(m.Login)] = m.Role
+	}
+	without2FA, twoFactorErr := tm.ListMembersWithout2FA(ctx)
+	identities, ssoErr := tm.ListSSOIdentities(ctx)
+
+	audit := &SampleAudit{Organization: tm.owner, CreatedAt: now.UTC()}
+	memberWeights, teamWeights := MemberWeights(cfg), TeamWeights(cfg)
+	for _, login := range members {
+		tm.reporter.Progress("Auditing member %s", login)
+		subject := AuditSubject{Name: login, Weight: memberWeights[login]}
+		role, isMember := roles[strings.ToLower(login)]
+		subject.Checks = append(subject.Checks,
+			checkMembership(cfg, upstreamCfg, login, isMember),
+			checkRoles(cfg, upstreamCfg, login, role),
+			checkSSOIdentity(cfg, login, identities, ssoErr),
+			checkTwoFactor(login, without2FA, twoFactorErr),
+		)
+		last, err := tm.LastActivity(ctx, login)
+		subject.Checks = append(subject.Checks, checkActivity(last, err, inactiveDays, now))
+		audit.Members = append(audit.Members, subject)
+	}
+	for _, teamName := range teams {
+		tm.reporter.Progress("Auditing team %s", teamName)
+		subject := AuditSubject{Name: teamName, Weight: teamWeights[teamName]}
+		subject.Checks = checkTeam(cfg.Teams[teamName], upstreamCfg.Teams[teamName], upstreamCfg.Teams[teamName].ID != "")
+		audit.Teams = append(audit.Teams, subject)
+	}
+	return audit, nil
+}
+
+func checkMembership(cfg, upstreamCfg *config.Config, login string, isMember bool) AuditCheck {
+	check := AuditCheck{Name: "membership", Status: CheckPassed}
+	if !isMember {
+		check.Status, check.Detail = CheckFailed, "not a member of the organization"
+		return check
+	}
+	var expected, actual []string
+	for teamName, teamCfg := range cfg.Teams {
+		if set.ContainsFold(teamCfg.Members, login) {
+			expected = append(expected, teamName)
+		}
+	}
+	for teamName, teamCfg := range upstreamCfg.Teams {
+		if _, managed := cfg.Teams[teamName]; managed && set.ContainsFold(teamCfg.Members, login) {
+			actual = append(actual, teamName)
+		}
+	}
+	var problems []string
+	if missing := set.Difference(expected, actual); len(missing) != 0 {
+		sort.Strings(missing)
+		problems = append(problems, "missing from teams "+strings.Join(missing, ", "))
+	}
+	if unexpected := set.Difference(actual, expected); len(unexpected) != 0 {
+		sort.Strings(unexpected)
+		problems = append(problems, "unexpected member of teams "+strings.Join(unexpected, ", "))
+	}
+	if len(problems) != 0 {
+		check.Status, check.Detail = CheckFailed, strings.Join(problems, "; ")
+	} else {
+		check.Detail = fmt.Sprintf("member of %d teams", len(expected))
+	}
+	return check
+}
+
+func checkRoles(cfg, upstreamCfg *config.Config, login, orgRole string) AuditCheck {
+	check := AuditCheck{Name: "role", Status: CheckPassed, Detail: "member of the organization"}
+	if orgRole == "ADMIN" {
+		check.Detail = "owner of the organization"
+	}
+	var problems []string
+	if len(cfg.Owners) != 0 && set.ContainsFold(cfg.Owners, login) != (orgRole == "ADMIN") {
+		if orgRole == "ADMIN" {
+			problems = append(problems, "owner of the organization but not in owners")
+		} else {
+			problems = append(problems, "in owners but not an owner of the organization")
+		}
+	}
+	for _, teamName := range sortedKeys(cfg.Teams) {
+		teamCfg := cfg.Teams[teamName]
+		// The roles of teams without maintainers aren't managed.
+		if len(teamCfg.Maintainers) == 0 || !set.ContainsFold(teamCfg.Members, login) {
+			continue
+		}
+		expected := set.ContainsFold(teamCfg.Maintainers, login)
+		if actual := set.ContainsFold(upstreamCfg.Teams[teamName].Maintainers, login); actual != expected {
+			if actual {
+				problems = append(problems, "unexpected maintainer of team "+teamName)
+			} else {
+				problems = append(problems, "not a maintainer of team "+teamName)
+			}
+		}
+	}
+	if len(problems) != 0 {
+		check.Status, check.Detail = CheckFailed, strings.Join(problems, "; ")
+	}
+	return check
+}
+
+func checkSSOIdentity(cfg *config.Config, login string, identities []SSOIdentity, err error) AuditCheck {
+	check := AuditCheck{Name: "sso identity"}
+	if err != nil {
+		check.Status, check.Detail = CheckUnknown, github.TranslateError(err).Error()
+		return check
+	}
+	for _, identity := range identities {
+		if !strings.EqualFold(identity.Login, login) {
+			continue
+		}
+		if stored := cfg.Members[login].Metadata[MetadataSSONameID]; stored != "" && stored != identity.NameID {
+			check.Status, check.Detail = CheckFailed, fmt.Sprintf("linked to %s instead of %s", identity.NameID, stored)
+			return check
+		}
+		check.Status, check.Detail = CheckPassed, "linked to "+identity.NameID
+		return check
+	}
+	if cfg.Members[login].Bot {
+		check.Status, check.Detail = CheckUnknown, "bot without linked identity"
+		return check
+	}
+	check.Status, check.Detail = CheckFailed, "no linked identity"
+	return check
+}
+
+func checkTwoFactor(login string, without2FA []string, err error) AuditCheck {
+	check := AuditCheck{Name: "2fa", Status: CheckPassed, Detail: "enabled"}
+	switch {
+	case err != nil:
+		check.Status, check.Detail = CheckUnknown, github.TranslateError(err).Error()
+	case set.ContainsFold(without2FA, login):
+		check.Status, check.Detail = CheckFailed, "disabled"
+	}
+	return check
+}
+
+func checkActivity(last time.Time, err error, inactiveDays int, now time.Time) AuditCheck {
+	check := AuditCheck{Name: "activity", Status: CheckPassed}
+	switch {
+	case err != nil:
+		check.Status, check.Detail = CheckUnknown, github.TranslateError(err).Error()
+	case last.IsZero():
+		check.Status, check.Detail = CheckFailed, "no activity found"
+	default:
+		check.Detail = "last active " + last.UTC().Format(config.DateFormat)
+		if last.Before(now.AddDate(0, 0, -inactiveDays)) {
+			check.Status = CheckFailed
+			check.Detail += fmt.Sprintf(", inactive for more than %d days", inactiveDays)
+		}
+	}
+	return check
+}
+
+func checkTeam(teamCfg, upstreamTeam config.TeamConfig, exists bool) []AuditCheck {
+	if !exists {
+		return []AuditCheck{{Name: "members", Status: CheckFailed, Detail: "team missing in GitHub"}}
+	}
+	check := func(name string, expected, actual []string) AuditCheck {
+		var problems []string
+		if missing := set.DifferenceFold(expected, actual); len(missing) != 0 {
+			sort.Strings(missing)
+			problems = append(problems, "missing "+strings.Join(missing, ", "))
+		}
+		if unexpected := set.DifferenceFold(actual, expected); len(unexpected) != 0 {
+			sort.Strings(unexpected)
+			problems = append(problems, "unexpected "+strings.Join(unexpected, ", "))
+		}
+		if len(problems) != 0 {
+			return AuditCheck{Name: name, Status: CheckFailed, Detail: strings.Join(problems, "; ")}
+		}
+		return AuditCheck{Name: name, Status: CheckPassed, Detail: fmt.Sprintf("%d as configured", len(expected))}
+	}
+	checks := []AuditCheck{check("members", teamCfg.Members, upstreamTeam.Members)}
+	if len(teamCfg.Maintainers) != 0 {
+		checks = append(checks, check("maintainers", teamCfg.Maintainers, upstreamTeam.Maintainers))
+	}
+	return checks
+}
+
+// Sign signs the audit with the given secret.
+func (a *SampleAudit) Sign(secret []byte) error {
+	mac, err := a.mac(secret)
+	if err != nil {
+		return err
+	}
+	a.Signature = hex.EncodeToString(mac)
+	return nil
+}
+
+// Verify returns an error if the audit was not signed with the given secret
+// or was modified since it was signed.
+func (a *SampleAudit) Verify(secret []byte) error {
+	if a.Signature == "" {
+		return errors.New("audit is not signed")
+	}
+	signature, err := hex.DecodeString(a.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid audit signature: %w", err)
+	}
+	mac, err := a.mac(secret)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(signature, mac) {
+		return errors.New("audit signature does not match, the audit was modified or signed with a different secret")
+	}
+	return nil
+}
+
+// mac returns the HMAC-SHA256 of the audit without its signature.
+func (a *SampleAudit) mac(secret []byte) ([]byte, error) {
+	unsigned := *a
+	unsigned.Signature = ""
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, secret)
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// StoreSampleAudit stores the given audit into file.
+func StoreSampleAudit(file string, audit *SampleAudit) error {
+	data, err := json.MarshalIndent(audit, "", "  ")
+	if err != nil {
+		return err
+	}
+	return renameio.WriteFile(file, append(data, '\n'), 0o644)
+}
+
+// LoadSampleAudit loads the audit stored in file.
+func LoadSampleAudit(file string) (*SampleAudit, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	audit := &SampleAudit{}
+	if err = json.Unmarshal(data, audit); err != nil {
+		return nil, fmt.Errorf("failed to parse audit: %w", err)
+	}
+	return audit, nil
+}
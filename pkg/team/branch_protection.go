@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// BranchProtectionIssue is a team reference of a branch protection rule that
+// is inconsistent with the local config.
+type BranchProtectionIssue struct {
+	Repository string
+	Branch     string
+	// Rule is the part of the branch protection rule referencing the team.
+	Rule string
+	// Team is the slug of the referenced team.
+	Team    string
+	Problem string
+}
+
+func (i BranchProtectionIssue) String() string {
+	return fmt.Sprintf("%s@%s: %s references team %q which %s", i.Repository, i.Branch, i.Rule, i.Team, i.Problem)
+}
+
+// ListRepositories returns the names of all non-archived repositories of the
+// organization.
+func (tm *Manager) ListRepositories(ctx context.Context) ([]string, error) {
+	repos, err := tm.listActiveRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		names = append(names, repo.GetName())
+	}
+	return names, nil
+}
+
+// listActiveRepositories returns all non-archived repositories of the
+// organization.
+func (tm *Manager) listActiveRepositories(ctx context.Context) ([]*gh.Repository, error) {
+	opts := &gh.RepositoryListByOrgOptions{
+		ListOptions: gh.ListOptions{PerPage: 100},
+	}
+	var repos []*gh.Repository
+	for {
+		page, resp, err := tm.ghClient.Repositories.ListByOrg(ctx, tm.owner, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range page {
+			if !repo.GetArchived() {
+				repos = append(repos, repo)
+			}
+		}
+		if resp.NextPage == 0 {
+			return repos, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// CheckBranchProtections checks that all teams referenced by the branch
+// protection rules of the protected branches of the given repositories exist
+// in the local config and are not empty.
+func (tm *Manager) CheckBranchProtections(ctx context.Context, cfg *config.Config, repos []string) ([]BranchProtectionIssue, error) {
+	teamsBySlug := make(map[string]config.TeamConfig, len(cfg.Teams))
+	for teamName, teamCfg := range cfg.Teams {
+		teamsBySlug[TeamSlug(cfg, teamName)] = teamCfg
+	}
+
+	var issues []BranchProtectionIssue
+	for _, repo := range repos {
+		branches, err := tm.listProtectedBranches(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list protected branches of %q: %w", repo, err)
+		}
+		for _, branch := range branches {
+			protection, resp, err := tm.getBranchProtection(ctx, repo, branch)
+			if err != nil {
+				if resp != nil && resp.StatusCode == http.StatusNotFound {
+					// Branch is protected by rulesets only.
+					continue
+				}
+				return nil, fmt.Errorf("failed to get branch protection of %s@%s: %w", repo, branch, err)
+			}
+			issues = append(issues, branchProtectionIssues(teamsBySlug, repo, branch, protection)...)
+		}
+	}
+	return issues, nil
+}
+
+// listProtectedBranches returns the names of the protected branches of the
+// given repository.
+func (tm *Manager) listProtectedBranches(ctx context.Context, repo string) ([]string, error) {
+	opts := &gh.BranchListOptions{
+		Protected:   gh.Bool(true),
+		ListOptions: gh.ListOptions{PerPage: 100},
+	}
+	var branches []string
+	for {
+		page, resp, err := tm.ghClient.Repositories.ListBranches(ctx, tm.owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range page {
+			branches = append(branches, b.GetName())
+		}
+		if resp.NextPage == 0 {
+			return branches, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// branchProtectionIssues returns the issues of the teams referenced by the
+// branch protection rule of the given branch.
+func branchProtectionIssues(teamsBySlug map[string]config.TeamConfig, repo, branch string, protection *branchProtection) []BranchProtectionIssue {
+	var issues []BranchProtectionIssue
+	for _, ref := range branchProtectionTeamRefs(protection) {
+		for _, t := range ref.teams {
+			issue := BranchProtectionIssue{
+				Repository: repo,
+				Branch:     branch,
+				Rule:       ref.rule,
+				Team:       t.GetSlug(),
+			}
+			teamCfg, ok := teamsBySlug[t.GetSlug()]
+			switch {
+			case !ok:
+				issue.Problem = "does not exist in the local config"
+			case len(teamCfg.Members) == 0:
+				issue.Problem = "has no members"
+			default:
+				continue
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// branchProtection is the part of the branch protection of a branch that
+// references teams. It is decoded by hand as the gh.Protection of go-github
+// lacks the bypass allowances of the required pull request reviews.
+type branchProtection struct {
+	RequiredPullRequestReviews *struct {
+		DismissalRestrictions       *branchProtectionTeams `json:"dismissal_restrictions"`
+		BypassPullRequestAllowances *branchProtectionTeams `json:"bypass_pull_request_allowances"`
+	} `json:"required_pull_request_reviews"`
+	Restrictions *branchProtectionTeams `json:"restrictions"`
+}
+
+// branchProtectionTeams are the teams allowed by a part of a branch protection
+// rule.
+type branchProtectionTeams struct {
+	Teams []*gh.Team `json:"teams"`
+}
+
+// getBranchProtection returns the branch protection of the given branch.
+func (tm *Manager) getBranchProtection(ctx context.Context, repo, branch string) (*branchProtection, *gh.Response, error) {
+	req, err := tm.ghClient.NewRequest("GET", fmt.Sprintf("repos/%s/%s/branches/%s/protection", tm.owner, repo, url.PathEscape(branch)), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	protection := &branchProtection{}
+	resp, err := tm.ghClient.Do(ctx, req, protection)
+	if err != nil {
+		return nil, resp, err
+	}
+	return protection, resp, nil
+}
+
+// branchProtectionTeamRef are the teams referenced by a part of a branch
+// protection rule.
+type branchProtectionTeamRef struct {
+	rule  string
+	teams []*gh.Team
+}
+
+// branchProtectionTeamRefs returns the teams referenced by the given branch
+// protection rule: the teams allowed to dismiss or to bypass the required pull
+// request reviews and the teams allowed to push. Required reviewers aren't
+// part of branch protection rules but of the CODEOWNERS files.
+func branchProtectionTeamRefs(protection *branchProtection) []branchProtectionTeamRef {
+	var refs []branchProtectionTeamRef
+	if rpr := protection.RequiredPullRequestReviews; rpr != nil {
+		if rpr.DismissalRestrictions != nil {
+			refs = append(refs, branchProtectionTeamRef{"review dismissal restriction", rpr.DismissalRestrictions.Teams})
+		}
+		if rpr.BypassPullRequestAllowances != nil {
+			refs = append(refs, branchProtectionTeamRef{"review bypass allowance", rpr.BypassPullRequestAllowances.Teams})
+		}
+	}
+	if protection.Restrictions != nil {
+		refs = append(refs, branchProtectionTeamRef{"push restriction", protection.Restrictions.Teams})
+	}
+	return refs
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// RotateReviewCapacity returns the configuration to push to GitHub for the
+// given configuration, approximating the review capacity of its members: as
+// GitHub's code review assignment can't weight members, members with a
+// capacity below 100% are excluded from the code review assignment of their
+// teams on the days they are rotated out, so that they are assigned reviews
+// on roughly their capacity's share of days. cfg itself is left untouched.
+func RotateReviewCapacity(cfg *config.Config, now time.Time) *config.Config {
+	rotatedOut := map[string]struct{}{}
+	for login, user := range cfg.Members {
+		if !reviewCapacityAvailable(login, user.ReviewCapacity, now) {
+			rotatedOut[login] = struct{}{}
+		}
+	}
+	if len(rotatedOut) == 0 {
+		return cfg
+	}
+
+	effectiveCfg := *cfg
+	effectiveCfg.Teams = make(map[string]config.TeamConfig, len(cfg.Teams))
+	for teamName, teamCfg := range cfg.Teams {
+		excluded := map[string]struct{}{}
+		for _, xMember := range teamCfg.CodeReviewAssignment.ExcludedMembers {
+			excluded[xMember.Login] = struct{}{}
+		}
+		var rotated []config.ExcludedMember
+		for _, login := range teamCfg.Members {
+			if _, ok := rotatedOut[login]; !ok {
+				continue
+			}
+			if _, ok := excluded[login]; ok {
+				continue
+			}
+			rotated = append(rotated, config.ExcludedMember{
+				Login:  login,
+				Reason: fmt.Sprintf("review capacity %d%%, rotated out today", cfg.Members[login].ReviewCapacity),
+				Since:  now.Format(config.DateFormat),
+			})
+		}
+		if len(rotated) != 0 {
+			teamCfg.CodeReviewAssignment.ExcludedMembers = append(append([]config.ExcludedMember(nil), teamCfg.CodeReviewAssignment.ExcludedMembers...), rotated...)
+		}
+		effectiveCfg.Teams[teamName] = teamCfg
+	}
+	return &effectiveCfg
+}
+
+// HasReducedReviewCapacity returns true if any member of cfg has a review
+// capacity below 100%.
+func HasReducedReviewCapacity(cfg *config.Config) bool {
+	for _, user := range cfg.Members {
+		if user.ReviewCapacity != 0 && user.ReviewCapacity < 100 {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintReviewCapacity prints the members with a reduced review capacity and
+// whether they are rotated out of the code review assignments at the given
+// time.
+func PrintReviewCapacity(w io.Writer, cfg *config.Config, now time.Time) {
+	for _, login := range sortedKeys(cfg.Members) {
+		capacity := cfg.Members[login].ReviewCapacity
+		if capacity == 0 || capacity >= 100 {
+			continue
+		}
+		state := "assigned reviews"
+		if !reviewCapacityAvailable(login, capacity, now) {
+			state = "rotated out"
+		}
+		fmt.Fprintf(w, " Member: %s, review capacity %d%%, %s today\n", login, capacity, state)
+	}
+}
+
+// reviewCapacityAvailable returns true if a member with the given review
+// capacity is assigned reviews on the day of now. Days are rotated such that
+// the member is assigned reviews on capacity percent of days, e.g. every other
+// day for 50%. The rotation is offset per member so that members with the
+// same capacity aren't all rotated out on the same days.
+func reviewCapacityAvailable(login string, capacity int, now time.Time) bool {
+	if capacity == 0 || capacity >= 100 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(login))
+	day := int(now.Unix()/(24*60*60)) + int(h.Sum32()%100)
+	return (day+1)*capacity/100 > day*capacity/100
+}
@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// GenerateCodeOwners returns the CODEOWNERS file assigning the owned paths of
+// the teams of cfg to these teams of the organization org. Teams owning the
+// same path are listed on the same line. As the last matching pattern takes
+// precedence, the patterns matching all files, e.g. * or /, come first, and
+// the others are sorted so that the ones nested into others, e.g. /bpf/lib/
+// into /bpf/, come after them.
+func GenerateCodeOwners(cfg *config.Config, org string) string {
+	owners := map[string]set.Set[string]{}
+	for teamName, teamCfg := range cfg.Teams {
+		for _, path := range teamCfg.OwnedPaths {
+			if owners[path] == nil {
+				owners[path] = set.New[string]()
+			}
+			owners[path].Add("@" + org + "/" + TeamSlug(cfg, teamName))
+		}
+	}
+	paths := make([]string, 0, len(owners))
+	for path := range owners {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if catchAll(paths[i]) != catchAll(paths[j]) {
+			return catchAll(paths[i])
+		}
+		return paths[i] < paths[j]
+	})
+
+	var sb strings.Builder
+	sb.WriteString("# Generated by team-manager from the ownedPaths of the teams, do not edit.\n")
+	for _, path := range paths {
+		fmt.Fprintf(&sb, "%s %s\n", path, strings.Join(owners[path].Elements(), " "))
+	}
+	return sb.String()
+}
+
+// catchAll returns true if the given CODEOWNERS pattern matches all files.
+func catchAll(pattern string) bool {
+	switch pattern {
+	case "*", "/", "/*", "**", "/**", "/**/*":
+		return true
+	}
+	return false
+}
+
+// CodeOwnersIssue is an owner of a CODEOWNERS file that GitHub can't request
+// reviews from.
+type CodeOwnersIssue struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Owner   string `json:"owner"`
+	Problem string `json:"problem"`
+}
+
+func (i CodeOwnersIssue) String() string {
+	return fmt.Sprintf("%s:%d: %s %s", i.File, i.Line, i.Owner, i.Problem)
+}
+
+// CheckCodeOwners resolves the @org/team and @user owners of the given
+// CODEOWNERS files, mapped by file name, against cfg, and against GitHub for
+// the ones missing in cfg. It returns the unknown and empty teams and the
+// users that aren't members of the organization, sorted by file and line. If
+// repo isn't empty, the teams without write permission on the repository
+// containing the files, which GitHub ignores, are returned as well. Owners
+// referenced by email address aren't checked.
+func (tm *Manager) CheckCodeOwners(ctx context.Context, cfg *config.Config, repo string, files map[string]string) ([]CodeOwnersIssue, error) {
+	problems := map[string]string{}
+	var issues []CodeOwnersIssue
+	for _, file := range sortedKeys(files) {
+		scanner := bufio.NewScanner(strings.NewReader(files[file]))
+		for n := 1; scanner.Scan(); n++ {
+			line, _, _ := strings.Cut(scanner.Text(), "#")
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			for _, owner := range fields[1:] {
+				problem, ok := problems[strings.ToLower(owner)]
+				if !ok {
+					var err error
+					problem, err = tm.checkCodeOwner(ctx, cfg, repo, owner)
+					if err != nil {
+						return nil, err
+					}
+					problems[strings.ToLower(owner)] = problem
+				}
+				if problem != "" {
+					issues = append(issues, CodeOwnersIssue{File: file, Line: n, Owner: owner, Problem: problem})
+				}
+			}
+		}
+	}
+	return issues, nil
+}
+
+// checkCodeOwner returns why GitHub can't request reviews from the given
+// owner on repo, or an empty string if it can.
+func (tm *Manager) checkCodeOwner(ctx context.Context, cfg *config.Config, repo, owner string) (string, error) {
+	if !strings.HasPrefix(owner, "@") {
+		// Email address.
+		return "", nil
+	}
+	org, slug, isTeam := strings.Cut(owner[1:], "/")
+	if !isTeam {
+		for login := range cfg.Members {
+			if strings.EqualFold(login, org) {
+				return "", nil
+			}
+		}
+		member, _, err := tm.ghClient.Organizations.IsMember(ctx, tm.owner, org)
+		if err != nil {
+			return "", fmt.Errorf("failed to check membership of %s: %w", org, err)
+		}
+		if !member {
+			return fmt.Sprintf("is not a member of organization %s", tm.owner), nil
+		}
+		return "", nil
+	}
+
+	if !strings.EqualFold(org, tm.owner) {
+		return fmt.Sprintf("is a team of another organization than %s", tm.owner), nil
+	}
+	for teamName, teamCfg := range cfg.Teams {
+		if strings.EqualFold(TeamSlug(cfg, teamName), slug) {
+			if len(teamCfg.Members) == 0 {
+				return "is an empty team", nil
+			}
+			return tm.checkCodeOwnerAccess(ctx, slug, repo)
+		}
+	}
+	t, resp, err := tm.ghClient.Teams.GetTeamBySlug(ctx, tm.owner, slug)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "is an unknown team", nil
+		}
+		return "", fmt.Errorf("failed to get team %s: %w", slug, err)
+	}
+	if t.GetMembersCount() == 0 {
+		return "is an empty team", nil
+	}
+	return tm.checkCodeOwnerAccess(ctx, slug, repo)
+}
+
+// checkCodeOwnerAccess returns why GitHub ignores the team with the given slug
+// as owner on repo because of its permission, or an empty string if the team
+// has at least write permission or repo is empty.
+func (tm *Manager) checkCodeOwnerAccess(ctx context.Context, slug, repo string) (string, error) {
+	if repo == "" {
+		return "", nil
+	}
+	r, resp, err := tm.ghClient.Teams.IsTeamRepoBySlug(ctx, tm.owner, slug, tm.owner, repo)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return fmt.Sprintf("has no access to repository %s, write permission is required", repo), nil
+		}
+		return "", fmt.Errorf("failed to get permission of team %s on repository %s: %w", slug, repo, err)
+	}
+	if perm := highestPermission(r.GetPermissions()); permissionRank(perm) < permissionRank(config.RepositoryPermissionPush) {
+		return fmt.Sprintf("has %s permission on repository %s, write permission is required", perm, repo), nil
+	}
+	return "", nil
+}
+
+// FindCodeOwnersTeams returns the slugs of the teams of the organization
+// referenced by the CODEOWNERS files of the default branch of the given
+// repositories, sorted.
+func (tm *Manager) FindCodeOwnersTeams(ctx context.Context, repos []string) ([]string, error) {
+	prefix := strings.ToLower("@" + tm.owner + "/")
+	slugs := set.New[string]()
+	for _, repo := range repos {
+		for _, path := range CodeOwnersPaths {
+			content, err := tm.GetFileContent(ctx, repo, path)
+			if err != nil {
+				return nil, err
+			}
+			scanner := bufio.NewScanner(strings.NewReader(content))
+			for scanner.Scan() {
+				line, _, _ := strings.Cut(scanner.Text(), "#")
+				fields := strings.Fields(line)
+				if len(fields) < 2 {
+					continue
+				}
+				for _, owner := range fields[1:] {
+					if strings.HasPrefix(strings.ToLower(owner), prefix) {
+						slugs.Add(strings.ToLower(owner[len(prefix):]))
+					}
+				}
+			}
+		}
+	}
+	return slugs.Elements(), nil
+}
+
+// ImportTeams adds the teams of upstreamCfg with the given slugs that are
+// missing in cfg to cfg, together with their members. It returns the names of
+// the imported teams and the slugs of the teams missing in upstreamCfg,
+// sorted.
+func ImportTeams(cfg, upstreamCfg *config.Config, slugs []string) (imported, unknown []string) {
+	known := set.New[string]()
+	for teamName := range cfg.Teams {
+		known.Add(strings.ToLower(TeamSlug(cfg, teamName)))
+	}
+	upstreamNames := map[string]string{}
+	for teamName := range upstreamCfg.Teams {
+		upstreamNames[strings.ToLower(TeamSlug(upstreamCfg, teamName))] = teamName
+	}
+
+	for _, slug := range slugs {
+		if known.Has(strings.ToLower(slug)) {
+			continue
+		}
+		teamName, ok := upstreamNames[strings.ToLower(slug)]
+		if !ok {
+			unknown = append(unknown, slug)
+			continue
+		}
+		if _, ok := cfg.Teams[teamName]; ok {
+			// A local team with the same name but another slug, the
+			// renames are resolved by push.
+			continue
+		}
+		if cfg.Teams == nil {
+			cfg.Teams = map[string]config.TeamConfig{}
+		}
+		if cfg.Members == nil {
+			cfg.Members = map[string]config.User{}
+		}
+		teamCfg := upstreamCfg.Teams[teamName]
+		cfg.Teams[teamName] = teamCfg
+		for _, login := range teamCfg.Members {
+			if _, ok := cfg.Members[login]; !ok {
+				cfg.Members[login] = upstreamCfg.Members[login]
+			}
+		}
+		imported = append(imported, teamName)
+	}
+	sort.Strings(imported)
+	return imported, unknown
+}
@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CodeOwnersRule is a line of a CODEOWNERS file, assigning the paths matching
+// its pattern to its owners.
+type CodeOwnersRule struct {
+	Pattern string
+	Owners  []string
+
+	re *regexp.Regexp
+}
+
+// Match returns true if the pattern of the rule matches the given path,
+// relative to the root of the repository.
+func (r CodeOwnersRule) Match(file string) bool {
+	return r.re.MatchString(file)
+}
+
+// ParseCodeOwners returns the rules of the given CODEOWNERS file, in the order
+// of the file.
+func ParseCodeOwners(content string) ([]CodeOwnersRule, error) {
+	var rules []CodeOwnersRule
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		re, err := codeOwnersPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q on line %d: %w", fields[0], n, err)
+		}
+		rules = append(rules, CodeOwnersRule{Pattern: fields[0], Owners: fields[1:], re: re})
+	}
+	return rules, scanner.Err()
+}
+
+// codeOwnersPattern returns the regular expression of the given CODEOWNERS
+// pattern, following the gitignore rules as GitHub does: patterns without a
+// slash but a trailing one match at any depth, patterns ending with a slash
+// only match directories, and matching a directory matches all the files
+// below it, except for patterns ending with a single * that only match the
+// files of their directory.
+func codeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/") && (i == 0 || p[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.HasSuffix(p, "*") && !strings.HasSuffix(p, "**"):
+		b.WriteString("$")
+	default:
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
+
+// CodeOwners returns the owners of the given path according to rules, the
+// ones of the last matching rule, or nil if no rule matches it.
+func CodeOwners(rules []CodeOwnersRule, file string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Match(file) {
+			return rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// OwnerCoverage is the number of paths a team owns.
+type OwnerCoverage struct {
+	Owner string `json:"owner"`
+	Paths int    `json:"paths"`
+}
+
+// CodeOwnersCoverage is the coverage of the files of a repository by its
+// CODEOWNERS file.
+type CodeOwnersCoverage struct {
+	Files int `json:"files"`
+	// Unowned are the files without owner, collapsed into their directory
+	// when none of the files below it have an owner.
+	Unowned []string `json:"unowned"`
+	// UnownedFiles is the number of files without owner.
+	UnownedFiles int `json:"unownedFiles"`
+	// Teams are the teams owning paths, sorted by decreasing number of paths.
+	Teams []OwnerCoverage `json:"teams"`
+}
+
+// ComputeCodeOwnersCoverage returns the coverage of the given files by rules.
+// Files matched by a rule without owners have no owner.
+func ComputeCodeOwnersCoverage(rules []CodeOwnersRule, files []string) CodeOwnersCoverage {
+	coverage := CodeOwnersCoverage{Files: len(files), Unowned: []string{}, Teams: []OwnerCoverage{}}
+	teamPaths := map[string]int{}
+	// total and unowned count the files below every directory.
+	total, unowned := map[string]int{}, map[string]int{}
+	var unownedFiles []string
+	for _, file := range files {
+		owners := CodeOwners(rules, file)
+		for _, owner := range owners {
+			if strings.Contains(owner, "/") {
+				teamPaths[strings.ToLower(owner)]++
+			}
+		}
+		for dir := path.Dir(file); dir != "."; dir = path.Dir(dir) {
+			total[dir]++
+			if len(owners) == 0 {
+				unowned[dir]++
+			}
+		}
+		if len(owners) == 0 {
+			unownedFiles = append(unownedFiles, file)
+		}
+	}
+
+	coverage.UnownedFiles = len(unownedFiles)
+	seen := map[string]bool{}
+	for _, file := range unownedFiles {
+		collapsed := file
+		for dir := path.Dir(file); dir != "." && unowned[dir] == total[dir]; dir = path.Dir(dir) {
+			collapsed = dir + "/"
+		}
+		if !seen[collapsed] {
+			seen[collapsed] = true
+			coverage.Unowned = append(coverage.Unowned, collapsed)
+		}
+	}
+	sort.Strings(coverage.Unowned)
+
+	for owner, n := range teamPaths {
+		coverage.Teams = append(coverage.Teams, OwnerCoverage{Owner: owner, Paths: n})
+	}
+	sort.Slice(coverage.Teams, func(i, j int) bool {
+		if coverage.Teams[i].Paths != coverage.Teams[j].Paths {
+			return coverage.Teams[i].Paths > coverage.Teams[j].Paths
+		}
+		return coverage.Teams[i].Owner < coverage.Teams[j].Owner
+	})
+	return coverage
+}
+
+// GetCodeOwnersTree returns the content of the CODEOWNERS file GitHub uses for
+// the default branch of the given repository, the first one found in
+// CodeOwnersPaths, and the paths of the files of that branch. truncated is
+// true if the repository has too many files for GitHub to list them all.
+func (tm *Manager) GetCodeOwnersTree(ctx context.Context, repo string) (content string, files []string, truncated bool, err error) {
+	r, _, err := tm.ghClient.Repositories.Get(ctx, tm.owner, repo)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to get repository %q: %w", repo, err)
+	}
+	branch := r.GetDefaultBranch()
+	if content, err = tm.GetCodeOwners(ctx, repo, branch); err != nil {
+		return "", nil, false, err
+	}
+
+	tree, _, err := tm.ghClient.Git.GetTree(ctx, tm.owner, repo, branch, true)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to get tree of %s@%s: %w", repo, branch, err)
+	}
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			files = append(files, entry.GetPath())
+		}
+	}
+	return content, files, tree.GetTruncated(), nil
+}
@@ -0,0 +1,344 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/set"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// OutsideCollaborator is an outside collaborator of a repository.
+type OutsideCollaborator struct {
+	Perm config.RepositoryPermission `json:"permission"`
+	// Invitation is the ID of the invitation of the collaborator if it
+	// didn't accept it yet.
+	Invitation int64 `json:"invitation,omitempty"`
+}
+
+// CollaboratorChange is an outside collaborator of a repository that is added,
+// removed or whose permission is changed. Perm is empty for removals.
+type CollaboratorChange struct {
+	Repo  string                      `json:"repository"`
+	Login string                      `json:"login"`
+	Perm  config.RepositoryPermission `json:"permission,omitempty"`
+	// Current is the current permission of the collaborator, empty for
+	// additions.
+	Current config.RepositoryPermission `json:"current,omitempty"`
+	// Invitation is the ID of the pending invitation of the collaborator,
+	// if any.
+	Invitation int64 `json:"invitation,omitempty"`
+}
+
+// PrintCollaboratorChanges prints the given changes of outside collaborators.
+func PrintCollaboratorChanges(w io.Writer, changes []CollaboratorChange) {
+	for _, c := range changes {
+		switch {
+		case c.Current == "":
+			fmt.Fprintf(w, " Repository %s: adding %s with %s permission\n", c.Repo, c.Login, c.Perm)
+		case c.Perm == "":
+			fmt.Fprintf(w, " Repository %s: removing %s\n", c.Repo, c.Login)
+		default:
+			fmt.Fprintf(w, " Repository %s: changing permission of %s from %s to %s\n", c.Repo, c.Login, c.Current, c.Perm)
+		}
+	}
+}
+
+// ListOutsideCollaborators returns the outside collaborators of the given
+// repository mapped by login. Invited collaborators that didn't accept their
+// invitation yet are included, with the permission they are invited with.
+func (tm *Manager) ListOutsideCollaborators(ctx context.Context, repo string) (map[string]OutsideCollaborator, error) {
+	collaborators := map[string]OutsideCollaborator{}
+	opts := &gh.ListCollaboratorsOptions{Affiliation: "outside", ListOptions: gh.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := tm.ghClient.Repositories.ListCollaborators(ctx, tm.owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range page {
+			collaborators[u.GetLogin()] = OutsideCollaborator{Perm: highestPermission(u.GetPermissions())}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	listOpts := &gh.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := tm.ghClient.Repositories.ListInvitations(ctx, tm.owner, repo, listOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, inv := range page {
+			collaborators[inv.GetInvitee().GetLogin()] = OutsideCollaborator{
+				Perm:       invitationPermission(inv.GetPermissions()),
+				Invitation: inv.GetID(),
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+	return collaborators, nil
+}
+
+// highestPermission returns the highest of the permissions of a collaborator
+// as returned by GitHub.
+func highestPermission(perms map[string]bool) config.RepositoryPermission {
+	for _, perm := range []config.RepositoryPermission{
+		config.RepositoryPermissionAdmin,
+		config.RepositoryPermissionMaintain,
+		config.RepositoryPermissionPush,
+		config.RepositoryPermissionTriage,
+	} {
+		if perms[string(perm)] {
+			return perm
+		}
+	}
+	return config.RepositoryPermissionPull
+}
+
+// invitationPermission returns the permission of a repository invitation,
+// which GitHub names differently than the permissions of collaborators.
+func invitationPermission(perm string) config.RepositoryPermission {
+	switch perm {
+	case "read":
+		return config.RepositoryPermissionPull
+	case "write":
+		return config.RepositoryPermissionPush
+	}
+	return config.RepositoryPermission(perm)
+}
+
+// invitationPermissions is the inverse of invitationPermission.
+func invitationPermissions(perm config.RepositoryPermission) string {
+	switch perm {
+	case config.RepositoryPermissionPull:
+		return "read"
+	case config.RepositoryPermissionPush:
+		return "write"
+	}
+	return string(perm)
+}
+
+// ComputeCollaboratorChanges returns the changes turning the current outside
+// collaborators of a repository into the desired ones, sorted by login.
+func ComputeCollaboratorChanges(repo string, desired map[string]config.RepositoryPermission, current map[string]OutsideCollaborator) []CollaboratorChange {
+	lowerCurrent := map[string]string{}
+	for login := range current {
+		lowerCurrent[strings.ToLower(login)] = login
+	}
+	var changes []CollaboratorChange
+	for login, perm := range desired {
+		currentLogin, ok := lowerCurrent[strings.ToLower(login)]
+		if !ok {
+			changes = append(changes, CollaboratorChange{Repo: repo, Login: login, Perm: perm})
+			continue
+		}
+		delete(lowerCurrent, strings.ToLower(login))
+		if c := current[currentLogin]; c.Perm != perm {
+			changes = append(changes, CollaboratorChange{Repo: repo, Login: login, Perm: perm, Current: c.Perm, Invitation: c.Invitation})
+		}
+	}
+	for _, login := range lowerCurrent {
+		c := current[login]
+		changes = append(changes, CollaboratorChange{Repo: repo, Login: login, Current: c.Perm, Invitation: c.Invitation})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return strings.ToLower(changes[i].Login) < strings.ToLower(changes[j].Login)
+	})
+	return changes
+}
+
+// syncOutsideCollaborators adds, removes and updates the outside
+// collaborators of the repositories of the given config so that they match
+// it. It returns the number of changes submitted and failed.
+func (tm *Manager) syncOutsideCollaborators(ctx context.Context, cfg map[string]map[string]config.RepositoryPermission, force, dryRun bool) (submitted, failed int, err error) {
+	var changes []CollaboratorChange
+	for _, repo := range sortedKeys(cfg) {
+		current, err := tm.ListOutsideCollaborators(ctx, repo)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to list outside collaborators of repository %s: %w", repo, err)
+		}
+		changes = append(changes, ComputeCollaboratorChanges(repo, cfg[repo], current)...)
+	}
+	if len(changes) == 0 {
+		return 0, 0, nil
+	}
+
+	tm.reporter.Plan(PlanEvent{
+		Title:   "Going to update the following outside collaborators",
+		Changes: changes,
+		Print: func(w io.Writer) {
+			PrintCollaboratorChanges(w, changes)
+		},
+	})
+	yes := force
+	if !force {
+		yes, err = terminal.AskForConfirmation("Continue?")
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if !yes {
+		return 0, 0, nil
+	}
+
+	for _, c := range changes {
+		if dryRun {
+			submitted++
+			continue
+		}
+		switch {
+		case c.Perm == "" && c.Invitation != 0:
+			_, err = tm.ghClient.Repositories.DeleteInvitation(ctx, tm.owner, c.Repo, c.Invitation)
+		case c.Perm == "":
+			_, err = tm.ghClient.Repositories.RemoveCollaborator(ctx, tm.owner, c.Repo, c.Login)
+		case c.Invitation != 0:
+			_, _, err = tm.ghClient.Repositories.UpdateInvitation(ctx, tm.owner, c.Repo, c.Invitation, invitationPermissions(c.Perm))
+		default:
+			// Adding an existing collaborator updates its permission.
+			_, _, err = tm.ghClient.Repositories.AddCollaborator(ctx, tm.owner, c.Repo, c.Login,
+				&gh.RepositoryAddCollaboratorOptions{Permission: string(c.Perm)})
+		}
+		if err != nil {
+			tm.reporter.Error("Unable to update outside collaborator %s of repository %s: %s", c.Login, c.Repo, github.TranslateError(err))
+			failed++
+			continue
+		}
+		submitted++
+	}
+	return submitted, failed, nil
+}
+
+// DirectCollaborator is a user with direct access to a repository that isn't
+// granted by any team of the configuration.
+type DirectCollaborator struct {
+	Repo  string                      `json:"repository"`
+	Login string                      `json:"login"`
+	Perm  config.RepositoryPermission `json:"permission"`
+	// Member is true if the collaborator is a member of the configuration,
+	// false for outside collaborators.
+	Member bool `json:"member"`
+}
+
+// AuditDirectCollaborators returns the users with direct access to the
+// active repositories of the organization who aren't members of any team of
+// cfg with access to these repositories, sorted by repository and login. The
+// outside collaborators of cfg are ignored since they are managed.
+func (tm *Manager) AuditDirectCollaborators(ctx context.Context, cfg *config.Config) ([]DirectCollaborator, error) {
+	repos, err := tm.listActiveRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	var collaborators []DirectCollaborator
+	for _, repo := range repos {
+		teams, err := tm.listRepositoryTeams(ctx, repo.GetName())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list teams of repository %q: %w", repo.GetName(), err)
+		}
+		granted := set.New[string]()
+		for teamName, teamCfg := range cfg.Teams {
+			if teamHasAccess(cfg, teamName, teams) {
+				for _, login := range teamCfg.Members {
+					granted.Add(strings.ToLower(login))
+				}
+			}
+		}
+		for login := range cfg.OutsideCollaborators[repo.GetName()] {
+			granted.Add(strings.ToLower(login))
+		}
+
+		opts := &gh.ListCollaboratorsOptions{Affiliation: "direct", ListOptions: gh.ListOptions{PerPage: 100}}
+		for {
+			page, resp, err := tm.ghClient.Repositories.ListCollaborators(ctx, tm.owner, repo.GetName(), opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list collaborators of repository %q: %w", repo.GetName(), err)
+			}
+			for _, u := range page {
+				if granted.Has(strings.ToLower(u.GetLogin())) {
+					continue
+				}
+				_, member := cfg.Members[u.GetLogin()]
+				collaborators = append(collaborators, DirectCollaborator{
+					Repo:   repo.GetName(),
+					Login:  u.GetLogin(),
+					Perm:   highestPermission(u.GetPermissions()),
+					Member: member,
+				})
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+	sort.Slice(collaborators, func(i, j int) bool {
+		if collaborators[i].Repo != collaborators[j].Repo {
+			return collaborators[i].Repo < collaborators[j].Repo
+		}
+		return strings.ToLower(collaborators[i].Login) < strings.ToLower(collaborators[j].Login)
+	})
+	return collaborators, nil
+}
+
+// teamHasAccess returns true if the given team of cfg or any of its parent
+// teams, whose access it inherits, is among the given slugs.
+func teamHasAccess(cfg *config.Config, teamName string, slugs set.Set[string]) bool {
+	visited := map[string]bool{}
+	for name := teamName; name != "" && !visited[name]; name = cfg.Teams[name].Parent {
+		visited[name] = true
+		if slugs.Has(TeamSlug(cfg, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveDirectCollaborators removes the given collaborators from their
+// repositories, once confirmed.
+func (tm *Manager) RemoveDirectCollaborators(ctx context.Context, collaborators []DirectCollaborator, force bool) error {
+	if len(collaborators) == 0 {
+		return nil
+	}
+	tm.reporter.Plan(PlanEvent{
+		Title:   "Going to remove the following collaborators",
+		Changes: collaborators,
+		Print: func(w io.Writer) {
+			for _, c := range collaborators {
+				fmt.Fprintf(w, " Repository %s: removing %s with %s permission\n", c.Repo, c.Login, c.Perm)
+			}
+		},
+	})
+	yes := force
+	if !force {
+		var err error
+		yes, err = terminal.AskForConfirmation("Continue?")
+		if err != nil {
+			return err
+		}
+	}
+	if !yes {
+		return nil
+	}
+
+	for _, c := range collaborators {
+		if _, err := tm.ghClient.Repositories.RemoveCollaborator(ctx, tm.owner, c.Repo, c.Login); err != nil {
+			tm.reporter.Error("Unable to remove collaborator %s of repository %s: %s", c.Login, c.Repo, github.TranslateError(err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// Drift is a difference between the local and the upstream configuration.
+type Drift struct {
+	Kind     config.DriftKind
+	Severity config.Severity
+	Team     string
+	// Member is set for drifts of team members.
+	Member string
+}
+
+func (d Drift) String() string {
+	if d.Member != "" {
+		return fmt.Sprintf("[%s] %s: team %s, member %s", d.Severity, d.Kind, d.Team, d.Member)
+	}
+	return fmt.Sprintf("[%s] %s: team %s", d.Severity, d.Kind, d.Team)
+}
+
+// Hash returns the hash of the drift, which is stable across runs to tell
+// new drifts from known ones. The severity is left out, so that changing the
+// drift policy doesn't turn known drifts into new ones.
+func (d Drift) Hash() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s", d.Kind, d.Team, d.Member)))
+	return hex.EncodeToString(sum[:6])
+}
+
+// ComputeDrift returns the drifts between localCfg and upstreamCfg, classified
+// according to the drift policy of localCfg and sorted by decreasing
+// severity.
+func ComputeDrift(localCfg, upstreamCfg *config.Config) []Drift {
+	var drifts []Drift
+	add := func(kind config.DriftKind, teamName, member string) {
+		drifts = append(drifts, Drift{
+			Kind:     kind,
+			Severity: localCfg.Drift.Severity(kind),
+			Team:     teamName,
+			Member:   member,
+		})
+	}
+
+	for teamName, localTeam := range localCfg.Teams {
+		upstreamTeam, ok := upstreamCfg.Teams[teamName]
+		if !ok {
+			add(config.DriftTeamMissing, teamName, "")
+			continue
+		}
+		for _, member := range set.DifferenceFold(localTeam.Members, upstreamTeam.Members) {
+			add(config.DriftMemberMissing, teamName, member)
+		}
+		for _, member := range set.DifferenceFold(upstreamTeam.Members, localTeam.Members) {
+			add(config.DriftExtraMember, teamName, member)
+		}
+		// Excluded members can't be retrieved from GH, the settings are
+		// compared as by ComputePlan.
+		localCRA := localTeam.CodeReviewAssignment
+		localCRA.TeamMemberCount = ReviewerCount(localCfg, teamName)
+		localCRA.TeamMemberPercentage = 0
+		diff := compareReviewAssignments(localCRA, upstreamTeam.CodeReviewAssignment, nil, []string{}, localCfg.AppliedReviewOptions[teamName])
+		if len(diff.Settings) != 0 {
+			add(config.DriftReviewAssignment, teamName, "")
+		}
+	}
+	for teamName := range upstreamCfg.Teams {
+		if _, ok := localCfg.Teams[teamName]; !ok {
+			add(config.DriftUnmanagedTeam, teamName, "")
+		}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Severity != drifts[j].Severity {
+			return drifts[i].Severity.AtLeast(drifts[j].Severity)
+		}
+		if drifts[i].Team != drifts[j].Team {
+			return drifts[i].Team < drifts[j].Team
+		}
+		if drifts[i].Kind != drifts[j].Kind {
+			return drifts[i].Kind < drifts[j].Kind
+		}
+		return drifts[i].Member < drifts[j].Member
+	})
+	return drifts
+}
+
+// DriftsAtLeast returns the drifts with at least the given severity.
+func DriftsAtLeast(drifts []Drift, severity config.Severity) []Drift {
+	var filtered []Drift
+	for _, d := range drifts {
+		if d.Severity.AtLeast(severity) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// EffectiveConfig returns the configuration to push to GitHub for the local
+// configuration localCfg against the upstream configuration upstreamCfg at
+// now: the pending removals are held, the review capacities rotated and the
+// expired exclusions dropped. localCfg itself is left untouched.
+func EffectiveConfig(localCfg, upstreamCfg *config.Config, now time.Time) *config.Config {
+	return DropExpiredExclusions(RotateReviewCapacity(HoldPendingRemovals(localCfg, upstreamCfg, now), now), now)
+}
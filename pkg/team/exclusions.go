@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// ExpiringDays is the number of days ahead expirations of exclusions are
+// reported.
+const ExpiringDays = 14
+
+// Exclusion is a member excluded from the code review assignment of a team.
+type Exclusion struct {
+	Team   string `json:"team"`
+	Login  string `json:"login"`
+	Reason string `json:"reason,omitempty"`
+	Until  string `json:"until"`
+}
+
+// DropExpiredExclusions returns the configuration to push to GitHub for the
+// given configuration, without the exclusions from code review assignments
+// that expired at now. cfg itself is left untouched.
+func DropExpiredExclusions(cfg *config.Config, now time.Time) *config.Config {
+	if len(ExpiredExclusions(cfg, now)) == 0 {
+		return cfg
+	}
+
+	effectiveCfg := *cfg
+	effectiveCfg.Teams = make(map[string]config.TeamConfig, len(cfg.Teams))
+	for teamName, teamCfg := range cfg.Teams {
+		teamCfg.CodeReviewAssignment.ExcludedMembers = activeExclusions(teamCfg.CodeReviewAssignment.ExcludedMembers, now)
+		effectiveCfg.Teams[teamName] = teamCfg
+	}
+	return &effectiveCfg
+}
+
+// PruneExpiredExclusions removes the exclusions from code review assignments
+// that expired at now from cfg, and returns them.
+func PruneExpiredExclusions(cfg *config.Config, now time.Time) []Exclusion {
+	expired := ExpiredExclusions(cfg, now)
+	if len(expired) == 0 {
+		return nil
+	}
+	for teamName, teamCfg := range cfg.Teams {
+		teamCfg.CodeReviewAssignment.ExcludedMembers = activeExclusions(teamCfg.CodeReviewAssignment.ExcludedMembers, now)
+		cfg.Teams[teamName] = teamCfg
+	}
+	return expired
+}
+
+// ExpiredExclusions returns the exclusions from code review assignments of
+// cfg that expired at now, sorted by team and login.
+func ExpiredExclusions(cfg *config.Config, now time.Time) []Exclusion {
+	return exclusions(cfg, func(xMember config.ExcludedMember) bool {
+		return xMember.Expired(now)
+	})
+}
+
+// ExpiringExclusions returns the exclusions from code review assignments of
+// cfg that expire within the given number of days after now, sorted by
+// expiry date, team and login.
+func ExpiringExclusions(cfg *config.Config, now time.Time, days int) []Exclusion {
+	last := now.AddDate(0, 0, days).Format(config.DateFormat)
+	expiring := exclusions(cfg, func(xMember config.ExcludedMember) bool {
+		return xMember.Until != "" && !xMember.Expired(now) && xMember.Until <= last
+	})
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiring[i].Until < expiring[j].Until
+	})
+	return expiring
+}
+
+// PrintExclusions prints the given exclusions with their expiry date.
+func PrintExclusions(w io.Writer, exclusions []Exclusion) {
+	for _, x := range exclusions {
+		fmt.Fprintf(w, " Team: %s, member: %s, until %s\n", x.Team, x.Login, x.Until)
+	}
+}
+
+// exclusions returns the exclusions of cfg matching the given function,
+// sorted by team and login.
+func exclusions(cfg *config.Config, match func(config.ExcludedMember) bool) []Exclusion {
+	var matching []Exclusion
+	for _, teamName := range sortedKeys(cfg.Teams) {
+		for _, xMember := range cfg.Teams[teamName].CodeReviewAssignment.ExcludedMembers {
+			if match(xMember) {
+				matching = append(matching, Exclusion{Team: teamName, Login: xMember.Login, Reason: xMember.Reason, Until: xMember.Until})
+			}
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		if matching[i].Team != matching[j].Team {
+			return matching[i].Team < matching[j].Team
+		}
+		return matching[i].Login < matching[j].Login
+	})
+	return matching
+}
+
+// activeExclusions returns the exclusions that did not expire at now.
+func activeExclusions(excluded []config.ExcludedMember, now time.Time) []config.ExcludedMember {
+	var active []config.ExcludedMember
+	for _, xMember := range excluded {
+		if !xMember.Expired(now) {
+			active = append(active, xMember)
+		}
+	}
+	return active
+}
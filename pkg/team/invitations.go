@@ -0,0 +1,335 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// Invitation is the invitation to the organization of a user that isn't a
+// member of it but is added to a team.
+type Invitation struct {
+	// Pending is true if the user was already invited and didn't accept the
+	// invitation yet.
+	Pending bool `json:"pending,omitempty"`
+	// Role is the role the user is invited with. If empty, GitHub invites
+	// the user as direct member when adding it to the team.
+	Role config.OrgRole `json:"role,omitempty"`
+}
+
+func (i Invitation) String() string {
+	switch {
+	case i.Pending:
+		return "invited, pending"
+	case i.Role != "":
+		return fmt.Sprintf("not an org member, inviting as %s", i.Role)
+	default:
+		return "not an org member, invited by GitHub when added"
+	}
+}
+
+// OrgMembers are the members of an organization and the users with a pending
+// invitation to it, by lowercase login.
+type OrgMembers struct {
+	Members map[string]struct{}
+	Invited map[string]struct{}
+}
+
+// GetOrgMembers returns the members of the organization and the users with a
+// pending invitation to it.
+//
+//	{
+//	 organization(login: "cilium") {
+//	   membersWithRole(first: 100) { nodes { login } }
+//	   pendingMembers(first: 100) { nodes { login } }
+//	 }
+//	}
+func (tm *Manager) GetOrgMembers(ctx context.Context) (*OrgMembers, error) {
+	members, err := tm.queryOrgLogins(ctx, "membersWithRole")
+	if err != nil {
+		return nil, err
+	}
+	invited, err := tm.queryOrgLogins(ctx, "pendingMembers")
+	if err != nil {
+		return nil, err
+	}
+	return &OrgMembers{Members: members, Invited: invited}, nil
+}
+
+// queryOrgLogins returns the lowercase logins of the users of the given
+// organization connection.
+func (tm *Manager) queryOrgLogins(ctx context.Context, connection string) (map[string]struct{}, error) {
+	query := github.BuildQuery(
+		map[string]string{"owner": "String!", "cursor": "String"},
+		github.NewField("organization",
+			// Aliased so that both connections decode into Users.
+			github.Connection("users: "+connection, "first: 100, after: $cursor", github.NewField("login")),
+		).WithArgs("login: $owner"),
+	)
+
+	logins := map[string]struct{}{}
+	variables := map[string]interface{}{
+		"owner":  tm.owner,
+		"cursor": nil,
+	}
+	for {
+		var q struct {
+			Organization struct {
+				Users github.ConnectionResult[struct {
+					Login string
+				}]
+			}
+		}
+		if err := tm.gqlGHClient.QueryRaw(ctx, query, variables, &q); err != nil {
+			return nil, err
+		}
+		for _, n := range q.Organization.Users.Nodes {
+			logins[strings.ToLower(n.Login)] = struct{}{}
+		}
+		if !q.Organization.Users.PageInfo.HasNextPage {
+			return logins, nil
+		}
+		variables["cursor"] = q.Organization.Users.PageInfo.EndCursor
+	}
+}
+
+// PlanInvitations sets the invitations of the users added to teams by plan
+// that aren't members of the organization. Users that weren't invited yet are
+// invited with the invite role of localCfg. The accounts configured in
+// localCfg that don't exist anymore, e.g. deleted or suspended, are reported
+// and dropped from plan, adding them would fail. Organization members are
+// known to exist, so only the other configured logins are resolved.
+func (tm *Manager) PlanInvitations(ctx context.Context, localCfg *config.Config, plan *Plan) error {
+	orgMembers, err := tm.GetOrgMembers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read organization members: %w", err)
+	}
+	plan.Invitations = ComputeInvitations(plan, orgMembers, localCfg.Policy.InviteRole)
+
+	configured := set.New[string]()
+	for login := range localCfg.Members {
+		configured.Add(login)
+	}
+	for _, teamCfg := range localCfg.Teams {
+		configured.Add(teamCfg.Members...)
+	}
+	for login := range plan.Invitations {
+		configured.Add(login)
+	}
+	var logins []string
+	for _, login := range configured.Elements() {
+		if _, ok := orgMembers.Members[strings.ToLower(login)]; !ok {
+			logins = append(logins, login)
+		}
+	}
+	missing, err := tm.FindMissingAccounts(ctx, set.UnionFold(logins, nil))
+	if err != nil {
+		return fmt.Errorf("failed to resolve accounts of configured users: %w", err)
+	}
+	for _, login := range missing {
+		var teams, added []string
+		for _, teamName := range sortedKeys(localCfg.Teams) {
+			if set.ContainsFold(localCfg.Teams[teamName].Members, login) {
+				teams = append(teams, teamName)
+			}
+		}
+		for _, teamName := range sortedKeys(plan.TeamChanges) {
+			if set.ContainsFold(plan.TeamChanges[teamName].Add, login) {
+				added = append(added, teamName)
+			}
+		}
+		if len(added) != 0 {
+			tm.reporter.Error("Account %s doesn't exist anymore or is suspended, not adding it to teams %s, remove it from the configuration",
+				login, strings.Join(added, ", "))
+			continue
+		}
+		tm.reporter.Error("Account %s of teams %s doesn't exist anymore or is suspended, remove it from the configuration",
+			login, strings.Join(teams, ", "))
+	}
+	plan.dropMissingAccounts(missing)
+	return nil
+}
+
+// ComputeInvitations returns the invitations of the users added to teams by
+// plan that aren't members of the organization, by login.
+func ComputeInvitations(plan *Plan, orgMembers *OrgMembers, role config.OrgRole) map[string]Invitation {
+	invitations := map[string]Invitation{}
+	for _, change := range plan.TeamChanges {
+		for _, login := range change.Add {
+			lower := strings.ToLower(login)
+			if _, ok := orgMembers.Members[lower]; ok {
+				continue
+			}
+			if _, ok := orgMembers.Invited[lower]; ok {
+				invitations[login] = Invitation{Pending: true}
+				continue
+			}
+			invitations[login] = Invitation{Role: role}
+		}
+	}
+	return invitations
+}
+
+// sendInvitations invites the users of the plan that weren't invited yet and
+// whose invitation has a role to the organization. It returns the number of
+// invitations that failed.
+func (tm *Manager) sendInvitations(ctx context.Context, plan *Plan, dryRun bool) (failed int) {
+	for _, login := range sortedKeys(plan.Invitations) {
+		invitation := plan.Invitations[login]
+		if invitation.Pending || invitation.Role == "" {
+			continue
+		}
+		tm.reporter.Progress("Inviting %s to organization %s as %s", login, tm.owner, invitation.Role)
+		if dryRun {
+			continue
+		}
+		if err := tm.InviteUser(ctx, login, invitation.Role); err != nil {
+			tm.reporter.Error("Unable to invite %s: %s", login, github.TranslateError(err))
+			failed++
+			continue
+		}
+		invitation.Pending = true
+		plan.Invitations[login] = invitation
+	}
+	return failed
+}
+
+// InviteUser invites the user with the given login to the organization with
+// the given role.
+func (tm *Manager) InviteUser(ctx context.Context, login string, role config.OrgRole) error {
+	user, _, err := tm.ghClient.Users.Get(ctx, login)
+	if err != nil {
+		return err
+	}
+	_, _, err = tm.ghClient.Organizations.CreateOrgInvitation(ctx, tm.owner, &gh.CreateOrgInvitationOptions{
+		InviteeID: user.ID,
+		Role:      gh.String(string(role)),
+	})
+	return err
+}
+
+// withoutInvitees returns change without the added users that are invited to
+// the organization, whose team memberships stay pending until they accept
+// the invitation.
+func withoutInvitees(change TeamChange, invitations map[string]Invitation) TeamChange {
+	if len(invitations) == 0 {
+		return change
+	}
+	var add []string
+	for _, login := range change.Add {
+		if _, ok := invitations[login]; !ok {
+			add = append(add, login)
+		}
+	}
+	change.Add = add
+	return change
+}
+
+// ListOrgInvitations returns the pending invitations to the organization.
+func (tm *Manager) ListOrgInvitations(ctx context.Context) ([]*gh.Invitation, error) {
+	var invitations []*gh.Invitation
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := tm.ghClient.Organizations.ListPendingOrgInvitations(ctx, tm.owner, opts)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, page...)
+		if resp.NextPage == 0 {
+			return invitations, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// CancelOrgInvitation cancels the pending invitation to the organization with
+// the given ID.
+func (tm *Manager) CancelOrgInvitation(ctx context.Context, id int64) error {
+	// go-github doesn't support canceling invitations.
+	req, err := tm.ghClient.NewRequest("DELETE", fmt.Sprintf("orgs/%s/invitations/%d", tm.owner, id), nil)
+	if err != nil {
+		return err
+	}
+	_, err = tm.ghClient.Do(ctx, req, nil)
+	return err
+}
+
+// PendingTeamMemberships maps the lowercase logins of the team members of cfg with a
+// pending invitation to the organization to the teams they are members of in
+// cfg. Their team memberships stay pending until they accept the invitation,
+// so they show up as missing members of these teams in the meantime.
+func PendingTeamMemberships(cfg *config.Config, invitations []*gh.Invitation) map[string][]string {
+	invited := map[string]struct{}{}
+	for _, invitation := range invitations {
+		if login := invitation.GetLogin(); login != "" {
+			invited[strings.ToLower(login)] = struct{}{}
+		}
+	}
+
+	pending := map[string][]string{}
+	for _, teamName := range sortedKeys(cfg.Teams) {
+		for _, login := range cfg.Teams[teamName].Members {
+			lower := strings.ToLower(login)
+			if _, ok := invited[lower]; ok {
+				pending[lower] = append(pending[lower], teamName)
+			}
+		}
+	}
+	return pending
+}
+
+// StaleInvitation is a pending invitation to the organization that should be
+// canceled.
+type StaleInvitation struct {
+	*gh.Invitation
+	Reason string
+}
+
+// StaleInvitations returns the invitations of the given ones that are older
+// than maxAge, if not 0, and, if unknown is set, the ones of users that
+// aren't members of cfg nor of any of its teams, e.g. because they were
+// removed from it. Invitations by email, which can't be matched against cfg,
+// are only stale once older than maxAge.
+func StaleInvitations(cfg *config.Config, invitations []*gh.Invitation, maxAge time.Duration, unknown bool, now time.Time) []StaleInvitation {
+	known := map[string]struct{}{}
+	for login := range cfg.Members {
+		known[strings.ToLower(login)] = struct{}{}
+	}
+	for _, teamCfg := range cfg.Teams {
+		for _, login := range teamCfg.Members {
+			known[strings.ToLower(login)] = struct{}{}
+		}
+	}
+
+	var stale []StaleInvitation
+	for _, invitation := range invitations {
+		if age := now.Sub(invitation.GetCreatedAt()); maxAge != 0 && age > maxAge {
+			stale = append(stale, StaleInvitation{
+				Invitation: invitation,
+				Reason:     fmt.Sprintf("sent %d days ago", int(age.Hours()/24)),
+			})
+			continue
+		}
+		login := invitation.GetLogin()
+		if !unknown || login == "" {
+			continue
+		}
+		if _, ok := known[strings.ToLower(login)]; !ok {
+			stale = append(stale, StaleInvitation{
+				Invitation: invitation,
+				Reason:     "not in the configuration",
+			})
+		}
+	}
+	return stale
+}
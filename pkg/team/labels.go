@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// LabelChange is a label of a repository that is created, updated or
+// deleted.
+type LabelChange struct {
+	Repo string `json:"repository"`
+	// Label is the desired label, nil for deletions.
+	Label *config.Label `json:"label,omitempty"`
+	// Current is the current label, nil for creations.
+	Current *config.Label `json:"current,omitempty"`
+}
+
+// PrintLabelChanges prints the given changes of labels.
+func PrintLabelChanges(w io.Writer, changes []LabelChange) {
+	for _, c := range changes {
+		switch {
+		case c.Current == nil:
+			fmt.Fprintf(w, " Repository %s: creating label %s (#%s) %q\n", c.Repo, c.Label.Name, c.Label.Color, c.Label.Description)
+		case c.Label == nil:
+			fmt.Fprintf(w, " Repository %s: deleting label %s\n", c.Repo, c.Current.Name)
+		default:
+			fmt.Fprintf(w, " Repository %s: updating label %s (#%s) %q to %s (#%s) %q\n", c.Repo,
+				c.Current.Name, c.Current.Color, c.Current.Description, c.Label.Name, c.Label.Color, c.Label.Description)
+		}
+	}
+}
+
+// ListLabels returns the labels of the given repository.
+func (tm *Manager) ListLabels(ctx context.Context, repo string) ([]config.Label, error) {
+	var labels []config.Label
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := tm.ghClient.Issues.ListLabels(ctx, tm.owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range page {
+			labels = append(labels, config.Label{Name: l.GetName(), Color: l.GetColor(), Description: l.GetDescription()})
+		}
+		if resp.NextPage == 0 {
+			return labels, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// ComputeLabelChanges returns the changes turning the current labels of a
+// repository into the desired ones, keyed by lower case name, sorted by
+// name. The current labels missing in desired are deleted if prune is set.
+func ComputeLabelChanges(repo string, desired map[string]config.Label, current []config.Label, prune bool) []LabelChange {
+	var changes []LabelChange
+	seen := map[string]bool{}
+	for _, c := range current {
+		c := c
+		name := strings.ToLower(c.Name)
+		seen[name] = true
+		l, ok := desired[name]
+		switch {
+		case !ok && prune:
+			changes = append(changes, LabelChange{Repo: repo, Current: &c})
+		case ok && (l.Name != c.Name || !strings.EqualFold(l.Color, c.Color) || l.Description != c.Description):
+			changes = append(changes, LabelChange{Repo: repo, Label: &l, Current: &c})
+		}
+	}
+	for name, l := range desired {
+		l := l
+		if !seen[name] {
+			changes = append(changes, LabelChange{Repo: repo, Label: &l})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return strings.ToLower(changes[i].name()) < strings.ToLower(changes[j].name())
+	})
+	return changes
+}
+
+// name returns the current name of the label of c, or its desired name for
+// creations.
+func (c LabelChange) name() string {
+	if c.Current != nil {
+		return c.Current.Name
+	}
+	return c.Label.Name
+}
+
+// syncLabels creates, updates and deletes the labels of the repositories of
+// the label sets of cfg so that they match it. It returns the number of
+// changes submitted and failed.
+func (tm *Manager) syncLabels(ctx context.Context, cfg *config.Config, force, dryRun bool) (submitted, failed int, err error) {
+	desired, prune := cfg.RepositoryLabels()
+	var changes []LabelChange
+	for _, repo := range sortedKeys(desired) {
+		current, err := tm.ListLabels(ctx, repo)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to list labels of repository %s: %w", repo, err)
+		}
+		changes = append(changes, ComputeLabelChanges(repo, desired[repo], current, prune[repo])...)
+	}
+	if len(changes) == 0 {
+		return 0, 0, nil
+	}
+
+	tm.reporter.Plan(PlanEvent{
+		Title:   "Going to update the following repository labels",
+		Changes: changes,
+		Print: func(w io.Writer) {
+			PrintLabelChanges(w, changes)
+		},
+	})
+	yes := force
+	if !force {
+		yes, err = terminal.AskForConfirmation("Continue?")
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if !yes {
+		return 0, 0, nil
+	}
+
+	for _, c := range changes {
+		if dryRun {
+			submitted++
+			continue
+		}
+		// go-github doesn't escape the names of labels in their URL, e.g.
+		// of "kind/bug".
+		switch {
+		case c.Current == nil:
+			_, _, err = tm.ghClient.Issues.CreateLabel(ctx, tm.owner, c.Repo, ghLabel(*c.Label))
+		case c.Label == nil:
+			_, err = tm.ghClient.Issues.DeleteLabel(ctx, tm.owner, c.Repo, url.PathEscape(c.Current.Name))
+		default:
+			_, _, err = tm.ghClient.Issues.EditLabel(ctx, tm.owner, c.Repo, url.PathEscape(c.Current.Name), ghLabel(*c.Label))
+		}
+		if err != nil {
+			tm.reporter.Error("Unable to update label %s of repository %s: %s", c.name(), c.Repo, github.TranslateError(err))
+			failed++
+			continue
+		}
+		submitted++
+	}
+	return submitted, failed, nil
+}
+
+// ghLabel returns the GitHub label of the given label. The description is
+// always set so that it is cleared if empty.
+func ghLabel(l config.Label) *gh.Label {
+	return &gh.Label{
+		Name:        gh.String(l.Name),
+		Color:       gh.String(strings.ToLower(l.Color)),
+		Description: gh.String(l.Description),
+	}
+}
@@ -0,0 +1,522 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// ListTeamMembers returns the logins of the members of the given team.
+func (tm *Manager) ListTeamMembers(ctx context.Context, teamName string) ([]string, error) {
+	opts := &gh.TeamListTeamMembersOptions{
+		ListOptions: gh.ListOptions{PerPage: 100},
+	}
+	var members []string
+	for {
+		page, resp, err := tm.ghClient.Teams.ListTeamMembersBySlug(ctx, tm.owner, tm.teamSlug(teamName), opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range page {
+			members = append(members, u.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			return members, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// CreateTeam creates the given team with the given settings and returns its
+// node ID.
+func (tm *Manager) CreateTeam(ctx context.Context, teamName string, newTeam NewTeam) (string, error) {
+	t := teamRequest{NewTeam: gh.NewTeam{Name: teamName}}
+	if newTeam.Description != "" {
+		t.Description = &newTeam.Description
+	}
+	if newTeam.Privacy != "" {
+		privacy := restPrivacy(newTeam.Privacy)
+		t.Privacy = &privacy
+	}
+	if newTeam.NotificationSetting != "" {
+		setting := restNotificationSetting(newTeam.NotificationSetting)
+		t.NotificationSetting = &setting
+	}
+	if newTeam.Parent != "" {
+		parent, _, err := tm.ghClient.Teams.GetTeamBySlug(ctx, tm.owner, tm.teamSlug(newTeam.Parent))
+		if err != nil {
+			return "", fmt.Errorf("failed to get parent team %q: %w", newTeam.Parent, err)
+		}
+		t.ParentTeamID = parent.ID
+	}
+	req, err := tm.ghClient.NewRequest("POST", fmt.Sprintf("orgs/%s/teams", tm.owner), t)
+	if err != nil {
+		return "", err
+	}
+	created := new(gh.Team)
+	if _, err = tm.ghClient.Do(ctx, req, created); err != nil {
+		return "", err
+	}
+	tm.slugs[teamName] = created.GetSlug()
+	return created.GetNodeID(), nil
+}
+
+// createTeams creates the new teams of the given plan, parent teams first,
+// and sets their IDs into the review assignments of the plan. GitHub adds the
+// creator of a team as its maintainer, so the members that aren't part of
+// the plan are added to the members to remove. The teams whose parent
+// couldn't be created are skipped, and the members and review assignments of
+// the teams that couldn't be created are dropped from the plan. It returns
+// the IDs of the created teams and the number of teams that couldn't be
+// created.
+func (tm *Manager) createTeams(ctx context.Context, plan *Plan) (map[string]string, int) {
+	created := map[string]string{}
+	notCreated := map[string]bool{}
+	fail := func(teamName string) {
+		delete(plan.TeamChanges, teamName)
+		delete(plan.ReviewAssignments, teamName)
+		notCreated[teamName] = true
+	}
+	remaining := sortedKeys(plan.NewTeams)
+	for len(remaining) != 0 {
+		var next []string
+		for _, teamName := range remaining {
+			parent := plan.NewTeams[teamName].Parent
+			if _, isNew := plan.NewTeams[parent]; isNew {
+				if notCreated[parent] {
+					tm.reporter.Error("Unable to create team %s: parent team %s wasn't created", teamName, parent)
+					fail(teamName)
+					continue
+				}
+				if _, ok := created[parent]; !ok {
+					next = append(next, teamName)
+					continue
+				}
+			}
+
+			tm.reporter.Progress("Creating team: %s", teamName)
+			id, err := tm.CreateTeam(ctx, teamName, plan.NewTeams[teamName])
+			if err != nil {
+				tm.reporter.Error("Unable to create team %s: %s", teamName, github.TranslateError(err))
+				fail(teamName)
+				continue
+			}
+			created[teamName] = id
+			tm.reporter.Progress("Created team %s with ID %s", teamName, id)
+
+			input := plan.ReviewAssignments[teamName]
+			input.ID = id
+			plan.ReviewAssignments[teamName] = input
+
+			members, err := tm.ListTeamMembers(ctx, teamName)
+			if err != nil {
+				tm.reporter.Warn("Unable to list members of created team %s: %s", teamName, err)
+				continue
+			}
+			change := plan.TeamChanges[teamName]
+			change.Remove = append(change.Remove, set.DifferenceFold(members, change.Add)...)
+			plan.TeamChanges[teamName] = change
+		}
+		if len(next) == len(remaining) {
+			// Only teams that are their own ancestors are left.
+			for _, teamName := range next {
+				tm.reporter.Error("Unable to create team %s: cyclic parent teams", teamName)
+				fail(teamName)
+			}
+			break
+		}
+		remaining = next
+	}
+	return created, len(notCreated)
+}
+
+// DeleteTeam deletes the given team.
+func (tm *Manager) DeleteTeam(ctx context.Context, teamName string) error {
+	_, err := tm.ghClient.Teams.DeleteTeamBySlug(ctx, tm.owner, tm.teamSlug(teamName))
+	return err
+}
+
+// RenameTeam renames the given team and returns its new slug.
+func (tm *Manager) RenameTeam(ctx context.Context, oldName, newName string) (string, error) {
+	t, _, err := tm.ghClient.Teams.EditTeamBySlug(ctx, tm.owner, tm.teamSlug(oldName), gh.NewTeam{Name: newName}, false)
+	if err != nil {
+		return "", err
+	}
+	delete(tm.slugs, oldName)
+	tm.slugs[newName] = t.GetSlug()
+	return t.GetSlug(), nil
+}
+
+// RenameTeamInConfig renames the given team in cfg, including in the parents
+// of its child teams, the onboarding rules, the repository templates, the
+// exclusive teams, the security managers, the release cycles, the code review
+// assignments excluding its members and the members excluded from its own. As renaming a team changes its slug, the
+// slug of the team is reset.
+func RenameTeamInConfig(cfg *config.Config, oldName, newName string) {
+	teamCfg := cfg.Teams[oldName]
+	teamCfg.Slug = ""
+	cfg.Teams[newName] = teamCfg
+	delete(cfg.Teams, oldName)
+
+	for teamName, teamCfg := range cfg.Teams {
+		if teamCfg.Parent == oldName {
+			teamCfg.Parent = newName
+			cfg.Teams[teamName] = teamCfg
+		}
+	}
+	for _, rule := range cfg.OnboardingRules {
+		for i, t := range rule.Teams {
+			if t == oldName {
+				rule.Teams[i] = newName
+			}
+		}
+	}
+	for _, tmpl := range cfg.RepositoryTemplates {
+		if perm, ok := tmpl.Teams[oldName]; ok {
+			tmpl.Teams[newName] = perm
+			delete(tmpl.Teams, oldName)
+		}
+	}
+	for i, group := range cfg.Policy.ExclusiveTeams {
+		cfg.Policy.ExclusiveTeams[i] = replaceTeam(group, oldName, newName)
+	}
+	replaceReleaseTeam(cfg, oldName, newName)
+	for i, t := range cfg.SecurityManagers {
+		if t == oldName {
+			cfg.SecurityManagers[i] = newName
+		}
+	}
+	if logins, ok := cfg.AppliedExclusions[oldName]; ok {
+		cfg.AppliedExclusions[newName] = logins
+		delete(cfg.AppliedExclusions, oldName)
+	}
+	if options, ok := cfg.AppliedReviewOptions[oldName]; ok {
+		cfg.AppliedReviewOptions[newName] = options
+		delete(cfg.AppliedReviewOptions, oldName)
+	}
+	replaceExcludedTeam(cfg, oldName, newName)
+}
+
+// RemoveTeamFromConfig removes the given team from cfg, including from the
+// onboarding rules, repository templates, exclusive teams, security managers,
+// release cycles and code review assignments referencing it. Exclusive teams
+// left with a single team are dropped, as are the release cycles of the team.
+// If archive is set, the team configuration is moved into the retired teams
+// of cfg.
+func RemoveTeamFromConfig(cfg *config.Config, teamName string, archive bool, now time.Time) {
+	if archive {
+		if cfg.Retired == nil {
+			cfg.Retired = map[string]config.RetiredTeam{}
+		}
+		cfg.Retired[teamName] = config.RetiredTeam{
+			RetiredAt:  now.Format(config.DateFormat),
+			TeamConfig: cfg.Teams[teamName],
+		}
+	}
+	delete(cfg.Teams, teamName)
+
+	for i, rule := range cfg.OnboardingRules {
+		teams := rule.Teams[:0]
+		for _, t := range rule.Teams {
+			if t != teamName {
+				teams = append(teams, t)
+			}
+		}
+		cfg.OnboardingRules[i].Teams = teams
+	}
+	for _, tmpl := range cfg.RepositoryTemplates {
+		delete(tmpl.Teams, teamName)
+	}
+	exclusiveTeams := cfg.Policy.ExclusiveTeams[:0]
+	for _, group := range cfg.Policy.ExclusiveTeams {
+		group = set.Difference(group, []string{teamName})
+		if len(group) > 1 {
+			exclusiveTeams = append(exclusiveTeams, group)
+		}
+	}
+	cfg.Policy.ExclusiveTeams = exclusiveTeams
+	releases := cfg.Releases[:0]
+	for _, r := range cfg.Releases {
+		if r.Team != teamName {
+			releases = append(releases, r)
+		}
+	}
+	cfg.Releases = releases
+	securityManagers := cfg.SecurityManagers[:0]
+	for _, t := range cfg.SecurityManagers {
+		if t != teamName {
+			securityManagers = append(securityManagers, t)
+		}
+	}
+	cfg.SecurityManagers = securityManagers
+	delete(cfg.AppliedExclusions, teamName)
+	delete(cfg.AppliedReviewOptions, teamName)
+	replaceExcludedTeam(cfg, teamName, "")
+}
+
+// TeamMerge is the result of merging a team into another one.
+type TeamMerge struct {
+	// Added are the members of the source team added to the destination
+	// team.
+	Added []string
+	// Warnings are the settings of the source team that couldn't be merged.
+	Warnings []string
+}
+
+// MergeTeamsInConfig merges the team src into the team dst in cfg: the members
+// of src are added to dst along with their exclusions from the code review
+// assignment, and its metadata, owned paths, maintainers and repositories are
+// merged into the ones of dst. The maintainers and repositories are only
+// merged if they are managed for dst, since setting them would otherwise
+// demote the current maintainers and revoke the current repository access of
+// dst. The child teams, onboarding rules, repository templates, exclusive
+// teams and release cycles referencing src are updated to reference dst, as
+// well as the security managers and the code review assignments excluding the
+// members of src. The release shepherds of src stay members of dst. src is
+// left without members nor code review assignment, to be retired once pushed.
+func MergeTeamsInConfig(cfg *config.Config, src, dst string) (TeamMerge, error) {
+	srcCfg, ok := cfg.Teams[src]
+	if !ok {
+		return TeamMerge{}, fmt.Errorf("unknown team %q", src)
+	}
+	dstCfg, ok := cfg.Teams[dst]
+	if !ok {
+		return TeamMerge{}, fmt.Errorf("unknown team %q", dst)
+	}
+	if src == dst {
+		return TeamMerge{}, fmt.Errorf("team %q can't be merged into itself", src)
+	}
+
+	var merge TeamMerge
+	merge.Added = set.DifferenceFold(srcCfg.Members, dstCfg.Members)
+	excluded := make([]string, 0, len(dstCfg.CodeReviewAssignment.ExcludedMembers))
+	for _, xMember := range dstCfg.CodeReviewAssignment.ExcludedMembers {
+		excluded = append(excluded, xMember.Login)
+	}
+	for _, xMember := range srcCfg.CodeReviewAssignment.ExcludedMembers {
+		// Members of both teams keep the exclusions of dst.
+		if set.ContainsFold(merge.Added, xMember.Login) && !set.ContainsFold(excluded, xMember.Login) {
+			dstCfg.CodeReviewAssignment.ExcludedMembers = append(dstCfg.CodeReviewAssignment.ExcludedMembers, xMember)
+		}
+	}
+	dstCfg.Members = append(dstCfg.Members, merge.Added...)
+	sort.Strings(dstCfg.Members)
+
+	switch {
+	case len(dstCfg.Maintainers) != 0:
+		dstCfg.Maintainers = set.UnionFold(dstCfg.Maintainers, srcCfg.Maintainers)
+		sort.Strings(dstCfg.Maintainers)
+	case len(srcCfg.Maintainers) != 0:
+		merge.Warnings = append(merge.Warnings, fmt.Sprintf("the maintainers of team %s aren't merged since the roles of team %s aren't managed", src, dst))
+	}
+	switch {
+	case len(dstCfg.Repositories) != 0:
+		for repo, perm := range srcCfg.Repositories {
+			if current, ok := dstCfg.Repositories[repo]; !ok || permissionRank(perm) > permissionRank(current) {
+				dstCfg.Repositories[repo] = perm
+			}
+		}
+	case len(srcCfg.Repositories) != 0:
+		merge.Warnings = append(merge.Warnings, fmt.Sprintf("the repositories of team %s aren't merged since the repository access of team %s isn't managed", src, dst))
+	}
+	dstCfg.OwnedPaths = set.Union(dstCfg.OwnedPaths, srcCfg.OwnedPaths)
+	for k, v := range srcCfg.Metadata {
+		if _, ok := dstCfg.Metadata[k]; !ok {
+			if dstCfg.Metadata == nil {
+				dstCfg.Metadata = map[string]string{}
+			}
+			dstCfg.Metadata[k] = v
+		}
+	}
+	if dstCfg.Parent == src {
+		dstCfg.Parent = srcCfg.Parent
+	}
+
+	srcCfg.Members = nil
+	srcCfg.Maintainers = nil
+	srcCfg.CodeReviewAssignment = config.CodeReviewAssignment{}
+	srcCfg.Repositories = nil
+	srcCfg.OwnedPaths = nil
+	cfg.Teams[src] = srcCfg
+	cfg.Teams[dst] = dstCfg
+
+	for teamName, teamCfg := range cfg.Teams {
+		if teamCfg.Parent == src && teamName != dst {
+			teamCfg.Parent = dst
+			cfg.Teams[teamName] = teamCfg
+		}
+	}
+	for i, rule := range cfg.OnboardingRules {
+		cfg.OnboardingRules[i].Teams = replaceTeam(rule.Teams, src, dst)
+	}
+	for _, tmpl := range cfg.RepositoryTemplates {
+		if perm, ok := tmpl.Teams[src]; ok {
+			if current, ok := tmpl.Teams[dst]; !ok || permissionRank(perm) > permissionRank(current) {
+				tmpl.Teams[dst] = perm
+			}
+			delete(tmpl.Teams, src)
+		}
+	}
+	for i, group := range cfg.Policy.ExclusiveTeams {
+		cfg.Policy.ExclusiveTeams[i] = replaceTeam(group, src, dst)
+	}
+	replaceReleaseTeam(cfg, src, dst)
+	if cfg.SecurityManagers != nil {
+		cfg.SecurityManagers = replaceTeam(cfg.SecurityManagers, src, dst)
+	}
+	replaceExcludedTeam(cfg, src, dst)
+	return merge, nil
+}
+
+// TeamSplit is the result of splitting a team into new teams.
+type TeamSplit struct {
+	// Remaining are the members of the source team not assigned to any of
+	// the new teams, which stay members of the source team.
+	Remaining []string
+}
+
+// SplitTeamInConfig creates the teams of assignment in cfg, mapping their names
+// to the members of the team src they get. The new teams inherit the parent,
+// privacy, notification setting and code review assignment settings of src,
+// along with the exclusions and, if the roles of src are managed, the
+// maintainer role of their members. The assigned members are removed from src.
+// Nothing is changed if any of the new teams already exists or any of the
+// assigned users isn't a member of src.
+func SplitTeamInConfig(cfg *config.Config, src string, assignment map[string][]string) (TeamSplit, error) {
+	srcCfg, ok := cfg.Teams[src]
+	if !ok {
+		return TeamSplit{}, fmt.Errorf("unknown team %q", src)
+	}
+	var assigned []string
+	for _, teamName := range sortedKeys(assignment) {
+		if _, ok := cfg.Teams[teamName]; ok {
+			return TeamSplit{}, fmt.Errorf("team %q already exists", teamName)
+		}
+		if missing := set.DifferenceFold(assignment[teamName], srcCfg.Members); len(missing) != 0 {
+			return TeamSplit{}, fmt.Errorf("users %s are not members of team %q", strings.Join(missing, ", "), src)
+		}
+		assigned = append(assigned, assignment[teamName]...)
+	}
+
+	for teamName, logins := range assignment {
+		members := set.IntersectionFold(srcCfg.Members, logins)
+		sort.Strings(members)
+		cra := srcCfg.CodeReviewAssignment
+		cra.ExcludedMembers = nil
+		for _, xMember := range srcCfg.CodeReviewAssignment.ExcludedMembers {
+			if _, isTeam := xMember.Team(); isTeam || set.ContainsFold(members, xMember.Login) {
+				cra.ExcludedMembers = append(cra.ExcludedMembers, xMember)
+			}
+		}
+		teamCfg := config.TeamConfig{
+			Privacy:              srcCfg.Privacy,
+			NotificationSetting:  srcCfg.NotificationSetting,
+			Parent:               srcCfg.Parent,
+			Members:              members,
+			CodeReviewAssignment: cra,
+		}
+		if len(srcCfg.Maintainers) != 0 {
+			teamCfg.Maintainers = set.IntersectionFold(srcCfg.Maintainers, members)
+			sort.Strings(teamCfg.Maintainers)
+		}
+		cfg.Teams[teamName] = teamCfg
+	}
+
+	srcCfg.Members = set.DifferenceFold(srcCfg.Members, assigned)
+	srcCfg.Maintainers = set.DifferenceFold(srcCfg.Maintainers, assigned)
+	var staying []config.ExcludedMember
+	for _, xMember := range srcCfg.CodeReviewAssignment.ExcludedMembers {
+		if !set.ContainsFold(assigned, xMember.Login) {
+			staying = append(staying, xMember)
+		}
+	}
+	srcCfg.CodeReviewAssignment.ExcludedMembers = staying
+	cfg.Teams[src] = srcCfg
+
+	return TeamSplit{Remaining: srcCfg.Members}, nil
+}
+
+// replaceExcludedTeam replaces the exclusions of the team oldName from the code
+// review assignments of cfg by exclusions of the team newName, or removes them
+// if newName is empty. Teams don't exclude themselves nor the same team twice.
+func replaceExcludedTeam(cfg *config.Config, oldName, newName string) {
+	for teamName, teamCfg := range cfg.Teams {
+		excluded := teamCfg.CodeReviewAssignment.ExcludedMembers
+		replaced := make([]config.ExcludedMember, 0, len(excluded))
+		changed, seen := false, false
+		for _, xMember := range excluded {
+			xTeam, ok := xMember.Team()
+			if ok && xTeam == oldName {
+				changed = true
+				if newName == "" || newName == teamName {
+					continue
+				}
+				xMember.Login = config.ExcludedTeamPrefix + newName
+				xTeam = newName
+			}
+			if ok && xTeam == newName {
+				if seen {
+					changed = true
+					continue
+				}
+				seen = true
+			}
+			replaced = append(replaced, xMember)
+		}
+		if changed {
+			teamCfg.CodeReviewAssignment.ExcludedMembers = replaced
+			cfg.Teams[teamName] = teamCfg
+		}
+	}
+}
+
+// replaceTeam returns teamNames with oldName replaced by newName, without
+// duplicates.
+func replaceTeam(teamNames []string, oldName, newName string) []string {
+	replaced := make([]string, 0, len(teamNames))
+	for _, teamName := range teamNames {
+		if teamName == oldName {
+			teamName = newName
+		}
+		replaced = append(replaced, teamName)
+	}
+	return set.Union(replaced, nil)
+}
+
+// replaceReleaseTeam makes the release cycles of the team oldName the ones of
+// the team newName.
+func replaceReleaseTeam(cfg *config.Config, oldName, newName string) {
+	for i, r := range cfg.Releases {
+		if r.Team == oldName {
+			cfg.Releases[i].Team = newName
+		}
+	}
+}
+
+// permissionRank returns the rank of the given repository permission, higher
+// permissions having higher ranks.
+func permissionRank(perm config.RepositoryPermission) int {
+	for i, p := range []config.RepositoryPermission{
+		config.RepositoryPermissionPull,
+		config.RepositoryPermissionTriage,
+		config.RepositoryPermissionPush,
+		config.RepositoryPermissionMaintain,
+		config.RepositoryPermissionAdmin,
+	} {
+		if p == perm {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// LoginRename is a member of the configuration whose GitHub login changed,
+// matched by its ID.
+type LoginRename struct {
+	ID       string `json:"id"`
+	Local    string `json:"local"`
+	Upstream string `json:"upstream"`
+}
+
+// PrintLoginRenames prints the given renames.
+func PrintLoginRenames(w io.Writer, renames []LoginRename) {
+	for _, r := range renames {
+		fmt.Fprintf(w, " Member: %s\n", r.Local)
+		fmt.Fprintf(w, "    Renamed to %s in GitHub\n", r.Upstream)
+	}
+}
+
+// DetectLoginRenames returns the members of cfg whose ID now belongs to
+// another login, which isn't a member of cfg, sorted by their local login.
+// Members without ID and deleted accounts are ignored.
+//
+//	query($ids: [ID!]!) {
+//	 nodes(ids: $ids) { ... on User { id login } }
+//	}
+func (tm *Manager) DetectLoginRenames(ctx context.Context, cfg *config.Config) ([]LoginRename, error) {
+	loginsByID := map[string]string{}
+	ids := make([]string, 0, len(cfg.Members))
+	for login, user := range cfg.Members {
+		if user.ID == "" {
+			continue
+		}
+		loginsByID[user.ID] = login
+		ids = append(ids, user.ID)
+	}
+	sort.Strings(ids)
+
+	query := github.BuildQuery(
+		map[string]string{"ids": "[ID!]!"},
+		github.NewField("nodes",
+			github.NewField("... on User", github.Fields("id", "login")...),
+		).WithArgs("ids: $ids"),
+	)
+	var renames []LoginRename
+	for start := 0; start < len(ids); start += 100 {
+		end := start + 100
+		if end > len(ids) {
+			end = len(ids)
+		}
+		var q struct {
+			Nodes []*struct {
+				ID    string
+				Login string
+			}
+		}
+		err := tm.gqlGHClient.QueryRaw(ctx, query, map[string]interface{}{"ids": ids[start:end]}, &q)
+		var qErrs github.QueryErrors
+		if err != nil && !(errors.As(err, &qErrs) && qErrs.OnlyNotFound()) {
+			return nil, err
+		}
+		for _, node := range q.Nodes {
+			if node == nil || node.Login == "" {
+				continue
+			}
+			local := loginsByID[node.ID]
+			if local == "" || strings.EqualFold(local, node.Login) {
+				continue
+			}
+			if _, ok := cfg.Members[node.Login]; ok {
+				continue
+			}
+			renames = append(renames, LoginRename{ID: node.ID, Local: local, Upstream: node.Login})
+		}
+	}
+	sort.Slice(renames, func(i, j int) bool {
+		return renames[i].Local < renames[j].Local
+	})
+	return renames, nil
+}
+
+// RenameMemberInConfig renames the member with the given login in cfg,
+// keeping its data, team memberships, exclusions and pending removals.
+func RenameMemberInConfig(cfg *config.Config, oldLogin, newLogin string) {
+	replaceLogin(cfg, oldLogin, newLogin)
+	if user, ok := cfg.Members[oldLogin]; ok {
+		delete(cfg.Members, oldLogin)
+		cfg.Members[newLogin] = user
+	}
+}
+
+// resolveLoginRenames renames the members of localCfg whose login changed in
+// GitHub, once confirmed. Otherwise they show up as removed members, with
+// their new login as an unknown user.
+func (tm *Manager) resolveLoginRenames(ctx context.Context, localCfg *config.Config, force bool) error {
+	renames, err := tm.DetectLoginRenames(ctx, localCfg)
+	if err != nil {
+		return fmt.Errorf("failed to detect renamed members: %w", err)
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+
+	tm.reporter.Plan(PlanEvent{
+		Title:   "Found the following members renamed in GitHub",
+		Changes: renames,
+		Print:   func(w io.Writer) { PrintLoginRenames(w, renames) },
+	})
+	yes := force
+	if !force {
+		yes, err = terminal.AskForConfirmation("Rename them in the configuration?")
+		if err != nil {
+			return err
+		}
+	}
+	if !yes {
+		return nil
+	}
+	for _, r := range renames {
+		tm.reporter.Progress("Renaming member %s to %s in the configuration", r.Local, r.Upstream)
+		RenameMemberInConfig(localCfg, r.Local, r.Upstream)
+	}
+	return nil
+}
@@ -17,223 +17,243 @@ package team
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
-	"reflect"
-	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	gh "github.com/google/go-github/v33/github"
 	"github.com/shurcooL/githubv4"
 
-	"github.com/cilium/team-manager/pkg/comparator"
 	"github.com/cilium/team-manager/pkg/config"
 	"github.com/cilium/team-manager/pkg/github"
-	"github.com/cilium/team-manager/pkg/slices"
+	"github.com/cilium/team-manager/pkg/set"
 	"github.com/cilium/team-manager/pkg/terminal"
 )
 
 type Manager struct {
-	owner       string
-	ghClient    *gh.Client
-	gqlGHClient *githubv4.Client
+	owner        string
+	ghClient     *restClient
+	gqlGHClient  *github.GraphQLClient
+	customFields config.CustomFields
+	// verifyTimeout is how long to wait for membership changes to be
+	// reflected by GitHub, 0 to not verify them.
+	verifyTimeout time.Duration
+	// removeFromOrg is set if SyncTeams removes users it removed from their
+	// last team from the organization.
+	removeFromOrg bool
+	// reporter receives the output of the operations, see SetReporter.
+	reporter Reporter
+	// slugs maps team names to their GitHub slugs, see teamSlug.
+	slugs map[string]string
 }
 
-func NewManager(ghClient *gh.Client, gqlGHClient *githubv4.Client, owner string) *Manager {
+func NewManager(ghClient *gh.Client, gqlGHClient *github.GraphQLClient, owner string) *Manager {
 	return &Manager{
 		owner:       owner,
-		ghClient:    ghClient,
+		ghClient:    newRESTClient(ghClient),
 		gqlGHClient: gqlGHClient,
+		reporter:    &TextReporter{Out: os.Stdout, Err: os.Stderr},
+		slugs:       map[string]string{},
 	}
 }
 
+// NewManagerWithHTTPClient returns a Manager of the given organization
+// accessing GitHub with httpClient, e.g. for consumers bringing their own
+// authenticated transport, see github.NewClientWithHTTPClient.
+func NewManagerWithHTTPClient(httpClient *http.Client, owner string) *Manager {
+	return NewManager(github.NewClientWithHTTPClient(httpClient), github.NewClientGraphQLWithHTTPClient(httpClient), owner)
+}
+
+// SetTeamSlugs sets the GitHub slugs of the teams of cfg, used to refer to
+// these teams in REST API calls. Slugs of teams queried from GitHub are set
+// automatically.
+func (tm *Manager) SetTeamSlugs(cfg *config.Config) {
+	for teamName, teamCfg := range cfg.Teams {
+		if teamCfg.Slug != "" {
+			tm.slugs[teamName] = teamCfg.Slug
+		}
+	}
+}
+
+// teamSlug returns the GitHub slug of the given team name, falling back to
+// Slug for teams whose slug is unknown.
+func (tm *Manager) teamSlug(teamName string) string {
+	if slug, ok := tm.slugs[teamName]; ok {
+		return slug
+	}
+	return Slug(teamName)
+}
+
+// SetCustomFields sets the additional fields retrieved for teams and members
+// into their Metadata.
+func (tm *Manager) SetCustomFields(customFields config.CustomFields) {
+	tm.customFields = customFields
+}
+
+// SetVerifyTimeout sets how long to wait for membership changes to be
+// reflected by GitHub after submitting them, 0 to not verify them.
+func (tm *Manager) SetVerifyTimeout(timeout time.Duration) {
+	tm.verifyTimeout = timeout
+}
+
 // GetCurrentConfig returns a *config.Config by querying the organization teams.
-// It will not populate the excludedMembers from CodeReviewAssignments as GH
-// does not provide an API of such field.
+// It will not populate the excludedMembers nor the ReviewAssignmentOptions
+// from CodeReviewAssignments as GH does not provide an API of such fields,
+// they are tracked in Config.AppliedExclusions and Config.AppliedReviewOptions
+// instead.
 func (tm *Manager) GetCurrentConfig(ctx context.Context) (*config.Config, error) {
+	c, _, err := tm.fetchConfig(ctx)
+	return c, err
+}
+
+// fetchConfig returns the current config of the organization together with
+// the last time each team, keyed by its ID, was updated.
+func (tm *Manager) fetchConfig(ctx context.Context) (*config.Config, map[string]time.Time, error) {
 	c := &config.Config{
 		Organization: tm.owner,
 		Teams:        map[string]config.TeamConfig{},
 		Members:      map[string]config.User{},
 	}
 
-	variables := map[string]interface{}{}
-
-	result, err := tm.query(ctx, variables)
+	teams, err := tm.queryTeams(ctx, fullTeamQuery)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query github api: %w", err)
+		return nil, nil, fmt.Errorf("failed to query github api: %w", err)
 	}
 
-	requeryTeams := false
-	for {
-		if requeryTeams {
-			result, err = tm.query(ctx, variables)
-			if err != nil {
-				return nil, fmt.Errorf("failed to requery teams: %w", err)
-			}
-			requeryTeams = false
-		}
-
-		for _, t := range result.Organization.Teams.Nodes {
-			strTeamName := string(t.Name)
-			teamCfg, ok := c.Teams[strTeamName]
-			if !ok {
-				var cra config.CodeReviewAssignment
-				if t.ReviewRequestDelegationEnabled {
-					cra = config.CodeReviewAssignment{
-						Algorithm:       config.TeamReviewAssignmentAlgorithm(t.ReviewRequestDelegationAlgorithm),
-						Enabled:         bool(t.ReviewRequestDelegationEnabled),
-						NotifyTeam:      bool(t.ReviewRequestDelegationNotifyTeam),
-						TeamMemberCount: int(t.ReviewRequestDelegationMemberCount),
-					}
-				}
-				teamCfg = config.TeamConfig{
-					ID:                   fmt.Sprintf("%v", t.ID),
-					CodeReviewAssignment: cra,
-				}
+	teamsUpdatedAt := map[string]time.Time{}
+	for _, t := range teams {
+		teamCfg := newTeamConfig(t)
+		teamsUpdatedAt[teamCfg.ID] = t.UpdatedAt
+		for _, member := range t.Members.Nodes() {
+			teamCfg.Members = append(teamCfg.Members, member.Login)
+			if member.IsMaintainer() {
+				teamCfg.Maintainers = append(teamCfg.Maintainers, member.Login)
 			}
-
-			requeryMembers := false
-			for {
-				// Requery of members shouldn't override the teams result
-				innerResult := result
-				if requeryMembers {
-					innerResult, err = tm.query(ctx, variables)
-					if err != nil {
-						return nil, fmt.Errorf("failed to requery team members: %w", err)
-					}
-					requeryMembers = false
-				}
-				// Find team in result - especially important after requerying
-				teamNode, err := innerResult.Organization.Teams.WithID(t.ID)
-				if err != nil {
-					return nil, err
-				}
-				for _, member := range teamNode.Members.Nodes {
-					strLogin := string(member.Login)
-					teamCfg.Members = append(teamCfg.Members, strLogin)
-					c.Members[strLogin] = config.User{
-						ID:   fmt.Sprintf("%v", member.ID),
-						Name: string(member.Name),
-					}
-				}
-				sort.Strings(teamCfg.Members)
-				c.Teams[strTeamName] = teamCfg
-				if !teamNode.Members.PageInfo.HasNextPage {
-					break
-				}
-				requeryMembers = true
-				variables["membersCursor"] = githubv4.NewString(teamNode.Members.PageInfo.EndCursor)
+			c.Members[member.Login] = config.User{
+				ID:   member.ID,
+				Name: member.Name,
 			}
 		}
-		if !result.Organization.Teams.PageInfo.HasNextPage {
-			break
-		}
-		requeryTeams = true
-		variables["teamsCursor"] = githubv4.NewString(result.Organization.Teams.PageInfo.EndCursor)
-		// Clear the membersCursor as we are only using it when querying over members
-		variables["membersCursor"] = (*githubv4.String)(nil)
+		sort.Strings(teamCfg.Members)
+		sort.Strings(teamCfg.Maintainers)
+		c.Teams[t.Name] = teamCfg
+	}
+	if err := tm.fetchMetadata(ctx, c); err != nil {
+		return nil, nil, fmt.Errorf("failed to query custom fields: %w", err)
 	}
-	return c, nil
+	return c, teamsUpdatedAt, nil
 }
 
-func (tm *Manager) query(ctx context.Context, additionalVariables map[string]interface{}) (queryResult, error) {
-	var q queryResult
-	variables := map[string]interface{}{
-		"repositoryOwner": githubv4.String(tm.owner),
-		"teamsCursor":     (*githubv4.String)(nil), // Null after argument to get first page.
-		"membersCursor":   (*githubv4.String)(nil), // Null after argument to get first page.
+// newTeamConfig returns the TeamConfig, without members, of the given team.
+func newTeamConfig(t team) config.TeamConfig {
+	var cra config.CodeReviewAssignment
+	if t.ReviewRequestDelegationEnabled {
+		cra = config.CodeReviewAssignment{
+			Algorithm:       config.TeamReviewAssignmentAlgorithm(t.ReviewRequestDelegationAlgorithm),
+			Enabled:         t.ReviewRequestDelegationEnabled,
+			NotifyTeam:      t.ReviewRequestDelegationNotifyTeam,
+			TeamMemberCount: t.ReviewRequestDelegationMemberCount,
+		}
 	}
-
-	for k, v := range additionalVariables {
-		variables[k] = v
+	teamCfg := config.TeamConfig{
+		ID:                   t.ID,
+		Slug:                 t.Slug,
+		Description:          t.Description,
+		Privacy:              config.TeamPrivacy(t.Privacy),
+		NotificationSetting:  config.TeamNotificationSetting(t.NotificationSetting),
+		CodeReviewAssignment: cra,
 	}
-
-	err := tm.gqlGHClient.Query(ctx, &q, variables)
-	if err != nil {
-		return queryResult{}, err
+	if t.ParentTeam != nil {
+		teamCfg.Parent = t.ParentTeam.Name
 	}
-
-	return q, nil
-}
-
-//	{
-//	 organization(login: "cilium") {
-//	   teams(first: 100) {
-//	     nodes {
-//	       members(first: 100) {
-//	         nodes {
-//	           id
-//	           login
-//	         }
-//	       }
-//	     }
-//	   }
-//	 }
-//	}
-type queryResult struct {
-	Organization struct {
-		Teams Teams `graphql:"teams(first: 100, after: $teamsCursor)"`
-	} `graphql:"organization(login: $repositoryOwner)"`
+	return teamCfg
 }
 
-type Teams struct {
-	Nodes    []team
-	PageInfo struct {
-		EndCursor   githubv4.String
-		HasNextPage githubv4.Boolean
+// SyncTeamMembers adds, removes and changes the role of the members of the
+// given team name as set in change.
+func (tm *Manager) SyncTeamMembers(ctx context.Context, teamName string, change TeamChange) error {
+	for _, user := range change.Add {
+		role := change.role(user)
+		tm.reporter.Progress("Adding %s %s to team %s", role, user, teamName)
+		membership, _, err := tm.ghClient.Teams.AddTeamMembershipBySlug(ctx, tm.owner, tm.teamSlug(teamName), user, &gh.TeamAddTeamMembershipOptions{Role: role})
+		if err != nil {
+			return err
+		}
+		// Users that aren't members of the organization are only added
+		// once they accept the invitation to it.
+		if membership.GetState() == "pending" {
+			tm.reporter.Progress("Membership of %s in team %s is pending until %s accepts the invitation to the organization", user, teamName, user)
+		}
 	}
-}
-
-func (t Teams) WithID(id githubv4.ID) (team, error) {
-	for _, n := range t.Nodes {
-		if n.ID == id {
-			return n, nil
+	for _, user := range change.Remove {
+		tm.reporter.Progress("Removing member %s from team %s", user, teamName)
+		if _, err := tm.ghClient.Teams.RemoveTeamMembershipBySlug(ctx, tm.owner, tm.teamSlug(teamName), user); err != nil {
+			return err
 		}
 	}
+	// Adding an existing member updates its role.
+	for _, user := range set.DifferenceFold(change.Promote, change.Add) {
+		tm.reporter.Progress("Changing role of %s in team %s to maintainer", user, teamName)
+		if _, _, err := tm.ghClient.Teams.AddTeamMembershipBySlug(ctx, tm.owner, tm.teamSlug(teamName), user, &gh.TeamAddTeamMembershipOptions{Role: "maintainer"}); err != nil {
+			return err
+		}
+	}
+	for _, user := range change.Demote {
+		tm.reporter.Progress("Changing role of %s in team %s to member", user, teamName)
+		if _, _, err := tm.ghClient.Teams.AddTeamMembershipBySlug(ctx, tm.owner, tm.teamSlug(teamName), user, &gh.TeamAddTeamMembershipOptions{Role: "member"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	return team{}, fmt.Errorf("team with id %q not found", id)
+// EditTeam updates the settings of the given team name set in edit.
+func (tm *Manager) EditTeam(ctx context.Context, teamName string, edit TeamEdit) error {
+	t := teamRequest{
+		NewTeam: gh.NewTeam{
+			Name:        teamName,
+			Description: edit.Description,
+		},
+	}
+	if edit.Privacy != nil {
+		privacy := restPrivacy(*edit.Privacy)
+		t.Privacy = &privacy
+	}
+	if edit.NotificationSetting != nil {
+		setting := restNotificationSetting(*edit.NotificationSetting)
+		t.NotificationSetting = &setting
+	}
+	req, err := tm.ghClient.NewRequest("PATCH", fmt.Sprintf("orgs/%s/teams/%s", tm.owner, tm.teamSlug(teamName)), t)
+	if err != nil {
+		return err
+	}
+	_, err = tm.ghClient.Do(ctx, req, nil)
+	return err
 }
 
-type team struct {
-	Members struct {
-		Nodes    []teamMember
-		PageInfo struct {
-			EndCursor   githubv4.String
-			HasNextPage githubv4.Boolean
-		}
-	} `graphql:"members(first: 100, after: $membersCursor)"`
-	ID                                 githubv4.ID
-	DatabaseID                         githubv4.Int
-	Name                               githubv4.String
-	ReviewRequestDelegationEnabled     githubv4.Boolean
-	ReviewRequestDelegationAlgorithm   githubv4.String
-	ReviewRequestDelegationMemberCount githubv4.Int
-	ReviewRequestDelegationNotifyTeam  githubv4.Boolean
+// teamRequest is the body of the REST API requests creating and editing
+// teams, go-github doesn't support the notification setting of teams.
+type teamRequest struct {
+	gh.NewTeam
+	NotificationSetting *string `json:"notification_setting,omitempty"`
 }
 
-type teamMember struct {
-	ID    githubv4.ID
-	Login githubv4.String
-	Name  githubv4.String
+// restNotificationSetting returns the REST API notification setting of the
+// given config notification setting.
+func restNotificationSetting(setting config.TeamNotificationSetting) string {
+	return strings.ToLower(string(setting))
 }
 
-// SyncTeamMembers adds and removes the given login names into the given team
-// name.
-func (tm *Manager) SyncTeamMembers(ctx context.Context, teamName string, add, remove []string) error {
-	for _, user := range add {
-		fmt.Printf("Adding member %s to team %s\n", user, teamName)
-		if _, _, err := tm.ghClient.Teams.AddTeamMembershipBySlug(ctx, tm.owner, slug(teamName), user, &gh.TeamAddTeamMembershipOptions{Role: "member"}); err != nil {
-			return err
-		}
-	}
-	for _, user := range remove {
-		fmt.Printf("Removing member %s from team %s\n", user, teamName)
-		if _, err := tm.ghClient.Teams.RemoveTeamMembershipBySlug(ctx, tm.owner, slug(teamName), user); err != nil {
-			return err
-		}
+// restPrivacy returns the REST API privacy of the given config privacy.
+func restPrivacy(privacy config.TeamPrivacy) string {
+	// The REST API calls visible teams closed.
+	if privacy == config.TeamPrivacySecret {
+		return "secret"
 	}
-	return nil
+	return "closed"
 }
 
 // SyncTeamReviewAssignment updates the review assignment into GH for the given
@@ -256,39 +276,98 @@ func (tm *Manager) SyncTeams(ctx context.Context, localCfg *config.Config, force
 		return nil, err
 	}
 
-	type teamChange struct {
-		add, remove []string
+	skipped, err := tm.resolveRenames(ctx, localCfg, upstreamCfg, force, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err := tm.resolveLoginRenames(ctx, localCfg, force); err != nil {
+		return nil, err
 	}
-	teamChanges := map[string]teamChange{}
-
-	for localTeamName, localTeam := range localCfg.Teams {
-		// Since we can't get the list of excluded members from GH we have
-		// to back it up and re-added it again at the end of this for-loop.
-		backExcludedMembers := localTeam.CodeReviewAssignment.ExcludedMembers
 
-		localTeam.CodeReviewAssignment.ExcludedMembers = nil
-		if !reflect.DeepEqual(localTeam, upstreamCfg.Teams[localTeamName]) {
-			cmp := comparator.CompareWithNames(localTeam, upstreamCfg.Teams[localTeamName], "local", "remote")
-			fmt.Printf("Local config out of sync with upstream: %s\n", cmp)
-			toAdd := slices.NotIn(localTeam.Members, upstreamCfg.Teams[localTeamName].Members)
-			toDel := slices.NotIn(upstreamCfg.Teams[localTeamName].Members, localTeam.Members)
-			if len(toAdd) != 0 || len(toDel) != 0 {
-				teamChanges[localTeamName] = teamChange{
-					add:    toAdd,
-					remove: toDel,
-				}
+	now := time.Now()
+	effectiveCfg := EffectiveConfig(localCfg, upstreamCfg, now)
+	plan := ComputePlan(effectiveCfg, upstreamCfg)
+	for _, teamName := range skipped {
+		plan.dropTeam(teamName)
+	}
+	if err := tm.PlanInvitations(ctx, localCfg, plan); err != nil {
+		tm.reporter.Warn("Unable to check organization membership of added users: %s", github.TranslateError(err))
+	}
+	if len(plan.Diffs) != 0 {
+		tm.reporter.Plan(PlanEvent{Changes: plan.Diffs, Print: plan.PrintDiffs})
+	}
+	for _, teamName := range sortedKeys(plan.UnresolvedExclusions) {
+		for _, login := range plan.UnresolvedExclusions[teamName] {
+			tm.reporter.Error("User %q excluded from team %s not found in the list of team members in the organization", login, teamName)
+		}
+	}
+	summary := Summary{DryRun: dryRun}
+	copyMetadata(localCfg, upstreamCfg, tm.customFields)
+	copySlugs(localCfg, upstreamCfg)
+
+	if len(localCfg.PendingRemovals) != 0 {
+		tm.reporter.Plan(PlanEvent{
+			Title:   "Pending removals",
+			Changes: localCfg.PendingRemovals,
+			Print:   func(w io.Writer) { PrintPendingRemovals(w, localCfg) },
+		})
+	}
+	if expired := ExpiredExclusions(localCfg, now); len(expired) != 0 {
+		tm.reporter.Plan(PlanEvent{
+			Title:   "Expired code review exclusions, ignored",
+			Changes: expired,
+			Print:   func(w io.Writer) { PrintExclusions(w, expired) },
+		})
+	}
+	if expiring := ExpiringExclusions(localCfg, now, ExpiringDays); len(expiring) != 0 {
+		tm.reporter.Plan(PlanEvent{
+			Title:   fmt.Sprintf("Code review exclusions expiring within %d days", ExpiringDays),
+			Changes: expiring,
+			Print:   func(w io.Writer) { PrintExclusions(w, expiring) },
+		})
+	}
+	if HasReducedReviewCapacity(localCfg) {
+		capacities := map[string]int{}
+		for login, user := range localCfg.Members {
+			if user.ReviewCapacity != 0 && user.ReviewCapacity < 100 {
+				capacities[login] = user.ReviewCapacity
 			}
 		}
-		localTeam.CodeReviewAssignment.ExcludedMembers = backExcludedMembers
+		tm.reporter.Plan(PlanEvent{
+			Title:   "Review capacity rotation",
+			Changes: capacities,
+			Print:   func(w io.Writer) { PrintReviewCapacity(w, localCfg, now) },
+		})
 	}
 
-	if len(teamChanges) != 0 {
-		fmt.Printf("Going to submit the following changes:\n")
-		for teamName, teamCfg := range teamChanges {
-			fmt.Printf(" Team: %s\n", teamName)
-			fmt.Printf("    Adding members: %s\n", strings.Join(teamCfg.add, ", "))
-			fmt.Printf("  Removing members: %s\n", strings.Join(teamCfg.remove, ", "))
+	if len(plan.NewTeams) != 0 {
+		tm.reporter.Plan(PlanEvent{Title: "Going to create the following teams", Changes: plan.NewTeams, Print: plan.PrintNewTeams})
+		yes := force
+		if !force {
+			yes, err = terminal.AskForConfirmation("Continue?")
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !yes {
+			// The members and review assignments of the teams can't be
+			// synced without creating them first.
+			plan.dropNewTeams()
+		} else if !dryRun {
+			created, failed := tm.createTeams(ctx, plan)
+			summary.Submitted += len(created)
+			summary.Failed += failed
+			for teamName, id := range created {
+				teamCfg := localCfg.Teams[teamName]
+				teamCfg.ID = id
+				teamCfg.Slug = tm.teamSlug(teamName)
+				localCfg.Teams[teamName] = teamCfg
+			}
 		}
+	}
+
+	if len(plan.TeamChanges) != 0 {
+		tm.reporter.Plan(PlanEvent{Title: "Going to submit the following changes", Changes: plan.TeamChanges, Print: plan.PrintTeamChanges})
 		yes := force
 		if !force {
 			yes, err = terminal.AskForConfirmation("Continue?")
@@ -297,21 +376,27 @@ func (tm *Manager) SyncTeams(ctx context.Context, localCfg *config.Config, force
 			}
 		}
 		if yes {
-			for teamName, teamCfg := range teamChanges {
+			summary.Failed += tm.sendInvitations(ctx, plan, dryRun)
+			applied := map[string]TeamChange{}
+			for teamName, teamCfg := range plan.TeamChanges {
 				if !dryRun {
-					if err := tm.SyncTeamMembers(ctx, teamName, teamCfg.add, teamCfg.remove); err != nil {
-						fmt.Fprintf(os.Stderr, "[ERROR]:  Unable to sync team %s: %s\n", teamName, err)
+					if err := tm.SyncTeamMembers(ctx, teamName, teamCfg); err != nil {
+						tm.reporter.Error("Unable to sync team %s: %s", teamName, github.TranslateError(err))
+						summary.Failed++
 						continue
 					}
+					tm.verifyTeamChange(ctx, teamName, withoutInvitees(teamCfg, plan.Invitations))
 				}
+				summary.Submitted++
+				applied[teamName] = teamCfg
 				teamMembers := map[string]struct{}{}
 				for _, member := range localCfg.Teams[teamName].Members {
 					teamMembers[member] = struct{}{}
 				}
-				for _, rmMember := range teamCfg.remove {
+				for _, rmMember := range teamCfg.Remove {
 					delete(teamMembers, rmMember)
 				}
-				for _, addMember := range teamCfg.add {
+				for _, addMember := range teamCfg.Add {
 					teamMembers[addMember] = struct{}{}
 				}
 				team := localCfg.Teams[teamName]
@@ -321,55 +406,281 @@ func (tm *Manager) SyncTeams(ctx context.Context, localCfg *config.Config, force
 				}
 				localCfg.Teams[teamName] = team
 			}
+			if removals := OrgRemovals(upstreamCfg, applied); tm.removeFromOrg && len(removals) != 0 {
+				if err := tm.removeOrgMembers(ctx, removals, force, dryRun); err != nil {
+					return nil, err
+				}
+			}
 		}
 	}
 
-	yes := force
-	if !force {
-		yes, err = terminal.AskForConfirmation("Do you want to update CodeReviewAssignments?")
+	if len(plan.TeamEdits) != 0 {
+		tm.reporter.Plan(PlanEvent{Title: "Going to update the following team settings", Changes: plan.TeamEdits, Print: plan.PrintTeamEdits})
+		yes := force
+		if !force {
+			yes, err = terminal.AskForConfirmation("Continue?")
+			if err != nil {
+				return nil, err
+			}
+		}
+		if yes {
+			for _, teamName := range sortedKeys(plan.TeamEdits) {
+				if dryRun {
+					summary.Submitted++
+					continue
+				}
+				if err := tm.EditTeam(ctx, teamName, plan.TeamEdits[teamName]); err != nil {
+					tm.reporter.Error("Unable to update settings of team %s: %s", teamName, github.TranslateError(err))
+					summary.Failed++
+					continue
+				}
+				summary.Submitted++
+			}
+		}
+	}
+
+	if len(localCfg.Owners) != 0 {
+		if err := tm.syncOwners(ctx, localCfg.Owners, force, dryRun); err != nil {
+			return nil, err
+		}
+	}
+
+	if localCfg.Settings != (config.OrgSettings{}) {
+		submitted, failed, err := tm.syncOrgSettings(ctx, localCfg.Settings, force, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		summary.Submitted += submitted
+		summary.Failed += failed
+	}
+
+	if len(localCfg.SecurityManagers) != 0 {
+		submitted, failed, err := tm.syncSecurityManagers(ctx, localCfg, force, dryRun)
 		if err != nil {
 			return nil, err
 		}
+		summary.Submitted += submitted
+		summary.Failed += failed
 	}
-	if yes {
-		teamNames := make([]string, 0, len(localCfg.Teams))
-		for teamName := range localCfg.Teams {
-			teamNames = append(teamNames, teamName)
+
+	if len(localCfg.Labels) != 0 {
+		submitted, failed, err := tm.syncLabels(ctx, localCfg, force, dryRun)
+		if err != nil {
+			return nil, err
 		}
-		sort.Strings(teamNames)
-		for _, teamName := range teamNames {
-			storedTeam := localCfg.Teams[teamName]
-			cra := storedTeam.CodeReviewAssignment
-			usersIDs := getExcludedUsers(teamName, localCfg.Members, cra.ExcludedMembers, localCfg.ExcludeCRAFromAllTeams)
+		summary.Submitted += submitted
+		summary.Failed += failed
+	}
 
-			input := github.UpdateTeamReviewAssignmentInput{
-				Algorithm:             cra.Algorithm,
-				Enabled:               githubv4.Boolean(cra.Enabled),
-				ExcludedTeamMemberIDs: usersIDs,
-				NotifyTeam:            githubv4.Boolean(cra.NotifyTeam),
-				TeamMemberCount:       githubv4.Int(cra.TeamMemberCount),
+	if len(localCfg.OutsideCollaborators) != 0 {
+		submitted, failed, err := tm.syncOutsideCollaborators(ctx, localCfg.OutsideCollaborators, force, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		summary.Submitted += submitted
+		summary.Failed += failed
+	}
+
+	submitted, failed, err := tm.syncTeamRepositories(ctx, localCfg, force, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	summary.Submitted += submitted
+	summary.Failed += failed
+
+	if len(plan.ReviewAssignments) != 0 {
+		tm.reporter.Plan(PlanEvent{
+			Title:   "Going to update the following code review assignments",
+			Changes: plan.ReviewAssignmentDiffs,
+			Print: func(w io.Writer) {
+				for _, teamName := range sortedKeys(plan.ReviewAssignmentDiffs) {
+					fmt.Fprintf(w, " Team: %s: %s\n", teamName, plan.ReviewAssignmentDiffs[teamName])
+				}
+			},
+		})
+		yes := force
+		if !force {
+			yes, err = terminal.AskForConfirmation("Do you want to update CodeReviewAssignments?")
+			if err != nil {
+				return nil, err
 			}
-			fmt.Printf("Excluding members from team: %s\n", teamName)
-			if !dryRun {
-				err := tm.SyncTeamReviewAssignment(ctx, storedTeam.ID, input)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "[ERROR]: Unable to sync team excluded members %s: %s\n", teamName, err)
+		}
+		if yes {
+			for _, teamName := range sortedKeys(plan.ReviewAssignments) {
+				tm.reporter.Progress("Excluding members from team: %s", teamName)
+				if !dryRun {
+					input := plan.ReviewAssignments[teamName]
+					err := tm.SyncTeamReviewAssignment(ctx, localCfg.Teams[teamName].ID, input)
+					if err != nil {
+						tm.reporter.Error("Unable to sync team excluded members %s: %s", teamName, github.TranslateError(err))
+						summary.Failed++
+						continue
+					}
+					recordAppliedReviewAssignment(localCfg, teamName, excludedLogins(effectiveCfg, input.ExcludedTeamMemberIDs))
 				}
+				summary.Submitted++
 			}
 		}
 	}
+	for teamName := range localCfg.AppliedExclusions {
+		if _, ok := localCfg.Teams[teamName]; !ok {
+			delete(localCfg.AppliedExclusions, teamName)
+		}
+	}
+	for teamName := range localCfg.AppliedReviewOptions {
+		if _, ok := localCfg.Teams[teamName]; !ok {
+			delete(localCfg.AppliedReviewOptions, teamName)
+		}
+	}
 
+	tm.reporter.Summary(summary)
 	return localCfg, nil
 }
 
+// ApplyPlan submits the changes of the given plan to GitHub without asking
+// for confirmation. Failing changes are reported and do not prevent the
+// remaining changes from being submitted.
+//
+// With revalidate, the current upstream state is re-read before every
+// change: member changes already made upstream are skipped, and review
+// assignments changed upstream since the plan was computed are reported as
+// conflicts and not overwritten.
+//
+// The IDs and slugs of the created teams and the members excluded from the
+// review assignments that were applied are recorded into the local
+// configuration localCfg, see AppliedExclusions.
+func (tm *Manager) ApplyPlan(ctx context.Context, localCfg *config.Config, plan *Plan, revalidate, dryRun bool) error {
+	var submitted, failed, conflicts int
+	if len(plan.NewTeams) != 0 {
+		if dryRun {
+			for _, teamName := range sortedKeys(plan.NewTeams) {
+				tm.reporter.Progress("Creating team: %s", teamName)
+			}
+			submitted += len(plan.NewTeams)
+		} else {
+			var created map[string]string
+			created, failed = tm.createTeams(ctx, plan)
+			submitted += len(created)
+			for teamName, id := range created {
+				teamCfg, ok := localCfg.Teams[teamName]
+				if !ok {
+					continue
+				}
+				teamCfg.ID = id
+				teamCfg.Slug = tm.teamSlug(teamName)
+				localCfg.Teams[teamName] = teamCfg
+			}
+		}
+	}
+	if len(plan.TeamChanges) != 0 {
+		failed += tm.sendInvitations(ctx, plan, dryRun)
+	}
+	for _, teamName := range sortedKeys(plan.TeamChanges) {
+		teamCfg := plan.TeamChanges[teamName]
+		if revalidate {
+			var err error
+			teamCfg, err = tm.revalidateTeamChange(ctx, teamName, teamCfg)
+			if err != nil {
+				tm.reporter.Error("Unable to revalidate changes of team %s: %s", teamName, github.TranslateError(err))
+				failed++
+				continue
+			}
+		}
+		if dryRun {
+			submitted++
+			continue
+		}
+		if err := tm.SyncTeamMembers(ctx, teamName, teamCfg); err != nil {
+			tm.reporter.Error("Unable to sync team %s: %s", teamName, github.TranslateError(err))
+			failed++
+			continue
+		}
+		submitted++
+		tm.verifyTeamChange(ctx, teamName, withoutInvitees(teamCfg, plan.Invitations))
+	}
+	for _, teamName := range sortedKeys(plan.TeamEdits) {
+		tm.reporter.Progress("Updating settings of team: %s", teamName)
+		if dryRun {
+			submitted++
+			continue
+		}
+		if err := tm.EditTeam(ctx, teamName, plan.TeamEdits[teamName]); err != nil {
+			tm.reporter.Error("Unable to update settings of team %s: %s", teamName, github.TranslateError(err))
+			failed++
+			continue
+		}
+		submitted++
+	}
+	for _, teamName := range sortedKeys(plan.ReviewAssignments) {
+		input := plan.ReviewAssignments[teamName]
+		if revalidate {
+			if err := tm.revalidateReviewAssignment(ctx, teamName, plan.UpstreamReviewAssignments[teamName]); err != nil {
+				tm.reporter.Error("Skipping code review assignment of team %s: %s", teamName, err)
+				conflicts++
+				continue
+			}
+		}
+		tm.reporter.Progress("Updating code review assignment of team: %s", teamName)
+		if dryRun {
+			submitted++
+			continue
+		}
+		if err := tm.SyncTeamReviewAssignment(ctx, input.ID, input); err != nil {
+			tm.reporter.Error("Unable to sync code review assignment of team %s: %s", teamName, github.TranslateError(err))
+			failed++
+			continue
+		}
+		if _, ok := localCfg.Teams[teamName]; ok {
+			recordAppliedReviewAssignment(localCfg, teamName, excludedLogins(localCfg, input.ExcludedTeamMemberIDs))
+		}
+		submitted++
+	}
+	tm.reporter.Summary(Summary{Submitted: submitted, Failed: failed + conflicts, DryRun: dryRun})
+	if failed != 0 || conflicts != 0 {
+		return fmt.Errorf("%d changes failed, %d conflicts with upstream changes", failed, conflicts)
+	}
+	return nil
+}
+
+// recordAppliedReviewAssignment records the given logins as the members
+// excluded from the code review assignment of the given team of cfg, together
+// with the options of that assignment that are set. Unset options were left
+// untouched and keep their recorded value.
+func recordAppliedReviewAssignment(cfg *config.Config, teamName string, logins []string) {
+	if cfg.AppliedExclusions == nil {
+		cfg.AppliedExclusions = map[string][]string{}
+	}
+	cfg.AppliedExclusions[teamName] = logins
+
+	local := cfg.Teams[teamName].CodeReviewAssignment
+	options := cfg.AppliedReviewOptions[teamName]
+	if local.CountMembersAlreadyRequested != nil {
+		options.CountMembersAlreadyRequested = local.CountMembersAlreadyRequested
+	}
+	if local.IncludeChildTeamMembers != nil {
+		options.IncludeChildTeamMembers = local.IncludeChildTeamMembers
+	}
+	if local.RemoveTeamRequest != nil {
+		options.RemoveTeamRequest = local.RemoveTeamRequest
+	}
+	if options == (config.ReviewAssignmentOptions{}) {
+		return
+	}
+	if cfg.AppliedReviewOptions == nil {
+		cfg.AppliedReviewOptions = map[string]config.ReviewAssignmentOptions{}
+	}
+	cfg.AppliedReviewOptions[teamName] = options
+}
+
 // getExcludedUsers returns a list of all users that should be excluded for the
-// given team.
-func getExcludedUsers(teamName string, members map[string]config.User, excTeamMembers []config.ExcludedMember, excAllTeams []string) []githubv4.ID {
+// given team, and the logins of the excluded team members missing in members.
+func getExcludedUsers(members map[string]config.User, excTeamMembers []config.ExcludedMember, excAllTeams []string) ([]githubv4.ID, []string) {
 	m := make(map[githubv4.ID]struct{}, len(excTeamMembers)+len(excAllTeams))
+	var unresolved []string
 	for _, member := range excTeamMembers {
 		user, ok := members[member.Login]
 		if !ok {
-			fmt.Printf("[ERROR] user %q from team %s, not found in the list of team members in the organization\n", member.Login, teamName)
+			unresolved = append(unresolved, member.Login)
 			continue
 		}
 		m[user.ID] = struct{}{}
@@ -387,19 +698,25 @@ func getExcludedUsers(teamName string, members map[string]config.User, excTeamMe
 	for memberID := range m {
 		memberIDs = append(memberIDs, memberID)
 	}
-	return memberIDs
+	return memberIDs, unresolved
 }
 
-// slug returns the slug version of the team name. This simply replaces all
-// characters that are not in the following regex `[^a-z0-9]+` with a `-`.
-// It's a simplistic versions of the official's GitHub slug transformation since
-// GitHub changes accents characters as well, for example 'ä' to 'a'.
-func slug(s string) string {
-	s = strings.ToLower(s)
-
-	re := regexp.MustCompile("[^a-z0-9]+")
-	s = re.ReplaceAllString(s, "-")
+// copySlugs sets the slugs of the teams of upstreamCfg into the teams of
+// localCfg.
+func copySlugs(localCfg, upstreamCfg *config.Config) {
+	for teamName, teamCfg := range localCfg.Teams {
+		if upstreamTeam, ok := upstreamCfg.Teams[teamName]; ok {
+			teamCfg.Slug = upstreamTeam.Slug
+			localCfg.Teams[teamName] = teamCfg
+		}
+	}
+}
 
-	s = strings.Trim(s, "-")
-	return s
+// TeamSlug returns the GitHub slug of the given team of cfg, falling back to
+// Slug for teams whose slug wasn't retrieved from GitHub yet.
+func TeamSlug(cfg *config.Config, teamName string) string {
+	if slug := cfg.Teams[teamName].Slug; slug != "" {
+		return slug
+	}
+	return Slug(teamName)
 }
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// MembershipViolation is a member belonging to too many teams or to more
+// than one team of a group of mutually exclusive teams.
+type MembershipViolation struct {
+	Login  string
+	Teams  []string
+	Reason string
+}
+
+// CheckMemberships returns the members of cfg belonging to more than
+// maxTeams teams, unless maxTeams is 0, or to more than one team of any of
+// the exclusive teams of the policy of cfg, sorted by login.
+func CheckMemberships(cfg *config.Config, maxTeams int) []MembershipViolation {
+	memberTeams := map[string][]string{}
+	for _, teamName := range sortedKeys(cfg.Teams) {
+		for _, login := range cfg.Teams[teamName].Members {
+			memberTeams[login] = append(memberTeams[login], teamName)
+		}
+	}
+
+	var violations []MembershipViolation
+	for _, login := range sortedKeys(memberTeams) {
+		teams := memberTeams[login]
+		if maxTeams != 0 && len(teams) > maxTeams {
+			violations = append(violations, MembershipViolation{
+				Login:  login,
+				Teams:  teams,
+				Reason: fmt.Sprintf("member of %d teams, more than %d", len(teams), maxTeams),
+			})
+		}
+		for _, group := range cfg.Policy.ExclusiveTeams {
+			var exclusive []string
+			for _, teamName := range group {
+				for _, t := range teams {
+					if t == teamName {
+						exclusive = append(exclusive, teamName)
+						break
+					}
+				}
+			}
+			if len(exclusive) > 1 {
+				sort.Strings(exclusive)
+				violations = append(violations, MembershipViolation{
+					Login:  login,
+					Teams:  exclusive,
+					Reason: fmt.Sprintf("member of mutually exclusive teams %s", strings.Join(group, ", ")),
+				})
+			}
+		}
+	}
+	return violations
+}
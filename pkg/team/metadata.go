@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+)
+
+// fetchMetadata retrieves the custom fields of the teams and members of c
+// into their Metadata.
+func (tm *Manager) fetchMetadata(ctx context.Context, c *config.Config) error {
+	if len(tm.customFields.Team) != 0 {
+		nodes, err := tm.queryOrganizationNodes(ctx, "teams", "name", tm.customFields.Team)
+		if err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			teamName, _ := github.ValueAt(node, "name")
+			teamCfg, ok := c.Teams[teamName]
+			if !ok {
+				continue
+			}
+			teamCfg.Metadata = metadata(node, tm.customFields.Team)
+			c.Teams[teamName] = teamCfg
+		}
+	}
+	if len(tm.customFields.Member) != 0 {
+		nodes, err := tm.queryOrganizationNodes(ctx, "membersWithRole", "login", tm.customFields.Member)
+		if err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			login, _ := github.ValueAt(node, "login")
+			user, ok := c.Members[login]
+			if !ok {
+				continue
+			}
+			user.Metadata = metadata(node, tm.customFields.Member)
+			c.Members[login] = user
+		}
+	}
+	return nil
+}
+
+// queryOrganizationNodes returns all nodes of the given connection of the
+// organization, selecting the key field and the given field paths.
+func (tm *Manager) queryOrganizationNodes(ctx context.Context, connection, key string, paths []string) ([]map[string]interface{}, error) {
+	nodeFields := github.NewField("", github.NewField(key))
+	for _, path := range paths {
+		nodeFields.AddPath(path)
+	}
+	query := github.BuildQuery(
+		map[string]string{"owner": "String!", "cursor": "String"},
+		github.NewField("organization",
+			github.Connection(connection, "first: 100, after: $cursor", nodeFields.Fields...),
+		).WithArgs("login: $owner"),
+	)
+
+	var result []map[string]interface{}
+	variables := map[string]interface{}{
+		"owner":  tm.owner,
+		"cursor": nil,
+	}
+	for {
+		var q struct {
+			Organization map[string]github.ConnectionResult[map[string]interface{}]
+		}
+		if err := tm.gqlGHClient.QueryRaw(ctx, query, variables, &q); err != nil {
+			return nil, err
+		}
+		conn := q.Organization[connection]
+		result = append(result, conn.Nodes...)
+		if !conn.PageInfo.HasNextPage {
+			return result, nil
+		}
+		variables["cursor"] = conn.PageInfo.EndCursor
+	}
+}
+
+// metadata returns the values of the given field paths of node.
+func metadata(node map[string]interface{}, paths []string) map[string]string {
+	m := make(map[string]string, len(paths))
+	for _, path := range paths {
+		if v, ok := github.ValueAt(node, path); ok {
+			m[path] = v
+		}
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// copyMetadata copies the custom fields in the Metadata of the teams and
+// members of upstreamCfg into localCfg. Other Metadata of localCfg, e.g. the
+// SSO identities of members, is kept.
+func copyMetadata(localCfg, upstreamCfg *config.Config, customFields config.CustomFields) {
+	for teamName, teamCfg := range localCfg.Teams {
+		teamCfg.Metadata = mergeMetadata(teamCfg.Metadata, upstreamCfg.Teams[teamName].Metadata, customFields.Team)
+		localCfg.Teams[teamName] = teamCfg
+	}
+	for login, user := range localCfg.Members {
+		user.Metadata = mergeMetadata(user.Metadata, upstreamCfg.Members[login].Metadata, customFields.Member)
+		localCfg.Members[login] = user
+	}
+}
+
+// mergeMetadata returns local with the given paths replaced by their values
+// in upstream.
+func mergeMetadata(local, upstream map[string]string, paths []string) map[string]string {
+	m := make(map[string]string, len(local)+len(upstream))
+	for k, v := range local {
+		m[k] = v
+	}
+	for _, path := range paths {
+		delete(m, path)
+	}
+	for k, v := range upstream {
+		m[k] = v
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// MemberMove is the result of moving members from a team to another one.
+type MemberMove struct {
+	// Changes are the net membership changes of both teams: members that
+	// already belonged to the destination team are only removed from the
+	// source team.
+	Changes map[string]TeamChange
+	// Exclusions are the code review assignment exclusions moved along with
+	// the members.
+	Exclusions []config.ExcludedMember
+}
+
+// MoveMembersInConfig moves the given members of team from to team to in cfg,
+// together with their exclusions from the code review assignment. Maintainers
+// of from stay maintainers if the roles of team to are managed. Nothing is
+// changed if any of the members doesn't belong to from.
+func MoveMembersInConfig(cfg *config.Config, logins []string, from, to string) (MemberMove, error) {
+	fromCfg, ok := cfg.Teams[from]
+	if !ok {
+		return MemberMove{}, fmt.Errorf("unknown team %q", from)
+	}
+	toCfg, ok := cfg.Teams[to]
+	if !ok {
+		return MemberMove{}, fmt.Errorf("unknown team %q", to)
+	}
+	if from == to {
+		return MemberMove{}, fmt.Errorf("members can't be moved to the team they belong to")
+	}
+	if missing := set.DifferenceFold(logins, fromCfg.Members); len(missing) != 0 {
+		return MemberMove{}, fmt.Errorf("users %s are not members of team %q", strings.Join(missing, ", "), from)
+	}
+
+	moved := set.IntersectionFold(fromCfg.Members, logins)
+	maintainers := set.IntersectionFold(fromCfg.Maintainers, moved)
+	fromCfg.Members = set.DifferenceFold(fromCfg.Members, moved)
+	fromCfg.Maintainers = set.DifferenceFold(fromCfg.Maintainers, moved)
+
+	var staying, exclusions []config.ExcludedMember
+	for _, xMember := range fromCfg.CodeReviewAssignment.ExcludedMembers {
+		if set.ContainsFold(moved, xMember.Login) {
+			exclusions = append(exclusions, xMember)
+		} else {
+			staying = append(staying, xMember)
+		}
+	}
+	fromCfg.CodeReviewAssignment.ExcludedMembers = staying
+
+	added := set.DifferenceFold(moved, toCfg.Members)
+	toCfg.Members = append(toCfg.Members, added...)
+	sort.Strings(toCfg.Members)
+	var promoted []string
+	if len(toCfg.Maintainers) != 0 {
+		promoted = set.DifferenceFold(maintainers, toCfg.Maintainers)
+		toCfg.Maintainers = append(toCfg.Maintainers, promoted...)
+		sort.Strings(toCfg.Maintainers)
+	}
+	excluded := make([]string, 0, len(toCfg.CodeReviewAssignment.ExcludedMembers))
+	for _, xMember := range toCfg.CodeReviewAssignment.ExcludedMembers {
+		excluded = append(excluded, xMember.Login)
+	}
+	for _, xMember := range exclusions {
+		if !set.ContainsFold(excluded, xMember.Login) {
+			toCfg.CodeReviewAssignment.ExcludedMembers = append(toCfg.CodeReviewAssignment.ExcludedMembers, xMember)
+		}
+	}
+
+	cfg.Teams[from] = fromCfg
+	cfg.Teams[to] = toCfg
+
+	move := MemberMove{
+		Changes:    map[string]TeamChange{from: {Remove: moved}},
+		Exclusions: exclusions,
+	}
+	if len(added) != 0 || len(promoted) != 0 {
+		move.Changes[to] = TeamChange{Add: added, Promote: promoted}
+	}
+	return move, nil
+}
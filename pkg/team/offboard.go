@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// SetRemoveFromOrg sets whether SyncTeams removes the users it removed from
+// their last team from the organization as well.
+func (tm *Manager) SetRemoveFromOrg(remove bool) {
+	tm.removeFromOrg = remove
+}
+
+// OrgRemovals returns the logins of the users removed from a team by the
+// given changes that are no longer members of any team of upstreamCfg, sorted.
+// upstreamCfg is the state of the teams before the changes, including the
+// teams that aren't managed by the configuration.
+func OrgRemovals(upstreamCfg *config.Config, changes map[string]TeamChange) []string {
+	members := map[string]struct{}{}
+	for teamName, teamCfg := range upstreamCfg.Teams {
+		removed := map[string]struct{}{}
+		for _, login := range changes[teamName].Remove {
+			removed[strings.ToLower(login)] = struct{}{}
+		}
+		for _, login := range teamCfg.Members {
+			if _, ok := removed[strings.ToLower(login)]; !ok {
+				members[strings.ToLower(login)] = struct{}{}
+			}
+		}
+	}
+	for _, change := range changes {
+		for _, login := range change.Add {
+			members[strings.ToLower(login)] = struct{}{}
+		}
+	}
+
+	var removals []string
+	for _, change := range changes {
+		for _, login := range change.Remove {
+			lower := strings.ToLower(login)
+			if _, ok := members[lower]; ok {
+				continue
+			}
+			// Mark the user so that it's only returned once.
+			members[lower] = struct{}{}
+			removals = append(removals, login)
+		}
+	}
+	sort.Strings(removals)
+	return removals
+}
+
+// removeOrgMembers removes the given users from the organization, after asking
+// for confirmation even if the team changes were confirmed already: it also
+// revokes their access to the repositories of the organization. Organization
+// owners are never removed.
+func (tm *Manager) removeOrgMembers(ctx context.Context, logins []string, force, dryRun bool) error {
+	tm.reporter.Plan(PlanEvent{
+		Title:   fmt.Sprintf("Going to remove the following users, who are no longer members of any team, from organization %s", tm.owner),
+		Changes: logins,
+		Print: func(w io.Writer) {
+			for _, login := range logins {
+				fmt.Fprintf(w, " User: %s\n", login)
+			}
+		},
+	})
+	if !force {
+		yes, err := terminal.AskForConfirmation(fmt.Sprintf("Remove these %d users from organization %s?", len(logins), tm.owner))
+		if err != nil {
+			return err
+		}
+		if !yes {
+			return nil
+		}
+	}
+
+	for _, login := range logins {
+		if dryRun {
+			tm.reporter.Progress("Removing %s from organization %s", login, tm.owner)
+			continue
+		}
+		membership, _, err := tm.ghClient.Organizations.GetOrgMembership(ctx, login, tm.owner)
+		if err != nil {
+			tm.reporter.Error("Unable to read organization membership of %s: %s", login, github.TranslateError(err))
+			continue
+		}
+		if membership.GetRole() == "admin" {
+			tm.reporter.Warn("Not removing %s from organization %s, they are an owner of it", login, tm.owner)
+			continue
+		}
+		tm.reporter.Progress("Removing %s from organization %s", login, tm.owner)
+		if _, err := tm.ghClient.Organizations.RemoveMember(ctx, tm.owner, login); err != nil {
+			tm.reporter.Error("Unable to remove %s from organization %s: %s", login, tm.owner, github.TranslateError(err))
+		}
+	}
+	return nil
+}
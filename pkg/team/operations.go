@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OperationKind is the kind of mutation of an Operation.
+type OperationKind string
+
+// The operation kinds, in the order they are applied.
+const (
+	OperationCreateTeam          OperationKind = "create-team"
+	OperationInviteUser          OperationKind = "invite-user"
+	OperationAddMember           OperationKind = "add-member"
+	OperationRemoveMember        OperationKind = "remove-member"
+	OperationSetRole             OperationKind = "set-role"
+	OperationEditTeam            OperationKind = "edit-team"
+	OperationSetReviewAssignment OperationKind = "set-review-assignment"
+)
+
+var operationKindOrder = map[OperationKind]int{
+	OperationCreateTeam:          0,
+	OperationInviteUser:          1,
+	OperationAddMember:           2,
+	OperationRemoveMember:        3,
+	OperationSetRole:             4,
+	OperationEditTeam:            5,
+	OperationSetReviewAssignment: 6,
+}
+
+// Operation is a single mutation of a plan, as listed and hashed to compare
+// plans across runs. Plans are still applied by category of changes, see
+// ApplyPlan, not operation by operation.
+type Operation struct {
+	Kind OperationKind `json:"kind"`
+	Team string        `json:"team"`
+	// Target is the member or the setting the operation applies to, if
+	// any.
+	Target string `json:"target,omitempty"`
+	// Value is the value the target is set to, if any.
+	Value string `json:"value,omitempty"`
+}
+
+func (o Operation) String() string {
+	var subjects []string
+	if o.Team != "" {
+		subjects = append(subjects, "team "+o.Team)
+	}
+	if o.Target != "" {
+		subjects = append(subjects, o.Target)
+	}
+	s := fmt.Sprintf("%s %s: %s", o.Hash(), o.Kind, strings.Join(subjects, ", "))
+	if o.Value != "" {
+		s += ": " + o.Value
+	}
+	return s
+}
+
+// Hash returns the hash of the content of the operation, which is stable
+// across runs and identifies the same operation in different plans.
+func (o Operation) Hash() string {
+	data, _ := json.Marshal(o)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// Operations returns the operations of the plan in the order they are
+// applied: by kind, then by team and target.
+func (p *Plan) Operations() []Operation {
+	var ops []Operation
+	for teamName, newTeam := range p.NewTeams {
+		var settings []string
+		if newTeam.Description != "" {
+			settings = append(settings, fmt.Sprintf("description=%q", newTeam.Description))
+		}
+		if newTeam.Privacy != "" {
+			settings = append(settings, fmt.Sprintf("privacy=%s", newTeam.Privacy))
+		}
+		if newTeam.NotificationSetting != "" {
+			settings = append(settings, fmt.Sprintf("notificationSetting=%s", newTeam.NotificationSetting))
+		}
+		if newTeam.Parent != "" {
+			settings = append(settings, fmt.Sprintf("parent=%s", newTeam.Parent))
+		}
+		ops = append(ops, Operation{Kind: OperationCreateTeam, Team: teamName, Value: strings.Join(settings, " ")})
+	}
+	for login, invitation := range p.Invitations {
+		if invitation.Pending || invitation.Role == "" {
+			continue
+		}
+		// Invitations aren't specific to a team, Team is left empty.
+		ops = append(ops, Operation{Kind: OperationInviteUser, Target: login, Value: string(invitation.Role)})
+	}
+	for teamName, change := range p.TeamChanges {
+		added := make(map[string]struct{}, len(change.Add))
+		for _, login := range change.Add {
+			added[login] = struct{}{}
+			ops = append(ops, Operation{Kind: OperationAddMember, Team: teamName, Target: login, Value: change.role(login)})
+		}
+		for _, login := range change.Remove {
+			ops = append(ops, Operation{Kind: OperationRemoveMember, Team: teamName, Target: login})
+		}
+		for _, login := range change.Promote {
+			if _, ok := added[login]; ok {
+				continue
+			}
+			ops = append(ops, Operation{Kind: OperationSetRole, Team: teamName, Target: login, Value: "maintainer"})
+		}
+		for _, login := range change.Demote {
+			ops = append(ops, Operation{Kind: OperationSetRole, Team: teamName, Target: login, Value: "member"})
+		}
+	}
+	for teamName, edit := range p.TeamEdits {
+		if edit.Description != nil {
+			ops = append(ops, Operation{Kind: OperationEditTeam, Team: teamName, Target: "description", Value: *edit.Description})
+		}
+		if edit.Privacy != nil {
+			ops = append(ops, Operation{Kind: OperationEditTeam, Team: teamName, Target: "privacy", Value: string(*edit.Privacy)})
+		}
+		if edit.NotificationSetting != nil {
+			ops = append(ops, Operation{Kind: OperationEditTeam, Team: teamName, Target: "notificationSetting", Value: string(*edit.NotificationSetting)})
+		}
+	}
+	for teamName, input := range p.ReviewAssignments {
+		excluded := make([]string, 0, len(input.ExcludedTeamMemberIDs))
+		for _, id := range input.ExcludedTeamMemberIDs {
+			excluded = append(excluded, fmt.Sprint(id))
+		}
+		sort.Strings(excluded)
+		var options string
+		for _, o := range reviewAssignmentOptions(input) {
+			options += fmt.Sprintf(" %s=%t", o.key, o.value)
+		}
+		ops = append(ops, Operation{
+			Kind: OperationSetReviewAssignment,
+			Team: teamName,
+			Value: fmt.Sprintf("enabled=%t algorithm=%s count=%d notify=%t excluded=%s%s",
+				input.Enabled, input.Algorithm, input.TeamMemberCount, input.NotifyTeam, strings.Join(excluded, ","), options),
+		})
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Kind != ops[j].Kind {
+			return operationKindOrder[ops[i].Kind] < operationKindOrder[ops[j].Kind]
+		}
+		if ops[i].Team != ops[j].Team {
+			return ops[i].Team < ops[j].Team
+		}
+		return ops[i].Target < ops[j].Target
+	})
+	return ops
+}
+
+// HashOperations returns the hash of the given operations, which only
+// changes if the operations do, to tell whether a plan changed since a
+// previous run.
+func HashOperations(ops []Operation) string {
+	h := sha256.New()
+	for _, op := range ops {
+		fmt.Fprintln(h, op.Hash())
+	}
+	return hex.EncodeToString(h.Sum(nil)[:6])
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+)
+
+// OrgMember is a member of the organization.
+type OrgMember struct {
+	Login string `json:"login"`
+	Name  string `json:"name,omitempty"`
+	// Role is the role of the member in the organization, ADMIN or MEMBER.
+	Role string `json:"role"`
+}
+
+// ListOrgMembers returns the members of the organization, sorted by login.
+//
+//	{
+//	 organization(login: "cilium") {
+//	   membersWithRole(first: 100) { edges { role node { login name } } }
+//	 }
+//	}
+func (tm *Manager) ListOrgMembers(ctx context.Context) ([]OrgMember, error) {
+	query := github.BuildQuery(
+		map[string]string{"owner": "String!", "cursor": "String"},
+		github.NewField("organization",
+			github.NewField("membersWithRole",
+				github.NewField("edges",
+					github.NewField("role"),
+					github.NewField("node", github.Fields("login", "name")...),
+				),
+				github.NewField("pageInfo", github.Fields("endCursor", "hasNextPage")...),
+			).WithArgs("first: 100, after: $cursor"),
+		).WithArgs("login: $owner"),
+	)
+
+	var members []OrgMember
+	variables := map[string]interface{}{
+		"owner":  tm.owner,
+		"cursor": nil,
+	}
+	for {
+		var q struct {
+			Organization struct {
+				MembersWithRole struct {
+					Edges []struct {
+						Role string
+						Node struct {
+							Login string
+							Name  string
+						}
+					}
+					PageInfo github.PageInfo
+				}
+			}
+		}
+		if err := tm.gqlGHClient.QueryRaw(ctx, query, variables, &q); err != nil {
+			return nil, err
+		}
+		for _, e := range q.Organization.MembersWithRole.Edges {
+			members = append(members, OrgMember{Login: e.Node.Login, Name: e.Node.Name, Role: e.Role})
+		}
+		if !q.Organization.MembersWithRole.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = q.Organization.MembersWithRole.PageInfo.EndCursor
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return strings.ToLower(members[i].Login) < strings.ToLower(members[j].Login)
+	})
+	return members, nil
+}
+
+// Orphan is a member of the organization that isn't a member of any team of
+// the configuration.
+type Orphan struct {
+	OrgMember
+	// Known is true if the orphan is a member of the configuration.
+	Known bool `json:"known"`
+}
+
+// Orphans returns the given members of the organization that aren't members
+// of any team of cfg.
+func Orphans(cfg *config.Config, members []OrgMember) []Orphan {
+	teamMembers := map[string]struct{}{}
+	for _, teamCfg := range cfg.Teams {
+		for _, login := range teamCfg.Members {
+			teamMembers[strings.ToLower(login)] = struct{}{}
+		}
+	}
+	known := map[string]struct{}{}
+	for login := range cfg.Members {
+		known[strings.ToLower(login)] = struct{}{}
+	}
+
+	var orphans []Orphan
+	for _, m := range members {
+		lower := strings.ToLower(m.Login)
+		if _, ok := teamMembers[lower]; ok {
+			continue
+		}
+		_, ok := known[lower]
+		orphans = append(orphans, Orphan{OrgMember: m, Known: ok})
+	}
+	return orphans
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/set"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// OwnerChanges are the members promoted to and demoted from owners of the
+// organization.
+type OwnerChanges struct {
+	Promote []string `json:"promote,omitempty"`
+	Demote  []string `json:"demote,omitempty"`
+}
+
+// Print prints the promoted and demoted owners.
+func (c OwnerChanges) Print(w io.Writer) {
+	for _, login := range c.Promote {
+		fmt.Fprintf(w, " Promote: %s\n", login)
+	}
+	for _, login := range c.Demote {
+		fmt.Fprintf(w, " Demote: %s\n", login)
+	}
+}
+
+// ListOrgOwners returns the logins of the owners of the organization.
+func (tm *Manager) ListOrgOwners(ctx context.Context) ([]string, error) {
+	var owners []string
+	opts := &gh.ListMembersOptions{Role: "admin", ListOptions: gh.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := tm.ghClient.Organizations.ListMembers(ctx, tm.owner, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range page {
+			owners = append(owners, u.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			return owners, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// ComputeOwnerChanges returns the changes turning the current owners of the
+// organization into the desired ones, sorted.
+func ComputeOwnerChanges(desired, current []string) OwnerChanges {
+	changes := OwnerChanges{
+		Promote: set.DifferenceFold(desired, current),
+		Demote:  set.DifferenceFold(current, desired),
+	}
+	sort.Strings(changes.Promote)
+	sort.Strings(changes.Demote)
+	return changes
+}
+
+// syncOwners promotes and demotes the owners of the organization so that
+// they match the given ones. Owners are the most sensitive setting of an
+// organization, so the changes are always confirmed interactively: with
+// force, they are only reported. The owner the token belongs to is never
+// demoted, which would leave team-manager unable to manage the organization.
+func (tm *Manager) syncOwners(ctx context.Context, owners []string, force, dryRun bool) error {
+	current, err := tm.ListOrgOwners(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list organization owners: %w", err)
+	}
+	changes := ComputeOwnerChanges(owners, current)
+	if len(changes.Promote) == 0 && len(changes.Demote) == 0 {
+		return nil
+	}
+
+	tm.reporter.Plan(PlanEvent{
+		Title:   fmt.Sprintf("Going to change the following owners of organization %s", tm.owner),
+		Changes: changes,
+		Print:   changes.Print,
+	})
+	if force {
+		tm.reporter.Warn("Not changing the owners of organization %s with --force, they must be confirmed", tm.owner)
+		return nil
+	}
+	yes, err := terminal.AskForConfirmation(fmt.Sprintf("Change the owners of organization %s?", tm.owner))
+	if err != nil {
+		return err
+	}
+	if !yes || dryRun {
+		return nil
+	}
+
+	self, _, err := tm.ghClient.Users.Get(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to read authenticated user: %w", err)
+	}
+	for _, login := range changes.Promote {
+		if err := tm.setOrgRole(ctx, login, "admin"); err != nil {
+			tm.reporter.Error("Unable to promote %s to owner: %s", login, github.TranslateError(err))
+		}
+	}
+	for _, login := range changes.Demote {
+		if strings.EqualFold(login, self.GetLogin()) {
+			tm.reporter.Warn("Not demoting %s, the token used by team-manager belongs to them", login)
+			continue
+		}
+		if err := tm.setOrgRole(ctx, login, "member"); err != nil {
+			tm.reporter.Error("Unable to demote owner %s: %s", login, github.TranslateError(err))
+		}
+	}
+	return nil
+}
+
+// setOrgRole sets the role of the given member of the organization.
+func (tm *Manager) setOrgRole(ctx context.Context, login, role string) error {
+	_, _, err := tm.ghClient.Organizations.EditOrgMembership(ctx, login, tm.owner, &gh.Membership{Role: gh.String(role)})
+	return err
+}
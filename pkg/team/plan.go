@@ -0,0 +1,479 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/shurcooL/githubv4"
+
+	"github.com/cilium/team-manager/pkg/comparator"
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// Plan contains the changes that need to be submitted to GitHub to bring the
+// upstream config in sync with the local config.
+type Plan struct {
+	// Diffs maps the name of every team that is out of sync to the
+	// differences between its local and its upstream config.
+	Diffs map[string]comparator.TeamDiff
+
+	// NewTeams maps the name of every team that only exists locally to the
+	// settings it is created with.
+	NewTeams map[string]NewTeam
+
+	// TeamChanges maps the name of every team that has members to add or to
+	// remove to these members.
+	TeamChanges map[string]TeamChange
+
+	// TeamEdits maps the name of every team that has settings to update to
+	// these settings.
+	TeamEdits map[string]TeamEdit
+
+	// ReviewAssignments maps the name of every team whose review assignment
+	// changed to the review assignment that is submitted for it.
+	ReviewAssignments map[string]github.UpdateTeamReviewAssignmentInput
+
+	// ReviewAssignmentDiffs maps the name of every team whose review
+	// assignment changed to its changes.
+	ReviewAssignmentDiffs map[string]ReviewAssignmentDiff
+
+	// UpstreamReviewAssignments maps the name of every team to its upstream
+	// review assignment at the time the plan was computed, to detect
+	// conflicting changes when the plan is applied later on.
+	UpstreamReviewAssignments map[string]config.CodeReviewAssignment
+
+	// Invitations maps the users added to teams that aren't members of the
+	// organization to their invitation, see PlanInvitations.
+	Invitations map[string]Invitation
+
+	// UnresolvedExclusions maps the name of every team to the logins of
+	// its excluded members that aren't members of the organization, which
+	// are left out of its review assignment.
+	UnresolvedExclusions map[string][]string
+}
+
+// TeamChange contains the members that are added to and removed from a team,
+// and the members whose role is changed. Added members in Promote are added
+// with the maintainer role.
+type TeamChange struct {
+	Add     []string `json:"add,omitempty"`
+	Remove  []string `json:"remove,omitempty"`
+	Promote []string `json:"promote,omitempty"`
+	Demote  []string `json:"demote,omitempty"`
+}
+
+// role returns the role the given added member is added with.
+func (c TeamChange) role(user string) string {
+	for _, maintainer := range c.Promote {
+		if maintainer == user {
+			return "maintainer"
+		}
+	}
+	return "member"
+}
+
+// ReviewAssignmentDiff contains the changes of the code review assignment of a
+// team.
+type ReviewAssignmentDiff struct {
+	// Settings are the changed settings, e.g. "member count: 1 -> 2".
+	Settings []string `json:"settings,omitempty"`
+	// Exclude and Include are the logins of the members excluded from and
+	// included again into the review assignment.
+	Exclude []string `json:"exclude,omitempty"`
+	Include []string `json:"include,omitempty"`
+	// Unknown is set if the members excluded upstream are unknown, e.g.
+	// for new teams or review assignments never updated by push.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// Empty returns true if the review assignment didn't change.
+func (d ReviewAssignmentDiff) Empty() bool {
+	return len(d.Settings) == 0 && len(d.Exclude) == 0 && len(d.Include) == 0 && !d.Unknown
+}
+
+// String returns the changes of d on a single line.
+func (d ReviewAssignmentDiff) String() string {
+	changes := append([]string(nil), d.Settings...)
+	if d.Unknown {
+		changes = append(changes, "excluded members unknown upstream")
+	}
+	for _, login := range d.Exclude {
+		changes = append(changes, "excluding "+login)
+	}
+	for _, login := range d.Include {
+		changes = append(changes, "including "+login)
+	}
+	return strings.Join(changes, ", ")
+}
+
+// compareReviewAssignments returns the changes turning the upstream review
+// assignment of a team, with the members excluded and the options set when
+// push last updated it, into the local one with the given excluded members.
+// applied is nil if the excluded members are unknown.
+func compareReviewAssignments(local, upstream config.CodeReviewAssignment, excluded, applied []string, appliedOptions config.ReviewAssignmentOptions) ReviewAssignmentDiff {
+	var diff ReviewAssignmentDiff
+	if local.Enabled != upstream.Enabled {
+		diff.Settings = append(diff.Settings, fmt.Sprintf("enabled: %t -> %t", upstream.Enabled, local.Enabled))
+	}
+	// The settings of disabled review assignments aren't returned by
+	// GitHub, and unset algorithms are left untouched.
+	if local.Enabled {
+		if local.Algorithm != "" && local.Algorithm != upstream.Algorithm {
+			diff.Settings = append(diff.Settings, fmt.Sprintf("algorithm: %s -> %s", upstream.Algorithm, local.Algorithm))
+		}
+		if local.TeamMemberCount != upstream.TeamMemberCount {
+			diff.Settings = append(diff.Settings, fmt.Sprintf("member count: %d -> %d", upstream.TeamMemberCount, local.TeamMemberCount))
+		}
+		if local.NotifyTeam != upstream.NotifyTeam {
+			diff.Settings = append(diff.Settings, fmt.Sprintf("notify team: %t -> %t", upstream.NotifyTeam, local.NotifyTeam))
+		}
+		// Unset options are left untouched.
+		option := func(name string, local, applied *bool) {
+			if local == nil || (applied != nil && *applied == *local) {
+				return
+			}
+			upstream := "unknown"
+			if applied != nil {
+				upstream = fmt.Sprint(*applied)
+			}
+			diff.Settings = append(diff.Settings, fmt.Sprintf("%s: %s -> %t", name, upstream, *local))
+		}
+		option("count members already requested", local.CountMembersAlreadyRequested, appliedOptions.CountMembersAlreadyRequested)
+		option("include child team members", local.IncludeChildTeamMembers, appliedOptions.IncludeChildTeamMembers)
+		option("remove team request", local.RemoveTeamRequest, appliedOptions.RemoveTeamRequest)
+	}
+	if applied == nil {
+		diff.Unknown = true
+		return diff
+	}
+	diff.Exclude = set.DifferenceFold(excluded, applied)
+	diff.Include = set.DifferenceFold(applied, excluded)
+	sort.Strings(diff.Exclude)
+	sort.Strings(diff.Include)
+	return diff
+}
+
+// NewTeam contains the settings of a team that is created.
+type NewTeam struct {
+	Description         string                         `json:"description,omitempty"`
+	Privacy             config.TeamPrivacy             `json:"privacy,omitempty"`
+	NotificationSetting config.TeamNotificationSetting `json:"notificationSetting,omitempty"`
+	Parent              string                         `json:"parent,omitempty"`
+}
+
+// TeamEdit contains the settings of a team that are updated. Settings that are
+// nil are left untouched.
+type TeamEdit struct {
+	Description         *string                         `json:"description,omitempty"`
+	Privacy             *config.TeamPrivacy             `json:"privacy,omitempty"`
+	NotificationSetting *config.TeamNotificationSetting `json:"notificationSetting,omitempty"`
+}
+
+// ComputePlan returns the plan to bring upstreamCfg in sync with localCfg.
+// It does not perform any request to GitHub.
+func ComputePlan(localCfg, upstreamCfg *config.Config) *Plan {
+	plan := &Plan{
+		Diffs:                     map[string]comparator.TeamDiff{},
+		NewTeams:                  map[string]NewTeam{},
+		TeamChanges:               map[string]TeamChange{},
+		TeamEdits:                 map[string]TeamEdit{},
+		ReviewAssignments:         map[string]github.UpdateTeamReviewAssignmentInput{},
+		ReviewAssignmentDiffs:     map[string]ReviewAssignmentDiff{},
+		UpstreamReviewAssignments: map[string]config.CodeReviewAssignment{},
+		UnresolvedExclusions:      map[string][]string{},
+	}
+
+	for localTeamName, localTeam := range localCfg.Teams {
+		// Since we can't get the list of excluded members from GH we have
+		// to ignore them in the comparison.
+		localTeam.CodeReviewAssignment.ExcludedMembers = nil
+		localTeam.CodeReviewAssignment.InheritExclusions = false
+		localTeam.CodeReviewAssignment.TeamMemberCount = ReviewerCount(localCfg, localTeamName)
+		localTeam.CodeReviewAssignment.TeamMemberPercentage = 0
+		// Metadata is informational only and never pushed to GH.
+		localTeam.Metadata = nil
+		upstreamTeam, exists := upstreamCfg.Teams[localTeamName]
+		upstreamTeam.Metadata = nil
+		// Slugs are only retrieved from GH.
+		localTeam.Slug = upstreamTeam.Slug
+		if !exists {
+			plan.NewTeams[localTeamName] = NewTeam{
+				Description:         localTeam.Description,
+				Privacy:             localTeam.Privacy,
+				NotificationSetting: localTeam.NotificationSetting,
+				Parent:              localTeam.Parent,
+			}
+		}
+		// Descriptions, privacy and notification settings are only
+		// managed if they are set locally.
+		if localTeam.Description == "" {
+			localTeam.Description = upstreamTeam.Description
+		}
+		if localTeam.Privacy == "" {
+			localTeam.Privacy = upstreamTeam.Privacy
+		}
+		if localTeam.NotificationSetting == "" {
+			localTeam.NotificationSetting = upstreamTeam.NotificationSetting
+		}
+		// Likewise, team roles are only managed if maintainers are set
+		// locally.
+		if len(localTeam.Maintainers) == 0 {
+			localTeam.Maintainers = upstreamTeam.Maintainers
+		}
+		var edit TeamEdit
+		if localTeam.Description != upstreamTeam.Description {
+			description := localTeam.Description
+			edit.Description = &description
+		}
+		if localTeam.Privacy != upstreamTeam.Privacy {
+			privacy := localTeam.Privacy
+			edit.Privacy = &privacy
+		}
+		if localTeam.NotificationSetting != upstreamTeam.NotificationSetting {
+			setting := localTeam.NotificationSetting
+			edit.NotificationSetting = &setting
+		}
+		if exists && edit != (TeamEdit{}) {
+			plan.TeamEdits[localTeamName] = edit
+		}
+		if diff := comparator.CompareTeams(localTeam, upstreamTeam); !diff.Empty() {
+			plan.Diffs[localTeamName] = diff
+			toAdd := diff.Members.Added
+			toDel := diff.Members.Removed
+			toPromote := diff.Maintainers.Added
+			// Removed maintainers don't need to be demoted first.
+			toDemote := set.DifferenceFold(diff.Maintainers.Removed, toDel)
+			if len(toAdd) != 0 || len(toDel) != 0 || len(toPromote) != 0 || len(toDemote) != 0 {
+				plan.TeamChanges[localTeamName] = TeamChange{
+					Add:     toAdd,
+					Remove:  toDel,
+					Promote: toPromote,
+					Demote:  toDemote,
+				}
+			}
+		}
+	}
+
+	for teamName, storedTeam := range localCfg.Teams {
+		cra := storedTeam.CodeReviewAssignment
+		cra.TeamMemberCount = ReviewerCount(localCfg, teamName)
+		cra.TeamMemberPercentage = 0
+		usersIDs, unresolved := getExcludedUsers(localCfg.Members, localCfg.ExcludedMembers(teamName), localCfg.ExcludeCRAFromAllTeams)
+		if len(unresolved) != 0 {
+			plan.UnresolvedExclusions[teamName] = unresolved
+		}
+		upstreamTeam, exists := upstreamCfg.Teams[teamName]
+		applied, known := localCfg.AppliedExclusions[teamName]
+		if !exists || !known {
+			applied = nil
+		} else if applied == nil {
+			applied = []string{}
+		}
+		var appliedOptions config.ReviewAssignmentOptions
+		if exists {
+			appliedOptions = localCfg.AppliedReviewOptions[teamName]
+		}
+		diff := compareReviewAssignments(cra, upstreamTeam.CodeReviewAssignment, excludedLogins(localCfg, usersIDs), applied, appliedOptions)
+		plan.UpstreamReviewAssignments[teamName] = upstreamTeam.CodeReviewAssignment
+		if diff.Empty() {
+			continue
+		}
+
+		plan.ReviewAssignmentDiffs[teamName] = diff
+		plan.ReviewAssignments[teamName] = github.UpdateTeamReviewAssignmentInput{
+			Algorithm:                    cra.Algorithm,
+			CountMembersAlreadyRequested: github.NewReviewAssignmentOption(cra.CountMembersAlreadyRequested),
+			Enabled:                      githubv4.Boolean(cra.Enabled),
+			ExcludedTeamMemberIDs:        usersIDs,
+			ID:                           storedTeam.ID,
+			IncludeChildTeamMembers:      github.NewReviewAssignmentOption(cra.IncludeChildTeamMembers),
+			NotifyTeam:                   githubv4.Boolean(cra.NotifyTeam),
+			RemoveTeamRequest:            github.NewReviewAssignmentOption(cra.RemoveTeamRequest),
+			TeamMemberCount:              githubv4.Int(cra.TeamMemberCount),
+		}
+	}
+
+	return plan
+}
+
+// PrintDiffs prints the diffs of all teams that are out of sync.
+func (p *Plan) PrintDiffs(w io.Writer) {
+	for _, teamName := range sortedKeys(p.Diffs) {
+		fmt.Fprintf(w, "Local config of team %s out of sync with upstream:\n", teamName)
+		p.Diffs[teamName].Print(w)
+	}
+}
+
+// PrintTeamChanges prints the members that are added to and removed from
+// each team.
+func (p *Plan) PrintTeamChanges(w io.Writer) {
+	for _, teamName := range sortedKeys(p.TeamChanges) {
+		teamCfg := p.TeamChanges[teamName]
+		fmt.Fprintf(w, " Team: %s\n", teamName)
+		added := make([]string, 0, len(teamCfg.Add))
+		for _, login := range teamCfg.Add {
+			if invitation, ok := p.Invitations[login]; ok {
+				login = fmt.Sprintf("%s (%s)", login, invitation)
+			}
+			added = append(added, login)
+		}
+		fmt.Fprintf(w, "    Adding members: %s\n", strings.Join(added, ", "))
+		fmt.Fprintf(w, "  Removing members: %s\n", strings.Join(teamCfg.Remove, ", "))
+		if len(teamCfg.Promote) != 0 {
+			fmt.Fprintf(w, "   Maintainer role: %s\n", strings.Join(teamCfg.Promote, ", "))
+		}
+		if len(teamCfg.Demote) != 0 {
+			fmt.Fprintf(w, "       Member role: %s\n", strings.Join(teamCfg.Demote, ", "))
+		}
+	}
+}
+
+// PrintNewTeams prints the teams that are created with their settings.
+func (p *Plan) PrintNewTeams(w io.Writer) {
+	for _, teamName := range sortedKeys(p.NewTeams) {
+		newTeam := p.NewTeams[teamName]
+		fmt.Fprintf(w, " Team: %s\n", teamName)
+		if newTeam.Description != "" {
+			fmt.Fprintf(w, "    Description: %q\n", newTeam.Description)
+		}
+		if newTeam.Privacy != "" {
+			fmt.Fprintf(w, "    Privacy: %s\n", newTeam.Privacy)
+		}
+		if newTeam.NotificationSetting != "" {
+			fmt.Fprintf(w, "    Notification setting: %s\n", newTeam.NotificationSetting)
+		}
+		if newTeam.Parent != "" {
+			fmt.Fprintf(w, "    Parent: %s\n", newTeam.Parent)
+		}
+	}
+}
+
+// dropNewTeams removes the new teams and their members and review
+// assignments from the plan.
+func (p *Plan) dropNewTeams() {
+	for teamName := range p.NewTeams {
+		delete(p.TeamChanges, teamName)
+		delete(p.ReviewAssignments, teamName)
+	}
+	p.NewTeams = map[string]NewTeam{}
+}
+
+// dropTeam removes all changes of the given team from the plan.
+func (p *Plan) dropTeam(teamName string) {
+	delete(p.NewTeams, teamName)
+	delete(p.TeamChanges, teamName)
+	delete(p.TeamEdits, teamName)
+	delete(p.ReviewAssignments, teamName)
+	delete(p.UnresolvedExclusions, teamName)
+}
+
+// PrintTeamEdits prints the settings that are updated for each team.
+func (p *Plan) PrintTeamEdits(w io.Writer) {
+	for _, teamName := range sortedKeys(p.TeamEdits) {
+		edit := p.TeamEdits[teamName]
+		fmt.Fprintf(w, " Team: %s\n", teamName)
+		if edit.Description != nil {
+			fmt.Fprintf(w, "    Description: %q\n", *edit.Description)
+		}
+		if edit.Privacy != nil {
+			fmt.Fprintf(w, "    Privacy: %s\n", *edit.Privacy)
+		}
+		if edit.NotificationSetting != nil {
+			fmt.Fprintf(w, "    Notification setting: %s\n", *edit.NotificationSetting)
+		}
+	}
+}
+
+// reviewAssignmentOption is an option of a code review assignment that is
+// set, with its name in plans and its key in operations.
+type reviewAssignmentOption struct {
+	name, key string
+	value     bool
+}
+
+// reviewAssignmentOptions returns the options of input that are set.
+func reviewAssignmentOptions(input github.UpdateTeamReviewAssignmentInput) []reviewAssignmentOption {
+	var options []reviewAssignmentOption
+	for _, o := range []struct {
+		name, key string
+		value     *githubv4.Boolean
+	}{
+		{"count members already requested", "countRequested", input.CountMembersAlreadyRequested},
+		{"include child team members", "includeChildren", input.IncludeChildTeamMembers},
+		{"remove team request", "removeTeamRequest", input.RemoveTeamRequest},
+	} {
+		if o.value != nil {
+			options = append(options, reviewAssignmentOption{name: o.name, key: o.key, value: bool(*o.value)})
+		}
+	}
+	return options
+}
+
+// PrintReviewAssignments prints the review assignment of each team whose
+// review assignment changed, with its changes if known.
+func (p *Plan) PrintReviewAssignments(w io.Writer, localCfg *config.Config) {
+	for _, teamName := range sortedKeys(p.ReviewAssignments) {
+		input := p.ReviewAssignments[teamName]
+		fmt.Fprintf(w, " Team: %s\n", teamName)
+		if diff, ok := p.ReviewAssignmentDiffs[teamName]; ok {
+			fmt.Fprintf(w, "    Changes: %s\n", diff)
+		}
+		fmt.Fprintf(w, "    Enabled: %t, algorithm: %s, member count: %d, notify team: %t",
+			input.Enabled, input.Algorithm, input.TeamMemberCount, input.NotifyTeam)
+		for _, o := range reviewAssignmentOptions(input) {
+			fmt.Fprintf(w, ", %s: %t", o.name, o.value)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "    Excluded members:\n")
+		for _, login := range excludedLogins(localCfg, input.ExcludedTeamMemberIDs) {
+			fmt.Fprintf(w, "      %s: %s\n", login, exclusionReason(localCfg, teamName, login))
+		}
+	}
+}
+
+// exclusionReason returns why the given member is excluded from the code
+// review assignment of the given team.
+func exclusionReason(cfg *config.Config, teamName, login string) string {
+	for _, xMember := range cfg.ExcludedMembers(teamName) {
+		if xMember.Login == login && xMember.Reason != "" {
+			return xMember.Reason
+		}
+	}
+	for _, xMember := range cfg.ExcludeCRAFromAllTeams {
+		if xMember == login {
+			return "excluded from all teams"
+		}
+	}
+	return "no reason given"
+}
+
+// excludedLogins maps the given member IDs back to their sorted logins.
+func excludedLogins(cfg *config.Config, ids []githubv4.ID) []string {
+	logins := make([]string, 0, len(ids))
+	for login, user := range cfg.Members {
+		for _, id := range ids {
+			if id == user.ID {
+				logins = append(logins, login)
+				break
+			}
+		}
+	}
+	sort.Strings(logins)
+	return logins
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
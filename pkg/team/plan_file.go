@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/renameio"
+
+	"github.com/cilium/team-manager/pkg/comparator"
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+)
+
+// PlanFile is a plan stored by 'plan' to be applied later by 'apply'. It is
+// signed with a shared secret so that it can't be tampered with between both
+// steps, and it contains the hash of the upstream teams it was computed
+// against so that it is not applied once upstream changed.
+type PlanFile struct {
+	Organization string    `json:"organization"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpstreamHash string    `json:"upstreamHash"`
+	// PlanHash is the hash of the operations of the plan, see
+	// HashOperations.
+	PlanHash string `json:"planHash,omitempty"`
+	// Diffs are the differences between the local and the upstream teams,
+	// see Plan.Diffs. They are informational only.
+	Diffs             map[string]comparator.TeamDiff                    `json:"diffs,omitempty"`
+	NewTeams          map[string]NewTeam                                `json:"newTeams,omitempty"`
+	TeamChanges       map[string]TeamChange                             `json:"teamChanges,omitempty"`
+	TeamEdits         map[string]TeamEdit                               `json:"teamEdits,omitempty"`
+	ReviewAssignments map[string]github.UpdateTeamReviewAssignmentInput `json:"reviewAssignments,omitempty"`
+	// UpstreamReviewAssignments are the upstream review assignments at the
+	// time the plan was created, see Plan.UpstreamReviewAssignments.
+	UpstreamReviewAssignments map[string]config.CodeReviewAssignment `json:"upstreamReviewAssignments,omitempty"`
+	Invitations               map[string]Invitation                  `json:"invitations,omitempty"`
+	Signature                 string                                 `json:"signature,omitempty"`
+}
+
+// NewPlanFile returns the plan file of the given plan computed against the
+// given upstream config.
+func NewPlanFile(plan *Plan, upstreamCfg *config.Config, now time.Time) (*PlanFile, error) {
+	hash, err := UpstreamHash(upstreamCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &PlanFile{
+		Organization:              upstreamCfg.Organization,
+		CreatedAt:                 now.UTC(),
+		UpstreamHash:              hash,
+		PlanHash:                  HashOperations(plan.Operations()),
+		Diffs:                     plan.Diffs,
+		NewTeams:                  plan.NewTeams,
+		TeamChanges:               plan.TeamChanges,
+		TeamEdits:                 plan.TeamEdits,
+		ReviewAssignments:         plan.ReviewAssignments,
+		UpstreamReviewAssignments: plan.UpstreamReviewAssignments,
+		Invitations:               plan.Invitations,
+	}, nil
+}
+
+// Plan returns the plan stored in the plan file.
+func (p *PlanFile) Plan() *Plan {
+	plan := &Plan{
+		Diffs:                     p.Diffs,
+		NewTeams:                  p.NewTeams,
+		TeamChanges:               p.TeamChanges,
+		TeamEdits:                 p.TeamEdits,
+		ReviewAssignments:         p.ReviewAssignments,
+		UpstreamReviewAssignments: p.UpstreamReviewAssignments,
+		Invitations:               p.Invitations,
+	}
+	// Empty maps are omitted from plan files.
+	if plan.Diffs == nil {
+		plan.Diffs = map[string]comparator.TeamDiff{}
+	}
+	if plan.TeamChanges == nil {
+		plan.TeamChanges = map[string]TeamChange{}
+	}
+	if plan.ReviewAssignments == nil {
+		plan.ReviewAssignments = map[string]github.UpdateTeamReviewAssignmentInput{}
+	}
+	return plan
+}
+
+// UpstreamHash returns the hash of the teams of the given upstream config.
+// Only the teams are hashed as the plan doesn't depend on the rest of the
+// upstream config, e.g. on organization members joining.
+func UpstreamHash(cfg *config.Config) (string, error) {
+	config.SortConfig(cfg)
+	data, err := json.Marshal(cfg.Teams)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Sign signs the plan file with the given secret.
+func (p *PlanFile) Sign(secret []byte) error {
+	mac, err := p.mac(secret)
+	if err != nil {
+		return err
+	}
+	p.Signature = hex.EncodeToString(mac)
+	return nil
+}
+
+// Verify returns an error if the plan file was not signed with the given
+// secret or was modified since it was signed.
+func (p *PlanFile) Verify(secret []byte) error {
+	if p.Signature == "" {
+		return errors.New("plan is not signed")
+	}
+	signature, err := hex.DecodeString(p.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid plan signature: %w", err)
+	}
+	mac, err := p.mac(secret)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(signature, mac) {
+		return errors.New("plan signature does not match, the plan was modified or signed with a different secret")
+	}
+	return nil
+}
+
+// VerifyUpstream returns an error if the given upstream config is not the
+// one the plan was computed against.
+func (p *PlanFile) VerifyUpstream(upstreamCfg *config.Config) error {
+	hash, err := UpstreamHash(upstreamCfg)
+	if err != nil {
+		return err
+	}
+	if hash != p.UpstreamHash {
+		return fmt.Errorf("upstream teams changed since the plan was created at %s, create a new plan", p.CreatedAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// mac returns the HMAC-SHA256 of the plan file without its signature.
+func (p *PlanFile) mac(secret []byte) ([]byte, error) {
+	unsigned := *p
+	unsigned.Signature = ""
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, secret)
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// StorePlanFile stores the given plan file.
+func StorePlanFile(file string, p *PlanFile) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return renameio.WriteFile(file, data, 0o600)
+}
+
+// LoadPlanFile loads a plan file.
+func LoadPlanFile(file string) (*PlanFile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var p PlanFile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %q: %w", file, err)
+	}
+	return &p, nil
+}
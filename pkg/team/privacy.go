@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// redactedMetadata are the Metadata keys of the members that hold personal
+// data.
+var redactedMetadata = []string{MetadataSSONameID, MetadataSSOEmail}
+
+// RedactMembers removes the names, email addresses and SSO identities of the
+// members of cfg, leaving only their logins, IDs and team memberships.
+func RedactMembers(cfg *config.Config) {
+	for login, user := range cfg.Members {
+		user.Name = ""
+		user.Email = ""
+		if len(user.Metadata) != 0 {
+			// The metadata may be shared with another configuration, e.g.
+			// the previous snapshot, so it is copied.
+			metadata := make(map[string]string, len(user.Metadata))
+			for k, v := range user.Metadata {
+				metadata[k] = v
+			}
+			for _, k := range redactedMetadata {
+				delete(metadata, k)
+			}
+			user.Metadata = metadata
+			if len(metadata) == 0 {
+				user.Metadata = nil
+			}
+		}
+		cfg.Members[login] = user
+	}
+}
+
+// NewPseudonym returns a random login replacing the login of a purged
+// member. It is random rather than derived from the login, which would be
+// easy to reverse given that logins are public.
+func NewPseudonym() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "purged-" + hex.EncodeToString(b), nil
+}
+
+// PurgeMember removes the member with the given login from cfg, with all its
+// personal data, and replaces its login with pseudonym in the team
+// memberships, exclusions and pending removals of cfg, so that the history of
+// these memberships is preserved. It returns false if login isn't referenced
+// by cfg.
+func PurgeMember(cfg *config.Config, login, pseudonym string) bool {
+	purged := replaceLogin(cfg, login, pseudonym)
+	member := false
+	for _, teamCfg := range cfg.Teams {
+		for _, l := range teamCfg.Members {
+			member = member || l == pseudonym
+		}
+	}
+	for l := range cfg.Members {
+		if strings.EqualFold(l, login) {
+			delete(cfg.Members, l)
+			purged = true
+		}
+	}
+	if member {
+		// Team members must be members of the configuration, the
+		// pseudonym is one without any data.
+		cfg.Members[pseudonym] = config.User{}
+	}
+	return purged
+}
+
+// replaceLogin replaces the given login with newLogin in the teams, retired
+// teams, exclusions, owners and pending removals of cfg, but not in its
+// members. It returns false if login isn't referenced by them.
+func replaceLogin(cfg *config.Config, login, newLogin string) bool {
+	replaced := false
+	replace := func(logins []string) {
+		for i, l := range logins {
+			if strings.EqualFold(l, login) {
+				logins[i] = newLogin
+				replaced = true
+			}
+		}
+	}
+	replaceExcluded := func(excluded []config.ExcludedMember) {
+		for i, x := range excluded {
+			if strings.EqualFold(x.Login, login) {
+				excluded[i].Login = newLogin
+				replaced = true
+			}
+		}
+	}
+
+	for _, teamCfg := range cfg.Teams {
+		replace(teamCfg.Members)
+		replace(teamCfg.Maintainers)
+		replaceExcluded(teamCfg.CodeReviewAssignment.ExcludedMembers)
+	}
+	for _, retired := range cfg.Retired {
+		replace(retired.Members)
+		replace(retired.Maintainers)
+		replaceExcluded(retired.CodeReviewAssignment.ExcludedMembers)
+	}
+	replace(cfg.ExcludeCRAFromAllTeams)
+	replace(cfg.Owners)
+	for i, r := range cfg.PendingRemovals {
+		if strings.EqualFold(r.Login, login) {
+			cfg.PendingRemovals[i].Login = newLogin
+			replaced = true
+		}
+	}
+	return replaced
+}
@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	gh "github.com/google/go-github/v33/github"
+	"github.com/shurcooL/githubv4"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+)
+
+// ClassicProjectGrant is the permission of a team on a classic organization
+// project.
+type ClassicProjectGrant struct {
+	Team        string
+	Project     int
+	ProjectName string
+	Permission  config.ClassicProjectPermission
+}
+
+// classicProject is a classic project as returned by the REST API, which
+// go-github doesn't decode the team permissions of.
+type classicProject struct {
+	Number      int    `json:"number"`
+	Name        string `json:"name"`
+	Permissions struct {
+		Read  bool `json:"read"`
+		Write bool `json:"write"`
+		Admin bool `json:"admin"`
+	} `json:"permissions"`
+}
+
+// ListClassicProjectGrants returns the permissions of the teams of cfg on
+// classic organization projects, sorted by team and project.
+func (tm *Manager) ListClassicProjectGrants(ctx context.Context, cfg *config.Config) ([]ClassicProjectGrant, error) {
+	var grants []ClassicProjectGrant
+	for _, teamName := range sortedKeys(cfg.Teams) {
+		opts := &gh.ListOptions{PerPage: 100, Page: 1}
+		for {
+			u := fmt.Sprintf("orgs/%s/teams/%s/projects?per_page=%d&page=%d", tm.owner, TeamSlug(cfg, teamName), opts.PerPage, opts.Page)
+			req, err := tm.ghClient.NewRequest("GET", u, nil)
+			if err != nil {
+				return nil, err
+			}
+			// Team projects are still in preview.
+			req.Header.Set("Accept", "application/vnd.github.inertia-preview+json")
+			var page []classicProject
+			resp, err := tm.ghClient.Do(ctx, req, &page)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list projects of team %s: %w", teamName, err)
+			}
+			for _, p := range page {
+				perm := config.ClassicProjectPermissionRead
+				switch {
+				case p.Permissions.Admin:
+					perm = config.ClassicProjectPermissionAdmin
+				case p.Permissions.Write:
+					perm = config.ClassicProjectPermissionWrite
+				}
+				grants = append(grants, ClassicProjectGrant{
+					Team:        teamName,
+					Project:     p.Number,
+					ProjectName: p.Name,
+					Permission:  perm,
+				})
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+	sort.SliceStable(grants, func(i, j int) bool {
+		if grants[i].Team != grants[j].Team {
+			return grants[i].Team < grants[j].Team
+		}
+		return grants[i].Project < grants[j].Project
+	})
+	return grants, nil
+}
+
+// ProjectMigration is the role a team is granted on a Project, migrated from
+// its permission on a classic project.
+type ProjectMigration struct {
+	Grant   ClassicProjectGrant
+	Project int
+	Role    config.ProjectRole
+}
+
+// PlanProjectMigrations returns the migrations of the given classic project
+// grants according to the projects migration of cfg, and the grants of
+// classic projects that aren't mapped to any Project. Grants mapped to the
+// NONE role are left out.
+func PlanProjectMigrations(cfg *config.Config, grants []ClassicProjectGrant) (migrations []ProjectMigration, unmapped []ClassicProjectGrant) {
+	for _, g := range grants {
+		project, ok := cfg.ProjectsMigration.Projects[g.Project]
+		if !ok {
+			unmapped = append(unmapped, g)
+			continue
+		}
+		role := cfg.ProjectsMigration.Role(g.Permission)
+		if role == config.ProjectRoleNone {
+			continue
+		}
+		migrations = append(migrations, ProjectMigration{
+			Grant:   g,
+			Project: project,
+			Role:    role,
+		})
+	}
+	return migrations, unmapped
+}
+
+// PrintProjectMigrations prints the given migrations.
+func PrintProjectMigrations(w io.Writer, migrations []ProjectMigration) {
+	for _, m := range migrations {
+		fmt.Fprintf(w, " Team: %s\n", m.Grant.Team)
+		fmt.Fprintf(w, "    Classic project #%d %q (%s) -> project #%d (%s)\n", m.Grant.Project, m.Grant.ProjectName, m.Grant.Permission, m.Project, m.Role)
+	}
+}
+
+// MigrateProjects grants the teams the roles of the given migrations on
+// their Projects. Failing projects are reported and do not prevent the
+// remaining ones from being migrated, their number is returned.
+func (tm *Manager) MigrateProjects(ctx context.Context, cfg *config.Config, migrations []ProjectMigration) (failed int) {
+	byProject := map[int][]ProjectMigration{}
+	var numbers []int
+	for _, m := range migrations {
+		if _, ok := byProject[m.Project]; !ok {
+			numbers = append(numbers, m.Project)
+		}
+		byProject[m.Project] = append(byProject[m.Project], m)
+	}
+	sort.Ints(numbers)
+	for _, number := range numbers {
+		tm.reporter.Progress("Granting teams access to project #%d", number)
+		if err := tm.migrateProject(ctx, cfg, number, byProject[number]); err != nil {
+			tm.reporter.Error("Unable to grant teams access to project #%d: %s", number, github.TranslateError(err))
+			failed++
+		}
+	}
+	return failed
+}
+
+// migrateProject grants the teams of the given migrations their roles on the
+// Project with the given number.
+func (tm *Manager) migrateProject(ctx context.Context, cfg *config.Config, number int, migrations []ProjectMigration) error {
+	projectID, err := tm.queryProjectID(ctx, number)
+	if err != nil {
+		return err
+	}
+
+	// A team granted access to several classic projects migrated to the
+	// same Project is granted the highest role.
+	roles := map[string]config.ProjectRole{}
+	for _, m := range migrations {
+		if projectRoleLevel(m.Role) > projectRoleLevel(roles[m.Grant.Team]) {
+			roles[m.Grant.Team] = m.Role
+		}
+	}
+	input := github.UpdateProjectV2CollaboratorsInput{ProjectID: projectID}
+	for _, teamName := range sortedKeys(roles) {
+		teamID := cfg.Teams[teamName].ID
+		if teamID == "" {
+			return fmt.Errorf("team %s has no ID in the configuration", teamName)
+		}
+		input.Collaborators = append(input.Collaborators, github.ProjectV2Collaborator{
+			TeamID: githubv4.ID(teamID),
+			Role:   roles[teamName],
+		})
+	}
+
+	var m struct {
+		UpdateProjectV2Collaborators struct {
+			ClientMutationID string
+		} `graphql:"updateProjectV2Collaborators(input: $input)"`
+	}
+	return tm.gqlGHClient.Mutate(ctx, &m, input, nil)
+}
+
+// queryProjectID returns the ID of the Project of the organization with the
+// given number.
+func (tm *Manager) queryProjectID(ctx context.Context, number int) (githubv4.ID, error) {
+	query := github.BuildQuery(
+		map[string]string{"owner": "String!", "number": "Int!"},
+		github.NewField("organization",
+			github.NewField("projectV2", github.NewField("id")).WithArgs("number: $number"),
+		).WithArgs("login: $owner"),
+	)
+
+	var q struct {
+		Organization struct {
+			ProjectV2 *struct {
+				ID string
+			}
+		}
+	}
+	variables := map[string]interface{}{
+		"owner":  tm.owner,
+		"number": number,
+	}
+	if err := tm.gqlGHClient.QueryRaw(ctx, query, variables, &q); err != nil {
+		return nil, err
+	}
+	if q.Organization.ProjectV2 == nil {
+		return nil, fmt.Errorf("project #%d not found", number)
+	}
+	return githubv4.ID(q.Organization.ProjectV2.ID), nil
+}
+
+// projectRoleLevel returns the level of access granted by the given role.
+func projectRoleLevel(role config.ProjectRole) int {
+	switch role {
+	case config.ProjectRoleReader:
+		return 1
+	case config.ProjectRoleWriter:
+		return 2
+	case config.ProjectRoleAdmin:
+		return 3
+	}
+	return 0
+}
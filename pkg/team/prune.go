@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// PruneTeams deletes the upstream teams that are neither teams nor retired
+// teams of localCfg, after asking for confirmation unless force is set. Teams
+// renamed in GitHub, whose ID is still one of a team of localCfg, are not
+// deleted.
+//
+// As GitHub deletes the child teams of a team together with it, teams that
+// are ancestors of teams of localCfg are never deleted, and child teams are
+// deleted before their parent.
+func (tm *Manager) PruneTeams(ctx context.Context, localCfg *config.Config, force, dryRun bool) error {
+	teams, err := tm.queryTeams(ctx, teamQueryOptions{hierarchy: true})
+	if err != nil {
+		return err
+	}
+	parents := make(map[string]string, len(teams))
+	for _, t := range teams {
+		if t.ParentTeam != nil {
+			parents[t.Name] = t.ParentTeam.Name
+		}
+	}
+
+	// ancestors returns the ancestors of the given team, nearest first.
+	ancestors := func(teamName string) []string {
+		var names []string
+		visited := map[string]bool{teamName: true}
+		for parent := parents[teamName]; parent != "" && !visited[parent]; parent = parents[parent] {
+			visited[parent] = true
+			names = append(names, parent)
+		}
+		return names
+	}
+
+	ids := map[string]string{}
+	protected := map[string]string{}
+	for teamName, teamCfg := range localCfg.Teams {
+		if teamCfg.ID != "" {
+			ids[teamCfg.ID] = teamName
+		}
+		for _, ancestor := range ancestors(teamName) {
+			protected[ancestor] = teamName
+		}
+	}
+
+	var prune []string
+	for _, t := range teams {
+		if _, ok := localCfg.Teams[t.Name]; ok {
+			continue
+		}
+		if _, ok := localCfg.Retired[t.Name]; ok {
+			continue
+		}
+		if teamName, ok := ids[t.ID]; ok {
+			tm.reporter.Warn("Not deleting team %s, it is team %s of the configuration renamed in GitHub", t.Name, teamName)
+			continue
+		}
+		if child, ok := protected[t.Name]; ok {
+			tm.reporter.Warn("Not deleting team %s, it is an ancestor of team %s of the configuration", t.Name, child)
+			continue
+		}
+		prune = append(prune, t.Name)
+	}
+	if len(prune) == 0 {
+		tm.reporter.Progress("No teams to prune")
+		return nil
+	}
+	// Deepest teams first, so that child teams are deleted before their
+	// parent.
+	sort.Slice(prune, func(i, j int) bool {
+		di, dj := len(ancestors(prune[i])), len(ancestors(prune[j]))
+		if di != dj {
+			return di > dj
+		}
+		return prune[i] < prune[j]
+	})
+
+	tm.reporter.Plan(PlanEvent{
+		Title:   "Going to delete the following teams that are not part of the configuration",
+		Changes: prune,
+		Print: func(w io.Writer) {
+			for _, teamName := range prune {
+				fmt.Fprintf(w, " Team: %s\n", teamName)
+			}
+		},
+	})
+	if !force {
+		yes, err := terminal.AskForConfirmation("Continue?")
+		if err != nil {
+			return err
+		}
+		if !yes {
+			return nil
+		}
+	}
+
+	var failed int
+	for _, teamName := range prune {
+		tm.reporter.Progress("Deleting team %s", teamName)
+		if dryRun {
+			continue
+		}
+		if err := tm.DeleteTeam(ctx, teamName); err != nil {
+			tm.reporter.Error("Unable to delete team %s: %s", teamName, github.TranslateError(err))
+			failed++
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d teams could not be deleted", failed)
+	}
+	return nil
+}
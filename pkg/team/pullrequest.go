@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	gh "github.com/google/go-github/v33/github"
+)
+
+// OpenFilePullRequest opens a pull request against the default branch of the
+// given repository that creates or replaces the file at path with content.
+// The pull request is opened from a branch named after the title, which is
+// reused along with its open pull request if it already exists, e.g. when
+// the same change is requested again. If the repository is empty, the file is
+// committed directly to its default branch and no pull request is returned.
+func (tm *Manager) OpenFilePullRequest(ctx context.Context, repo, path string, content []byte, title, body string) (*gh.PullRequest, error) {
+	r, _, err := tm.ghClient.Repositories.Get(ctx, tm.owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+	base := r.GetDefaultBranch()
+
+	baseRef, resp, err := tm.ghClient.Git.GetRef(ctx, tm.owner, repo, "refs/heads/"+base)
+	if resp != nil && (resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusNotFound) {
+		// The repository has no commits yet: there is nothing to open a
+		// pull request against, and the file creates the default branch.
+		_, _, err = tm.ghClient.Repositories.CreateFile(ctx, tm.owner, repo, path, &gh.RepositoryContentFileOptions{
+			Message: gh.String(title),
+			Content: content,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to commit %q: %w", path, err)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch %q: %w", base, err)
+	}
+
+	branch := "team-manager/" + Slug(title)
+	_, resp, err = tm.ghClient.Git.GetRef(ctx, tm.owner, repo, "refs/heads/"+branch)
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		_, _, err = tm.ghClient.Git.CreateRef(ctx, tm.owner, repo, &gh.Reference{
+			Ref:    gh.String("refs/heads/" + branch),
+			Object: &gh.GitObject{SHA: baseRef.Object.SHA},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create branch %q: %w", branch, err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get branch %q: %w", branch, err)
+	}
+
+	opts := &gh.RepositoryContentFileOptions{
+		Message: gh.String(title),
+		Content: content,
+		Branch:  gh.String(branch),
+	}
+	existing, _, resp, err := tm.ghClient.Repositories.GetContents(ctx, tm.owner, repo, path, &gh.RepositoryContentGetOptions{Ref: branch})
+	switch {
+	case err == nil && existing == nil:
+		return nil, fmt.Errorf("%q is a directory", path)
+	case err == nil:
+		current, err := existing.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %q: %w", path, err)
+		}
+		if current != string(content) {
+			opts.SHA = existing.SHA
+			_, _, err = tm.ghClient.Repositories.UpdateFile(ctx, tm.owner, repo, path, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to commit %q: %w", path, err)
+			}
+		}
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		_, _, err = tm.ghClient.Repositories.CreateFile(ctx, tm.owner, repo, path, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to commit %q: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to get %q: %w", path, err)
+	}
+
+	open, _, err := tm.ghClient.PullRequests.List(ctx, tm.owner, repo, &gh.PullRequestListOptions{
+		State: "open",
+		Head:  tm.owner + ":" + branch,
+		Base:  base,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	if len(open) != 0 {
+		return open[0], nil
+	}
+
+	pr, _, err := tm.ghClient.PullRequests.Create(ctx, tm.owner, repo, &gh.NewPullRequest{
+		Title: gh.String(title),
+		Head:  gh.String(branch),
+		Base:  gh.String(base),
+		Body:  gh.String(body),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pull request: %w", err)
+	}
+	return pr, nil
+}
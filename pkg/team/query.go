@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/github"
+)
+
+// teamQueryOptions selects the optional fields of the team queries, so that
+// every query only pays for the fields it needs.
+type teamQueryOptions struct {
+	// members selects the members of every team.
+	members bool
+	// reviewAssignment selects the code review assignment settings.
+	reviewAssignment bool
+	// hierarchy selects the parent team.
+	hierarchy bool
+}
+
+// fullTeamQuery selects all fields of teams stored in the config.
+var fullTeamQuery = teamQueryOptions{
+	members:          true,
+	reviewAssignment: true,
+	hierarchy:        true,
+}
+
+// team is a team as returned by queries built by teamFields. Fields that
+// weren't selected are left empty.
+type team struct {
+	ID                                 string
+	DatabaseID                         int
+	Name                               string
+	Slug                               string
+	Description                        string
+	Privacy                            string
+	NotificationSetting                string
+	UpdatedAt                          time.Time
+	ReviewRequestDelegationEnabled     bool
+	ReviewRequestDelegationAlgorithm   string
+	ReviewRequestDelegationMemberCount int
+	ReviewRequestDelegationNotifyTeam  bool
+	ParentTeam                         *struct {
+		Name string
+	}
+	Members teamMembers
+}
+
+// teamMembers is the result of a field built with membersField.
+type teamMembers struct {
+	Edges    []teamMemberEdge
+	PageInfo github.PageInfo
+}
+
+type teamMemberEdge struct {
+	Role string
+	Node teamMember
+}
+
+// Nodes returns the members together with their role.
+func (m teamMembers) Nodes() []teamMember {
+	members := make([]teamMember, 0, len(m.Edges))
+	for _, e := range m.Edges {
+		member := e.Node
+		member.Role = e.Role
+		members = append(members, member)
+	}
+	return members
+}
+
+type teamMember struct {
+	ID    string
+	Login string
+	Name  string
+	// Role is either MAINTAINER or MEMBER.
+	Role string
+}
+
+// IsMaintainer returns true if the member has the maintainer role.
+func (m teamMember) IsMaintainer() bool {
+	return m.Role == "MAINTAINER"
+}
+
+// teamFields returns the fields of a team selected by opts.
+func teamFields(opts teamQueryOptions) []*github.Field {
+	fields := github.Fields("id", "databaseId", "name", "slug", "description", "privacy", "notificationSetting", "updatedAt")
+	if opts.reviewAssignment {
+		fields = append(fields, github.Fields(
+			"reviewRequestDelegationEnabled",
+			"reviewRequestDelegationAlgorithm",
+			"reviewRequestDelegationMemberCount",
+			"reviewRequestDelegationNotifyTeam",
+		)...)
+	}
+	if opts.hierarchy {
+		fields = append(fields, github.NewField("parentTeam", github.NewField("name")))
+	}
+	if opts.members {
+		fields = append(fields, membersField("first: 100"))
+	}
+	return fields
+}
+
+func membersField(args string) *github.Field {
+	return github.NewField("members",
+		github.NewField("edges",
+			github.NewField("role"),
+			github.NewField("node", github.Fields("id", "login", "name")...),
+		),
+		github.NewField("pageInfo", github.Fields("endCursor", "hasNextPage")...),
+	).WithArgs(args)
+}
+
+// queryTeams returns all teams of the organization with the fields selected
+// by opts.
+//
+//	{
+//	 organization(login: "cilium") {
+//	   teams(first: 100) {
+//	     nodes {
+//	       id
+//	       name
+//	       ...
+//	       members(first: 100) {
+//	         edges {
+//	           role
+//	           node {
+//	             id
+//	             login
+//	           }
+//	         }
+//	       }
+//	     }
+//	   }
+//	 }
+//	}
+func (tm *Manager) queryTeams(ctx context.Context, opts teamQueryOptions) ([]team, error) {
+	query := github.BuildQuery(
+		map[string]string{"owner": "String!", "teamsCursor": "String"},
+		github.NewField("organization",
+			github.Connection("teams", "first: 100, after: $teamsCursor", teamFields(opts)...),
+		).WithArgs("login: $owner"),
+	)
+
+	var teams []team
+	variables := map[string]interface{}{
+		"owner":       tm.owner,
+		"teamsCursor": nil,
+	}
+	for {
+		var q struct {
+			Organization struct {
+				Teams github.ConnectionResult[team]
+			}
+		}
+		if err := tm.gqlGHClient.QueryRaw(ctx, query, variables, &q); err != nil {
+			return nil, err
+		}
+		teams = append(teams, q.Organization.Teams.Nodes...)
+		for _, t := range q.Organization.Teams.Nodes {
+			tm.slugs[t.Name] = t.Slug
+		}
+		if !q.Organization.Teams.PageInfo.HasNextPage {
+			break
+		}
+		variables["teamsCursor"] = q.Organization.Teams.PageInfo.EndCursor
+	}
+
+	if opts.members {
+		// Only the first page of members is queried together with the
+		// teams, the remaining ones are queried per team.
+		for i, t := range teams {
+			if !t.Members.PageInfo.HasNextPage {
+				continue
+			}
+			members, err := tm.queryTeamMembers(ctx, t.Slug, t.Members.PageInfo.EndCursor)
+			if err != nil {
+				return nil, fmt.Errorf("failed to query members of team %q: %w", t.Name, err)
+			}
+			for _, member := range members {
+				teams[i].Members.Edges = append(teams[i].Members.Edges, teamMemberEdge{Role: member.Role, Node: member})
+			}
+		}
+	}
+	return teams, nil
+}
+
+// queryTeam returns the team with the given slug with the fields selected by
+// opts. Members are not paginated.
+func (tm *Manager) queryTeam(ctx context.Context, teamSlug string, opts teamQueryOptions) (team, error) {
+	query := github.BuildQuery(
+		map[string]string{"owner": "String!", "slug": "String!"},
+		github.NewField("organization",
+			github.NewField("team", teamFields(opts)...).WithArgs("slug: $slug"),
+		).WithArgs("login: $owner"),
+	)
+
+	var q struct {
+		Organization struct {
+			Team *team
+		}
+	}
+	variables := map[string]interface{}{
+		"owner": tm.owner,
+		"slug":  teamSlug,
+	}
+	if err := tm.gqlGHClient.QueryRaw(ctx, query, variables, &q); err != nil {
+		return team{}, err
+	}
+	if q.Organization.Team == nil {
+		return team{}, fmt.Errorf("team %q not found", teamSlug)
+	}
+	return *q.Organization.Team, nil
+}
+
+// queryTeamMembers returns the members of the team with the given slug,
+// starting after the given cursor, or from the first one if it is empty.
+func (tm *Manager) queryTeamMembers(ctx context.Context, teamSlug, after string) ([]teamMember, error) {
+	query := github.BuildQuery(
+		map[string]string{"owner": "String!", "slug": "String!", "membersCursor": "String"},
+		github.NewField("organization",
+			github.NewField("team",
+				membersField("first: 100, after: $membersCursor"),
+			).WithArgs("slug: $slug"),
+		).WithArgs("login: $owner"),
+	)
+
+	var members []teamMember
+	variables := map[string]interface{}{
+		"owner":         tm.owner,
+		"slug":          teamSlug,
+		"membersCursor": nil,
+	}
+	if after != "" {
+		variables["membersCursor"] = after
+	}
+	for {
+		var q struct {
+			Organization struct {
+				Team struct {
+					Members teamMembers
+				}
+			}
+		}
+		if err := tm.gqlGHClient.QueryRaw(ctx, query, variables, &q); err != nil {
+			return nil, err
+		}
+		members = append(members, q.Organization.Team.Members.Nodes()...)
+		if !q.Organization.Team.Members.PageInfo.HasNextPage {
+			return members, nil
+		}
+		variables["membersCursor"] = q.Organization.Team.Members.PageInfo.EndCursor
+	}
+}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	gh "github.com/google/go-github/v33/github"
+)
+
+// CodeOwnersPaths are the locations GitHub looks up CODEOWNERS files in,
+// relative to the root of repositories.
+var CodeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// TeamReference is a reference to a team in a repository.
+type TeamReference struct {
+	Repository string
+	Branch     string
+	// Path is the path of the CODEOWNERS file containing the reference, or
+	// empty if the reference is in a branch protection rule.
+	Path string
+	// Line is the line number of the reference in the CODEOWNERS file.
+	Line int
+	// Rule is the part of the branch protection rule referencing the team.
+	Rule string
+}
+
+func (r TeamReference) String() string {
+	if r.Path != "" {
+		return fmt.Sprintf("%s@%s: %s line %d", r.Repository, r.Branch, r.Path, r.Line)
+	}
+	return fmt.Sprintf("%s@%s: %s", r.Repository, r.Branch, r.Rule)
+}
+
+// FindTeamReferences returns the references to the team with the given slug
+// in the CODEOWNERS files and the branch protection rules of the default
+// branch of the given repositories.
+func (tm *Manager) FindTeamReferences(ctx context.Context, teamSlug string, repos []string) ([]TeamReference, error) {
+	owner := "@" + tm.owner + "/" + teamSlug
+	var refs []TeamReference
+	for _, repo := range repos {
+		r, _, err := tm.ghClient.Repositories.Get(ctx, tm.owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repository %q: %w", repo, err)
+		}
+		branch := r.GetDefaultBranch()
+
+		for _, path := range CodeOwnersPaths {
+			content, err := tm.getFileContent(ctx, repo, branch, path)
+			if err != nil {
+				return nil, err
+			}
+			for _, line := range codeOwnersLines(content, owner) {
+				refs = append(refs, TeamReference{Repository: repo, Branch: branch, Path: path, Line: line})
+			}
+		}
+
+		protection, resp, err := tm.getBranchProtection(ctx, repo, branch)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get branch protection of %s@%s: %w", repo, branch, err)
+		}
+		for _, ref := range branchProtectionTeamRefs(protection) {
+			for _, t := range ref.teams {
+				if t.GetSlug() == teamSlug {
+					refs = append(refs, TeamReference{Repository: repo, Branch: branch, Rule: ref.rule})
+				}
+			}
+		}
+	}
+	return refs, nil
+}
+
+// GetFileContent returns the content of the given file on the default branch
+// of the given repository, or an empty string if it doesn't exist.
+func (tm *Manager) GetFileContent(ctx context.Context, repo, path string) (string, error) {
+	return tm.getFileContent(ctx, repo, "", path)
+}
+
+// getFileContent returns the content of the given file, or an empty string
+// if it doesn't exist.
+func (tm *Manager) getFileContent(ctx context.Context, repo, branch, path string) (string, error) {
+	file, _, resp, err := tm.ghClient.Repositories.GetContents(ctx, tm.owner, repo, path, &gh.RepositoryContentGetOptions{Ref: branch})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get %s of %s@%s: %w", path, repo, branch, err)
+	}
+	if file == nil {
+		// path is a directory.
+		return "", nil
+	}
+	return file.GetContent()
+}
+
+// codeOwnersLines returns the numbers of the lines of the given CODEOWNERS
+// file that list owner.
+func codeOwnersLines(content, owner string) []int {
+	var lines []int
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		for _, field := range strings.Fields(line) {
+			if strings.EqualFold(field, owner) {
+				lines = append(lines, n)
+				break
+			}
+		}
+	}
+	return lines
+}
+
+// RewriteCodeOwners returns the given CODEOWNERS file with all references to
+// oldOwner replaced by newOwner, leaving comments untouched.
+func RewriteCodeOwners(content, oldOwner, newOwner string) string {
+	re := regexp.MustCompile(`(?i)(^|\s)` + regexp.QuoteMeta(oldOwner) + `(\s|$)`)
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		rules, comment, hasComment := strings.Cut(line, "#")
+		// Matches may overlap when the owner is listed twice in a row, so
+		// replace until there are no more matches.
+		for re.MatchString(rules) {
+			rules = re.ReplaceAllString(rules, "${1}"+newOwner+"${2}")
+		}
+		if hasComment {
+			rules += "#" + comment
+		}
+		lines[i] = rules
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"sort"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// ReleaseShepherds maps the names of the release cycles of cfg to the logins
+// of their shepherds. Cycles without a shepherd set are assigned one by
+// rotation: the members of the responsible team, sorted by login, take turns
+// in the order of the start of the cycles of the team, skipping the members
+// away at the start of the cycle. Cycles of teams without any available
+// member have no shepherd.
+func ReleaseShepherds(cfg *config.Config) map[string]string {
+	cycles := map[string][]config.ReleaseCycle{}
+	for _, r := range cfg.Releases {
+		cycles[r.Team] = append(cycles[r.Team], r)
+	}
+
+	shepherds := map[string]string{}
+	for teamName, teamCycles := range cycles {
+		sort.SliceStable(teamCycles, func(i, j int) bool {
+			return teamCycles[i].Start < teamCycles[j].Start
+		})
+		members := append([]string(nil), cfg.Teams[teamName].Members...)
+		sort.Strings(members)
+		for i, r := range teamCycles {
+			if r.Shepherd != "" {
+				shepherds[r.Name] = r.Shepherd
+				continue
+			}
+			for j := range members {
+				login := members[(i+j)%len(members)]
+				if !awayAt(cfg, teamName, login, r.Start) {
+					shepherds[r.Name] = login
+					break
+				}
+			}
+		}
+	}
+	return shepherds
+}
+
+// awayAt returns true if the given member is away on the given day, in the
+// YYYY-MM-DD format, i.e. temporarily excluded from the code review assignment
+// of the given team until a later day, e.g. on vacation.
+func awayAt(cfg *config.Config, teamName, login, day string) bool {
+	for _, xMember := range cfg.ExcludedMembers(teamName) {
+		if xMember.Login == login && xMember.Until != "" && (xMember.Since == "" || xMember.Since <= day) && day <= xMember.Until {
+			return true
+		}
+	}
+	return false
+}
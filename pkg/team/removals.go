@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// HoldPendingRemovals returns the configuration to push to GitHub for the
+// given local configuration, taking the removal grace period of its policy
+// into account: members removed from a team in localCfg that are still
+// members of that team upstream are kept in the team and excluded from its
+// code review assignment, until the grace period elapsed.
+//
+// The pending removals of localCfg are updated to track when every removal
+// was first seen, removals that were reverted or already performed upstream
+// are dropped. localCfg itself is otherwise left untouched.
+func HoldPendingRemovals(localCfg, upstreamCfg *config.Config, now time.Time) *config.Config {
+	if localCfg.Policy.GraceDays == 0 {
+		localCfg.PendingRemovals = nil
+		return localCfg
+	}
+
+	since := map[[2]string]string{}
+	for _, r := range localCfg.PendingRemovals {
+		since[[2]string{r.Team, r.Login}] = r.Since
+	}
+	today := now.Format(config.DateFormat)
+
+	effectiveCfg := *localCfg
+	effectiveCfg.Teams = make(map[string]config.TeamConfig, len(localCfg.Teams))
+	var pending []config.PendingRemoval
+	for _, teamName := range sortedKeys(localCfg.Teams) {
+		teamCfg := localCfg.Teams[teamName]
+		removed := set.DifferenceFold(upstreamCfg.Teams[teamName].Members, teamCfg.Members)
+		if len(removed) != 0 {
+			teamCfg.Members = append([]string(nil), teamCfg.Members...)
+			teamCfg.CodeReviewAssignment.ExcludedMembers = append([]config.ExcludedMember(nil), teamCfg.CodeReviewAssignment.ExcludedMembers...)
+		}
+		for _, login := range removed {
+			r := config.PendingRemoval{Team: teamName, Login: login, Since: today}
+			if s, ok := since[[2]string{teamName, login}]; ok {
+				r.Since = s
+			}
+			if removalDate(r, localCfg.Policy.GraceDays).After(now) {
+				teamCfg.Members = append(teamCfg.Members, login)
+				teamCfg.CodeReviewAssignment.ExcludedMembers = append(teamCfg.CodeReviewAssignment.ExcludedMembers, config.ExcludedMember{
+					Login:  login,
+					Reason: fmt.Sprintf("pending removal since %s", r.Since),
+					Since:  r.Since,
+				})
+			}
+			pending = append(pending, r)
+		}
+		sort.Strings(teamCfg.Members)
+		effectiveCfg.Teams[teamName] = teamCfg
+	}
+	localCfg.PendingRemovals = pending
+	effectiveCfg.PendingRemovals = pending
+	return &effectiveCfg
+}
+
+// PrintPendingRemovals prints the pending removals of the given configuration
+// together with the date they are performed.
+func PrintPendingRemovals(w io.Writer, cfg *config.Config) {
+	for _, r := range cfg.PendingRemovals {
+		fmt.Fprintf(w, " Team: %s, member: %s, pending since %s, removed on %s\n",
+			r.Team, r.Login, r.Since, removalDate(r, cfg.Policy.GraceDays).Format(config.DateFormat))
+	}
+}
+
+// removalDate returns the date the given pending removal is performed.
+func removalDate(r config.PendingRemoval, graceDays int) time.Time {
+	since, err := time.Parse(config.DateFormat, r.Since)
+	if err != nil {
+		// Sanity checked, should never happen.
+		return time.Time{}
+	}
+	return since.AddDate(0, 0, graceDays)
+}
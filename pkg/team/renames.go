@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// TeamRename is a team of the local configuration that was renamed in
+// GitHub, matched by its ID.
+type TeamRename struct {
+	ID       string
+	Local    string
+	Upstream string
+}
+
+// DetectRenames returns the teams of localCfg that exist in upstreamCfg with
+// the same ID but under another name, which isn't used by any team of
+// localCfg, sorted by their local name.
+func DetectRenames(localCfg, upstreamCfg *config.Config) []TeamRename {
+	upstreamNames := make(map[string]string, len(upstreamCfg.Teams))
+	for teamName, teamCfg := range upstreamCfg.Teams {
+		upstreamNames[teamCfg.ID] = teamName
+	}
+
+	var renames []TeamRename
+	for teamName, teamCfg := range localCfg.Teams {
+		if teamCfg.ID == "" {
+			continue
+		}
+		if _, ok := upstreamCfg.Teams[teamName]; ok {
+			continue
+		}
+		upstreamName, ok := upstreamNames[teamCfg.ID]
+		if !ok {
+			continue
+		}
+		if _, ok := localCfg.Teams[upstreamName]; ok {
+			continue
+		}
+		renames = append(renames, TeamRename{
+			ID:       teamCfg.ID,
+			Local:    teamName,
+			Upstream: upstreamName,
+		})
+	}
+	sort.Slice(renames, func(i, j int) bool {
+		return renames[i].Local < renames[j].Local
+	})
+	return renames
+}
+
+// PrintRenames prints the given renames.
+func PrintRenames(w io.Writer, renames []TeamRename) {
+	for _, r := range renames {
+		fmt.Fprintf(w, " Team: %s\n", r.Local)
+		fmt.Fprintf(w, "    Renamed to %s in GitHub\n", r.Upstream)
+	}
+}
+
+// resolveRenames resolves the teams renamed in GitHub, either by renaming
+// them back in GitHub or by renaming them in localCfg, as chosen by the user.
+// With force, the teams are renamed back in GitHub. upstreamCfg is updated
+// with the teams renamed back, so that they aren't created again. The local
+// names of the skipped teams, which must be left out of the sync, are
+// returned.
+func (tm *Manager) resolveRenames(ctx context.Context, localCfg, upstreamCfg *config.Config, force, dryRun bool) ([]string, error) {
+	renames := DetectRenames(localCfg, upstreamCfg)
+	if len(renames) == 0 {
+		return nil, nil
+	}
+
+	tm.reporter.Plan(PlanEvent{
+		Title:   "Found the following teams renamed in GitHub",
+		Changes: renames,
+		Print:   func(w io.Writer) { PrintRenames(w, renames) },
+	})
+	var skipped []string
+	for _, r := range renames {
+		action := "upstream"
+		if !force {
+			var err error
+			action, err = askRenameAction(r)
+			if err != nil {
+				return nil, err
+			}
+		}
+		switch action {
+		case "upstream":
+			tm.reporter.Progress("Renaming team %s back to %s", r.Upstream, r.Local)
+			if !dryRun {
+				if _, err := tm.RenameTeam(ctx, r.Upstream, r.Local); err != nil {
+					tm.reporter.Error("Unable to rename team %s: %s", r.Upstream, github.TranslateError(err))
+					skipped = append(skipped, r.Local)
+					continue
+				}
+			}
+			RenameTeamInConfig(upstreamCfg, r.Upstream, r.Local)
+			upstreamTeam := upstreamCfg.Teams[r.Local]
+			upstreamTeam.Slug = tm.teamSlug(r.Local)
+			upstreamCfg.Teams[r.Local] = upstreamTeam
+		case "local":
+			tm.reporter.Progress("Renaming team %s to %s in the configuration", r.Local, r.Upstream)
+			RenameTeamInConfig(localCfg, r.Local, r.Upstream)
+		default:
+			skipped = append(skipped, r.Local)
+		}
+	}
+	return skipped, nil
+}
+
+func askRenameAction(r TeamRename) (string, error) {
+	question := fmt.Sprintf("Team %s was renamed to %s in GitHub, rename it back in GitHub (u), rename it in the configuration (l) or skip (s)?", r.Local, r.Upstream)
+	for {
+		answer, err := terminal.AskForInput(question, "")
+		if err != nil {
+			return "", err
+		}
+		switch strings.ToLower(answer) {
+		case "u", "upstream":
+			return "upstream", nil
+		case "l", "local":
+			return "local", nil
+		case "s", "skip":
+			return "", nil
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Reporter receives the output of the operations of a Manager, e.g. to print
+// it or to encode it for other tools. The default reporter of a Manager is a
+// TextReporter printing to stdout and stderr.
+type Reporter interface {
+	// Plan reports changes that are about to be submitted.
+	Plan(event PlanEvent)
+	// Progress reports a step of an operation.
+	Progress(format string, args ...interface{})
+	// Warn reports a problem that doesn't prevent an operation from
+	// completing.
+	Warn(format string, args ...interface{})
+	// Error reports a change that failed, the remaining changes are still
+	// submitted.
+	Error(format string, args ...interface{})
+	// Summary reports the outcome of a sync.
+	Summary(summary Summary)
+}
+
+// PlanEvent are changes about to be submitted.
+type PlanEvent struct {
+	// Title describes the changes, e.g. "Going to create the following
+	// teams". It is empty for changes that aren't confirmed.
+	Title string `json:"title,omitempty"`
+	// Changes are the changes, e.g. the TeamChanges of a Plan.
+	Changes interface{} `json:"changes"`
+	// Print prints Changes in a human readable form.
+	Print func(w io.Writer) `json:"-"`
+}
+
+// Summary is the outcome of a sync.
+type Summary struct {
+	// Submitted is the number of changes submitted successfully.
+	Submitted int `json:"submitted"`
+	// Failed is the number of changes that failed.
+	Failed int `json:"failed"`
+	// DryRun is true if no change was actually submitted.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// SetReporter sets the reporter the output of the operations of tm is sent
+// to.
+func (tm *Manager) SetReporter(reporter Reporter) {
+	tm.reporter = reporter
+}
+
+// TextReporter prints the output of a Manager for humans, the warnings and
+// errors to Err and everything else to Out.
+type TextReporter struct {
+	Out io.Writer
+	Err io.Writer
+}
+
+func (r *TextReporter) Plan(event PlanEvent) {
+	if event.Title != "" {
+		fmt.Fprintf(r.Out, "%s:\n", event.Title)
+	}
+	event.Print(r.Out)
+}
+
+func (r *TextReporter) Progress(format string, args ...interface{}) {
+	fmt.Fprintf(r.Out, format+"\n", args...)
+}
+
+func (r *TextReporter) Warn(format string, args ...interface{}) {
+	fmt.Fprintf(r.Err, "[WARN]: "+format+"\n", args...)
+}
+
+func (r *TextReporter) Error(format string, args ...interface{}) {
+	fmt.Fprintf(r.Err, "[ERROR]: "+format+"\n", args...)
+}
+
+func (r *TextReporter) Summary(summary Summary) {
+	if summary.DryRun {
+		fmt.Fprintf(r.Out, "Dry run, %d changes not submitted\n", summary.Submitted+summary.Failed)
+		return
+	}
+	fmt.Fprintf(r.Out, "Submitted %d changes, %d failed\n", summary.Submitted, summary.Failed)
+}
+
+// JSONReporter writes the output of a Manager as a stream of JSON objects, one
+// per line, with an "event" field set to plan, progress, warn, error or
+// summary.
+type JSONReporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONReporter returns a JSONReporter writing to w.
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	return &JSONReporter{enc: json.NewEncoder(w)}
+}
+
+type jsonMessage struct {
+	Event   string `json:"event"`
+	Message string `json:"message"`
+}
+
+func (r *JSONReporter) encode(v interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Reporting must not fail the operations, there is nowhere else to
+	// report the error to anyway.
+	_ = r.enc.Encode(v)
+}
+
+func (r *JSONReporter) Plan(event PlanEvent) {
+	r.encode(struct {
+		Event string `json:"event"`
+		PlanEvent
+	}{"plan", event})
+}
+
+func (r *JSONReporter) Progress(format string, args ...interface{}) {
+	r.encode(jsonMessage{"progress", fmt.Sprintf(format, args...)})
+}
+
+func (r *JSONReporter) Warn(format string, args ...interface{}) {
+	r.encode(jsonMessage{"warn", fmt.Sprintf(format, args...)})
+}
+
+func (r *JSONReporter) Error(format string, args ...interface{}) {
+	r.encode(jsonMessage{"error", fmt.Sprintf(format, args...)})
+}
+
+func (r *JSONReporter) Summary(summary Summary) {
+	r.encode(struct {
+		Event string `json:"event"`
+		Summary
+	}{"summary", summary})
+}
+
+// SilentReporter discards the output of a Manager.
+type SilentReporter struct{}
+
+func (SilentReporter) Plan(PlanEvent)                  {}
+func (SilentReporter) Progress(string, ...interface{}) {}
+func (SilentReporter) Warn(string, ...interface{})     {}
+func (SilentReporter) Error(string, ...interface{})    {}
+func (SilentReporter) Summary(Summary)                 {}
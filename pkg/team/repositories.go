@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/set"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// ListRepositoriesCreatedSince returns the repositories of the organization
+// that were created after the given time, newest first.
+func (tm *Manager) ListRepositoriesCreatedSince(ctx context.Context, since time.Time) ([]*gh.Repository, error) {
+	opts := &gh.RepositoryListByOrgOptions{
+		Sort:        "created",
+		Direction:   "desc",
+		ListOptions: gh.ListOptions{PerPage: 100},
+	}
+	var repos []*gh.Repository
+	for {
+		page, resp, err := tm.ghClient.Repositories.ListByOrg(ctx, tm.owner, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range page {
+			if !repo.GetCreatedAt().After(since) {
+				return repos, nil
+			}
+			repos = append(repos, repo)
+		}
+		if resp.NextPage == 0 {
+			return repos, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// ErrDeclined is returned when the confirmation of changes was declined.
+var ErrDeclined = errors.New("changes declined")
+
+// ApplyRepositoryTemplates grants the team permissions of all repository
+// templates from cfg that match the given repositories. It returns
+// ErrDeclined if the changes weren't confirmed, and an error if any grant
+// failed.
+func (tm *Manager) ApplyRepositoryTemplates(ctx context.Context, cfg *config.Config, repos []string, force bool, dryRun bool) error {
+	type grant struct {
+		Repo string                      `json:"repository"`
+		Team string                      `json:"team"`
+		Perm config.RepositoryPermission `json:"permission"`
+	}
+	var grants []grant
+	for _, repo := range repos {
+		for _, tmpl := range cfg.RepositoryTemplates {
+			if !tmpl.Matches(repo) {
+				continue
+			}
+			for teamName, perm := range tmpl.Teams {
+				grants = append(grants, grant{Repo: repo, Team: teamName, Perm: perm})
+			}
+		}
+	}
+	if len(grants) == 0 {
+		tm.reporter.Progress("No repository template matches the given repositories")
+		return nil
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		if grants[i].Repo != grants[j].Repo {
+			return grants[i].Repo < grants[j].Repo
+		}
+		return grants[i].Team < grants[j].Team
+	})
+
+	tm.reporter.Plan(PlanEvent{
+		Title:   "Going to submit the following changes",
+		Changes: grants,
+		Print: func(w io.Writer) {
+			for _, g := range grants {
+				fmt.Fprintf(w, " Repository: %s\n", g.Repo)
+				fmt.Fprintf(w, "    Granting %s permission to team %s\n", g.Perm, g.Team)
+			}
+		},
+	})
+	yes := force
+	if !force {
+		var err error
+		yes, err = terminal.AskForConfirmation("Continue?")
+		if err != nil {
+			return err
+		}
+	}
+	if !yes {
+		return ErrDeclined
+	}
+	if dryRun {
+		return nil
+	}
+
+	var failed int
+	for _, g := range grants {
+		opts := &gh.TeamAddTeamRepoOptions{Permission: string(g.Perm)}
+		if _, err := tm.ghClient.Teams.AddTeamRepoBySlug(ctx, tm.owner, TeamSlug(cfg, g.Team), tm.owner, g.Repo, opts); err != nil {
+			tm.reporter.Error("Unable to grant %s permission on %s to team %s: %s", g.Perm, g.Repo, g.Team, github.TranslateError(err))
+			failed++
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d of %d grants failed", failed, len(grants))
+	}
+	return nil
+}
+
+// HandleRepositoryCreated applies the repository templates from cfg to the
+// newly created repository, sets the custom properties of the matching
+// templates and, if requested by a matching template, opens a pull request
+// adding a CODEOWNERS skeleton to it.
+func (tm *Manager) HandleRepositoryCreated(ctx context.Context, cfg *config.Config, repo string, dryRun bool) error {
+	if err := tm.ApplyRepositoryTemplates(ctx, cfg, []string{repo}, true, dryRun); err != nil {
+		return err
+	}
+	if err := tm.setRepositoryProperties(ctx, cfg, repo, dryRun); err != nil {
+		return err
+	}
+
+	owners := set.New[string]()
+	for _, tmpl := range cfg.RepositoryTemplates {
+		if !tmpl.CodeOwners || !tmpl.Matches(repo) {
+			continue
+		}
+		for teamName, perm := range tmpl.Teams {
+			// GitHub ignores code owners without write access.
+			if perm.CanWrite() {
+				owners.Add("@" + tm.owner + "/" + TeamSlug(cfg, teamName))
+			}
+		}
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+
+	content := fmt.Sprintf("# Generated by team-manager from the repository templates.\n* %s\n", strings.Join(owners.Elements(), " "))
+
+	tm.reporter.Progress("Opening pull request adding CODEOWNERS to %s", repo)
+	if dryRun {
+		return nil
+	}
+	pr, err := tm.OpenFilePullRequest(ctx, repo, ".github/CODEOWNERS", []byte(content),
+		"Add CODEOWNERS", "This adds the code owners defined by the team-manager repository templates.")
+	if err != nil {
+		return err
+	}
+	if pr == nil {
+		tm.reporter.Progress("Committed CODEOWNERS to the default branch of empty repository %s", repo)
+		return nil
+	}
+	tm.reporter.Progress("Opened %s", pr.GetHTMLURL())
+	return nil
+}
+
+// setRepositoryProperties sets the custom properties of the repository
+// templates from cfg matching the given repository on it.
+func (tm *Manager) setRepositoryProperties(ctx context.Context, cfg *config.Config, repo string, dryRun bool) error {
+	properties := map[string]string{}
+	for _, tmpl := range cfg.RepositoryTemplates {
+		if !tmpl.Matches(repo) {
+			continue
+		}
+		for name, value := range tmpl.Properties {
+			properties[name] = value
+		}
+	}
+	if len(properties) == 0 {
+		return nil
+	}
+
+	type propertyValue struct {
+		Name  string `json:"property_name"`
+		Value string `json:"value"`
+	}
+	values := make([]propertyValue, 0, len(properties))
+	for _, name := range sortedKeys(properties) {
+		tm.reporter.Progress("Setting custom property %s of %s to %q", name, repo, properties[name])
+		values = append(values, propertyValue{Name: name, Value: properties[name]})
+	}
+	if dryRun {
+		return nil
+	}
+	req, err := tm.ghClient.NewRequest("PATCH", fmt.Sprintf("repos/%s/%s/properties/values", tm.owner, repo), struct {
+		Properties []propertyValue `json:"properties"`
+	}{values})
+	if err != nil {
+		return err
+	}
+	if _, err := tm.ghClient.Do(ctx, req, nil); err != nil {
+		return fmt.Errorf("failed to set custom properties of %s: %w", repo, err)
+	}
+	return nil
+}
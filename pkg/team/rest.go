@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"net/http"
+
+	gh "github.com/google/go-github/v33/github"
+)
+
+// restClient is the subset of the GitHub REST API the Manager uses, grouped by
+// service as in gh.Client so that upgrading go-github only requires adapting
+// these interfaces rather than every operation.
+type restClient struct {
+	requester
+
+	Git           gitService
+	Issues        issuesService
+	Organizations organizationsService
+	PullRequests  pullRequestsService
+	Repositories  repositoriesService
+	Search        searchService
+	Teams         teamsService
+	Users         usersService
+}
+
+// newRESTClient returns the restClient performing the operations with c, nil
+// if c is nil.
+func newRESTClient(c *gh.Client) *restClient {
+	if c == nil {
+		return nil
+	}
+	return &restClient{
+		requester:     c,
+		Git:           c.Git,
+		Issues:        c.Issues,
+		Organizations: c.Organizations,
+		PullRequests:  c.PullRequests,
+		Repositories:  c.Repositories,
+		Search:        c.Search,
+		Teams:         c.Teams,
+		Users:         c.Users,
+	}
+}
+
+// requester performs the requests of the REST API operations go-github
+// doesn't implement.
+type requester interface {
+	NewRequest(method, urlStr string, body interface{}) (*http.Request, error)
+	Do(ctx context.Context, req *http.Request, v interface{}) (*gh.Response, error)
+}
+
+// gitService are the operations of the Git database REST API the Manager uses.
+type gitService interface {
+	CreateRef(ctx context.Context, owner string, repo string, ref *gh.Reference) (*gh.Reference, *gh.Response, error)
+	GetRef(ctx context.Context, owner string, repo string, ref string) (*gh.Reference, *gh.Response, error)
+	GetTree(ctx context.Context, owner string, repo string, sha string, recursive bool) (*gh.Tree, *gh.Response, error)
+}
+
+// issuesService are the operations of the issues REST API the Manager uses.
+type issuesService interface {
+	CreateLabel(ctx context.Context, owner string, repo string, label *gh.Label) (*gh.Label, *gh.Response, error)
+	DeleteLabel(ctx context.Context, owner string, repo string, name string) (*gh.Response, error)
+	EditLabel(ctx context.Context, owner string, repo string, name string, label *gh.Label) (*gh.Label, *gh.Response, error)
+	ListLabels(ctx context.Context, owner string, repo string, opts *gh.ListOptions) ([]*gh.Label, *gh.Response, error)
+}
+
+// organizationsService are the operations of the organizations REST API the
+// Manager uses.
+type organizationsService interface {
+	Edit(ctx context.Context, name string, org *gh.Organization) (*gh.Organization, *gh.Response, error)
+	Get(ctx context.Context, org string) (*gh.Organization, *gh.Response, error)
+	CreateOrgInvitation(ctx context.Context, org string, opts *gh.CreateOrgInvitationOptions) (*gh.Invitation, *gh.Response, error)
+	EditOrgMembership(ctx context.Context, user, org string, membership *gh.Membership) (*gh.Membership, *gh.Response, error)
+	GetOrgMembership(ctx context.Context, user, org string) (*gh.Membership, *gh.Response, error)
+	IsMember(ctx context.Context, org, user string) (bool, *gh.Response, error)
+	ListMembers(ctx context.Context, org string, opts *gh.ListMembersOptions) ([]*gh.User, *gh.Response, error)
+	ListPendingOrgInvitations(ctx context.Context, org string, opts *gh.ListOptions) ([]*gh.Invitation, *gh.Response, error)
+	RemoveMember(ctx context.Context, org, user string) (*gh.Response, error)
+}
+
+// pullRequestsService are the operations of the pull requests REST API the
+// Manager uses.
+type pullRequestsService interface {
+	Get(ctx context.Context, owner string, repo string, number int) (*gh.PullRequest, *gh.Response, error)
+	List(ctx context.Context, owner string, repo string, opts *gh.PullRequestListOptions) ([]*gh.PullRequest, *gh.Response, error)
+	ListFiles(ctx context.Context, owner string, repo string, number int, opts *gh.ListOptions) ([]*gh.CommitFile, *gh.Response, error)
+	Create(ctx context.Context, owner string, repo string, pull *gh.NewPullRequest) (*gh.PullRequest, *gh.Response, error)
+}
+
+// repositoriesService are the operations of the repositories REST API the
+// Manager uses.
+type repositoriesService interface {
+	AddCollaborator(ctx context.Context, owner, repo, user string, opts *gh.RepositoryAddCollaboratorOptions) (*gh.CollaboratorInvitation, *gh.Response, error)
+	CreateFile(ctx context.Context, owner, repo, path string, opts *gh.RepositoryContentFileOptions) (*gh.RepositoryContentResponse, *gh.Response, error)
+	DeleteInvitation(ctx context.Context, owner, repo string, invitationID int64) (*gh.Response, error)
+	Get(ctx context.Context, owner, repo string) (*gh.Repository, *gh.Response, error)
+	GetContents(ctx context.Context, owner, repo, path string, opts *gh.RepositoryContentGetOptions) (fileContent *gh.RepositoryContent, directoryContent []*gh.RepositoryContent, resp *gh.Response, err error)
+	ListAllTopics(ctx context.Context, owner, repo string) ([]string, *gh.Response, error)
+	ListBranches(ctx context.Context, owner string, repo string, opts *gh.BranchListOptions) ([]*gh.Branch, *gh.Response, error)
+	ListByOrg(ctx context.Context, org string, opts *gh.RepositoryListByOrgOptions) ([]*gh.Repository, *gh.Response, error)
+	ListCollaborators(ctx context.Context, owner, repo string, opts *gh.ListCollaboratorsOptions) ([]*gh.User, *gh.Response, error)
+	ListInvitations(ctx context.Context, owner, repo string, opts *gh.ListOptions) ([]*gh.RepositoryInvitation, *gh.Response, error)
+	ListTeams(ctx context.Context, owner string, repo string, opts *gh.ListOptions) ([]*gh.Team, *gh.Response, error)
+	RemoveCollaborator(ctx context.Context, owner, repo, user string) (*gh.Response, error)
+	ReplaceAllTopics(ctx context.Context, owner, repo string, topics []string) ([]string, *gh.Response, error)
+	UpdateFile(ctx context.Context, owner, repo, path string, opts *gh.RepositoryContentFileOptions) (*gh.RepositoryContentResponse, *gh.Response, error)
+	UpdateInvitation(ctx context.Context, owner, repo string, invitationID int64, permissions string) (*gh.RepositoryInvitation, *gh.Response, error)
+}
+
+// searchService are the operations of the search REST API the Manager uses.
+type searchService interface {
+	Issues(ctx context.Context, query string, opts *gh.SearchOptions) (*gh.IssuesSearchResult, *gh.Response, error)
+}
+
+// teamsService are the operations of the teams REST API the Manager uses.
+type teamsService interface {
+	AddTeamMembershipBySlug(ctx context.Context, org, slug, user string, opts *gh.TeamAddTeamMembershipOptions) (*gh.Membership, *gh.Response, error)
+	AddTeamRepoBySlug(ctx context.Context, org, slug, owner, repo string, opts *gh.TeamAddTeamRepoOptions) (*gh.Response, error)
+	DeleteTeamBySlug(ctx context.Context, org, slug string) (*gh.Response, error)
+	EditTeamBySlug(ctx context.Context, org, slug string, team gh.NewTeam, removeParent bool) (*gh.Team, *gh.Response, error)
+	GetTeamBySlug(ctx context.Context, org, slug string) (*gh.Team, *gh.Response, error)
+	IsTeamRepoBySlug(ctx context.Context, org, slug, owner, repo string) (*gh.Repository, *gh.Response, error)
+	ListTeamMembersBySlug(ctx context.Context, org, slug string, opts *gh.TeamListTeamMembersOptions) ([]*gh.User, *gh.Response, error)
+	ListTeamReposBySlug(ctx context.Context, org, slug string, opts *gh.ListOptions) ([]*gh.Repository, *gh.Response, error)
+	RemoveTeamMembershipBySlug(ctx context.Context, org, slug, user string) (*gh.Response, error)
+	RemoveTeamRepoBySlug(ctx context.Context, org, slug, owner, repo string) (*gh.Response, error)
+}
+
+// usersService are the operations of the users REST API the Manager uses.
+type usersService interface {
+	Get(ctx context.Context, user string) (*gh.User, *gh.Response, error)
+}
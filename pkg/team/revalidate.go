@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// revalidateTeamChange re-reads the current members of the given team and
+// drops the members that were already added or removed upstream since the
+// plan was computed. Role changes are kept as they are idempotent.
+func (tm *Manager) revalidateTeamChange(ctx context.Context, teamName string, change TeamChange) (TeamChange, error) {
+	current, err := tm.currentTeamMembers(ctx, teamName)
+	if err != nil {
+		return TeamChange{}, err
+	}
+
+	revalidated := TeamChange{
+		Promote: change.Promote,
+		Demote:  change.Demote,
+	}
+	for _, user := range change.Add {
+		if current.Has(user) {
+			tm.reporter.Progress("Skipping adding member %s to team %s, already a member", user, teamName)
+			continue
+		}
+		revalidated.Add = append(revalidated.Add, user)
+	}
+	for _, user := range change.Remove {
+		if !current.Has(user) {
+			tm.reporter.Progress("Skipping removing member %s from team %s, not a member anymore", user, teamName)
+			continue
+		}
+		revalidated.Remove = append(revalidated.Remove, user)
+	}
+	return revalidated, nil
+}
+
+// revalidateReviewAssignment re-reads the current review assignment of the
+// given team and returns an error if it is not the given one, i.e. if it was
+// changed upstream since the plan was computed.
+func (tm *Manager) revalidateReviewAssignment(ctx context.Context, teamName string, planned config.CodeReviewAssignment) error {
+	t, err := tm.queryTeam(ctx, tm.teamSlug(teamName), teamQueryOptions{reviewAssignment: true})
+	if err != nil {
+		return err
+	}
+	// Excluded members can't be read from GitHub.
+	planned.ExcludedMembers = nil
+	planned.InheritExclusions = false
+	current := newTeamConfig(t).CodeReviewAssignment
+	if !reflect.DeepEqual(current, planned) {
+		tm.reporter.Warn("Code review assignment of team %s changed upstream since planning:\n%+v", teamName, current)
+		return fmt.Errorf("code review assignment of team %s changed upstream", teamName)
+	}
+	return nil
+}
+
+// verifyTeamChange waits up to the verify timeout of tm for the given member
+// changes of the given team to be reflected by GitHub, as team memberships
+// are only eventually consistent. Changes that are not reflected in time are
+// reported as pending verification.
+func (tm *Manager) verifyTeamChange(ctx context.Context, teamName string, change TeamChange) {
+	if tm.verifyTimeout == 0 || (len(change.Add) == 0 && len(change.Remove) == 0) {
+		return
+	}
+
+	deadline := time.Now().Add(tm.verifyTimeout)
+	backoff := time.Second
+	for {
+		current, err := tm.currentTeamMembers(ctx, teamName)
+		if err != nil {
+			tm.reporter.Warn("Unable to verify membership changes of team %s: %s", teamName, err)
+		} else {
+			change = unreflectedTeamChange(current, change)
+			if len(change.Add) == 0 && len(change.Remove) == 0 {
+				tm.reporter.Progress("Verified membership changes of team %s", teamName)
+				return
+			}
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			break
+		}
+		if backoff < wait {
+			wait = backoff
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		if backoff < 10*time.Second {
+			backoff *= 2
+		}
+	}
+
+	// Invited users that aren't members of the organization yet only show
+	// up once they accepted the invitation.
+	tm.reporter.Warn("Membership changes of team %s pending verification after %s, adding: [%s], removing: [%s]",
+		teamName, tm.verifyTimeout, strings.Join(change.Add, ", "), strings.Join(change.Remove, ", "))
+}
+
+// unreflectedTeamChange returns the member changes that are not reflected by
+// the given current members of a team.
+func unreflectedTeamChange(current set.Set[string], change TeamChange) TeamChange {
+	var unreflected TeamChange
+	for _, user := range change.Add {
+		if !current.Has(user) {
+			unreflected.Add = append(unreflected.Add, user)
+		}
+	}
+	for _, user := range change.Remove {
+		if current.Has(user) {
+			unreflected.Remove = append(unreflected.Remove, user)
+		}
+	}
+	return unreflected
+}
+
+// currentTeamMembers returns the logins of the current members of the given
+// team.
+func (tm *Manager) currentTeamMembers(ctx context.Context, teamName string) (set.Set[string], error) {
+	members, err := tm.queryTeamMembers(ctx, tm.teamSlug(teamName), "")
+	if err != nil {
+		return nil, err
+	}
+	current := set.New[string]()
+	for _, m := range members {
+		current.Add(m.Login)
+	}
+	return current, nil
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// MemberReviewLoad is the number of pull requests of the organization a member
+// was requested to review over a period of time.
+type MemberReviewLoad struct {
+	Login string `json:"login"`
+	// Pending is the number of pull requests still requesting a review from
+	// the member.
+	Pending int `json:"pending"`
+	// Reviewed is the number of pull requests reviewed by the member.
+	Reviewed int `json:"reviewed"`
+	// Excluded is set if the member is excluded from the code review
+	// assignment of the team.
+	Excluded bool `json:"excluded,omitempty"`
+}
+
+// Total returns the number of pull requests the member was requested to
+// review, pending or reviewed.
+func (l MemberReviewLoad) Total() int {
+	return l.Pending + l.Reviewed
+}
+
+// TeamReviewLoad is the review load of the members of a team, sorted by
+// decreasing total and login.
+type TeamReviewLoad struct {
+	Team    string             `json:"team"`
+	Members []MemberReviewLoad `json:"members"`
+}
+
+// Total returns the review load of all members of the team.
+func (l TeamReviewLoad) Total() int {
+	var total int
+	for _, m := range l.Members {
+		total += m.Total()
+	}
+	return total
+}
+
+// Mean returns the mean review load of the members of the team that aren't
+// excluded from its code review assignment, 0 if there is none.
+func (l TeamReviewLoad) Mean() float64 {
+	var total, eligible int
+	for _, m := range l.Members {
+		if !m.Excluded {
+			total += m.Total()
+			eligible++
+		}
+	}
+	if eligible == 0 {
+		return 0
+	}
+	return float64(total) / float64(eligible)
+}
+
+// GetReviewLoad returns the review load of the members of the given teams of
+// cfg over the pull requests of the organization created between since and
+// until, both inclusive, using the search API. The search API only returns
+// the review requests that are still pending, so the requests fulfilled by
+// reviews are counted by the reviews of the members. Members are counted
+// across the organization, whichever team their review was requested from.
+func (tm *Manager) GetReviewLoad(ctx context.Context, cfg *config.Config, teamNames []string, since, until time.Time) ([]TeamReviewLoad, error) {
+	period := fmt.Sprintf("created:%s..%s", since.Format(config.DateFormat), until.Format(config.DateFormat))
+	counted := map[string]MemberReviewLoad{}
+	loads := make([]TeamReviewLoad, 0, len(teamNames))
+	for _, teamName := range teamNames {
+		eligible := eligibleReviewers(cfg, teamName)
+		load := TeamReviewLoad{Team: teamName}
+		for _, login := range cfg.Teams[teamName].Members {
+			m, ok := counted[login]
+			if !ok {
+				tm.reporter.Progress("Counting review requests of %s", login)
+				var err error
+				m.Login = login
+				m.Pending, err = tm.searchCount(ctx, fmt.Sprintf("type:pr org:%s review-requested:%s %s", tm.owner, login, period))
+				if err != nil {
+					return nil, fmt.Errorf("failed to search review requests of %s: %w", login, err)
+				}
+				m.Reviewed, err = tm.searchCount(ctx, fmt.Sprintf("type:pr org:%s reviewed-by:%s -author:%s %s", tm.owner, login, login, period))
+				if err != nil {
+					return nil, fmt.Errorf("failed to search reviews of %s: %w", login, err)
+				}
+				counted[login] = m
+			}
+			m.Excluded = !set.ContainsFold(eligible, login)
+			load.Members = append(load.Members, m)
+		}
+		sort.Slice(load.Members, func(i, j int) bool {
+			if load.Members[i].Total() != load.Members[j].Total() {
+				return load.Members[i].Total() > load.Members[j].Total()
+			}
+			return load.Members[i].Login < load.Members[j].Login
+		})
+		loads = append(loads, load)
+	}
+	return loads, nil
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/set"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// SecurityManagerChanges are the slugs of the teams designated as and revoked
+// from security managers of the organization.
+type SecurityManagerChanges struct {
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
+
+// Print prints the designated and revoked security manager teams.
+func (c SecurityManagerChanges) Print(w io.Writer) {
+	for _, slug := range c.Add {
+		fmt.Fprintf(w, " Designate: %s\n", slug)
+	}
+	for _, slug := range c.Remove {
+		fmt.Fprintf(w, " Revoke: %s\n", slug)
+	}
+}
+
+// ListSecurityManagerTeams returns the slugs of the teams that are security
+// managers of the organization.
+func (tm *Manager) ListSecurityManagerTeams(ctx context.Context) ([]string, error) {
+	req, err := tm.ghClient.NewRequest("GET", fmt.Sprintf("orgs/%s/security-managers", tm.owner), nil)
+	if err != nil {
+		return nil, err
+	}
+	var teams []*gh.Team
+	if _, err = tm.ghClient.Do(ctx, req, &teams); err != nil {
+		return nil, err
+	}
+	slugs := make([]string, 0, len(teams))
+	for _, t := range teams {
+		slugs = append(slugs, t.GetSlug())
+	}
+	return slugs, nil
+}
+
+// ComputeSecurityManagerChanges returns the changes turning the slugs of the
+// current security manager teams into the desired ones, sorted.
+func ComputeSecurityManagerChanges(desired, current []string) SecurityManagerChanges {
+	changes := SecurityManagerChanges{
+		Add:    set.DifferenceFold(desired, current),
+		Remove: set.DifferenceFold(current, desired),
+	}
+	sort.Strings(changes.Add)
+	sort.Strings(changes.Remove)
+	return changes
+}
+
+// syncSecurityManagers designates the teams of cfg.SecurityManagers as
+// security managers of the organization and revokes the role from the other
+// teams. It returns the number of changes submitted and failed.
+func (tm *Manager) syncSecurityManagers(ctx context.Context, cfg *config.Config, force, dryRun bool) (submitted, failed int, err error) {
+	current, err := tm.ListSecurityManagerTeams(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list security managers of organization %s: %w", tm.owner, err)
+	}
+	desired := make([]string, 0, len(cfg.SecurityManagers))
+	for _, teamName := range cfg.SecurityManagers {
+		desired = append(desired, tm.teamSlug(teamName))
+	}
+	changes := ComputeSecurityManagerChanges(desired, current)
+	if len(changes.Add) == 0 && len(changes.Remove) == 0 {
+		return 0, 0, nil
+	}
+
+	tm.reporter.Plan(PlanEvent{
+		Title:   fmt.Sprintf("Going to change the following security manager teams of organization %s", tm.owner),
+		Changes: changes,
+		Print:   changes.Print,
+	})
+	yes := force
+	if !force {
+		yes, err = terminal.AskForConfirmation("Continue?")
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if !yes {
+		return 0, 0, nil
+	}
+	if dryRun {
+		return len(changes.Add) + len(changes.Remove), 0, nil
+	}
+
+	for _, slug := range changes.Add {
+		if err := tm.setSecurityManager(ctx, slug, "PUT"); err != nil {
+			tm.reporter.Error("Unable to designate team %s as security manager: %s", slug, github.TranslateError(err))
+			failed++
+			continue
+		}
+		submitted++
+	}
+	for _, slug := range changes.Remove {
+		if err := tm.setSecurityManager(ctx, slug, "DELETE"); err != nil {
+			tm.reporter.Error("Unable to revoke security manager role of team %s: %s", slug, github.TranslateError(err))
+			failed++
+			continue
+		}
+		submitted++
+	}
+	return submitted, failed, nil
+}
+
+// setSecurityManager designates the team with the given slug as security
+// manager with the PUT method, or revokes the role with DELETE.
+func (tm *Manager) setSecurityManager(ctx context.Context, slug, method string) error {
+	req, err := tm.ghClient.NewRequest(method, fmt.Sprintf("orgs/%s/security-managers/teams/%s", tm.owner, slug), nil)
+	if err != nil {
+		return err
+	}
+	_, err = tm.ghClient.Do(ctx, req, nil)
+	return err
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// OrgSettingChange is a setting of the organization to change.
+type OrgSettingChange struct {
+	Setting string `json:"setting"`
+	Current string `json:"current"`
+	Desired string `json:"desired"`
+}
+
+// GetOrgSettings returns the current settings of the organization.
+func (tm *Manager) GetOrgSettings(ctx context.Context) (config.OrgSettings, error) {
+	org, _, err := tm.ghClient.Organizations.Get(ctx, tm.owner)
+	if err != nil {
+		return config.OrgSettings{}, err
+	}
+	return config.OrgSettings{
+		DefaultRepositoryPermission: config.BasePermission(org.GetDefaultRepoPermission()),
+	}, nil
+}
+
+// ComputeOrgSettingChanges returns the changes turning the current settings of
+// the organization into the desired ones. Unset desired settings aren't
+// changed.
+func ComputeOrgSettingChanges(desired, current config.OrgSettings) []OrgSettingChange {
+	var changes []OrgSettingChange
+	if perm := desired.DefaultRepositoryPermission; perm != "" && perm != current.DefaultRepositoryPermission {
+		changes = append(changes, OrgSettingChange{
+			Setting: "default repository permission",
+			Current: string(current.DefaultRepositoryPermission),
+			Desired: string(perm),
+		})
+	}
+	return changes
+}
+
+// PrintOrgSettingChanges prints the given changes of the settings of the
+// organization.
+func PrintOrgSettingChanges(w io.Writer, changes []OrgSettingChange) {
+	for _, c := range changes {
+		fmt.Fprintf(w, "    Changing %s from %s to %s\n", c.Setting, c.Current, c.Desired)
+	}
+}
+
+// syncOrgSettings updates the settings of the organization that differ from
+// settings. It returns the number of changes submitted and failed.
+func (tm *Manager) syncOrgSettings(ctx context.Context, settings config.OrgSettings, force, dryRun bool) (submitted, failed int, err error) {
+	current, err := tm.GetOrgSettings(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get settings of organization %s: %w", tm.owner, err)
+	}
+	changes := ComputeOrgSettingChanges(settings, current)
+	if len(changes) == 0 {
+		return 0, 0, nil
+	}
+
+	tm.reporter.Plan(PlanEvent{
+		Title:   fmt.Sprintf("Going to change the following settings of organization %s", tm.owner),
+		Changes: changes,
+		Print: func(w io.Writer) {
+			PrintOrgSettingChanges(w, changes)
+		},
+	})
+	yes := force
+	if !force {
+		yes, err = terminal.AskForConfirmation("Continue?")
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if !yes {
+		return 0, 0, nil
+	}
+	if dryRun {
+		return len(changes), 0, nil
+	}
+
+	_, _, err = tm.ghClient.Organizations.Edit(ctx, tm.owner, &gh.Organization{
+		DefaultRepoPermission: gh.String(string(settings.DefaultRepositoryPermission)),
+	})
+	if err != nil {
+		tm.reporter.Error("Unable to change settings of organization %s: %s", tm.owner, github.TranslateError(err))
+		return 0, len(changes), nil
+	}
+	return len(changes), 0, nil
+}
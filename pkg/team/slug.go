@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// transliterations maps the lower case non-ASCII letters GitHub transliterates
+// in team slugs to their ASCII counterpart.
+var transliterations = func() map[rune]string {
+	m := map[rune]string{}
+	for ascii, letters := range map[string]string{
+		"a":  "àáâãäåāăą",
+		"ae": "æ",
+		"c":  "çćĉċč",
+		"d":  "ðďđ",
+		"e":  "èéêëēĕėęě",
+		"g":  "ĝğġģ",
+		"h":  "ĥħ",
+		"i":  "ìíîïĩīĭįı",
+		"ij": "ĳ",
+		"j":  "ĵ",
+		"k":  "ķĸ",
+		"l":  "ĺļľŀł",
+		"n":  "ñńņňŉŋ",
+		"o":  "òóôõöøōŏő",
+		"oe": "œ",
+		"r":  "ŕŗř",
+		"s":  "śŝşšſ",
+		"ss": "ß",
+		"t":  "ţťŧ",
+		"th": "þ",
+		"u":  "ùúûüũūŭůűų",
+		"w":  "ŵ",
+		"y":  "ýÿŷ",
+		"z":  "źżž",
+	} {
+		for _, r := range letters {
+			m[r] = ascii
+		}
+	}
+	return m
+}()
+
+var nonSlugRegex = regexp.MustCompile("[^a-z0-9]+")
+
+// Slug returns the slug version of the team name, following GitHub's slug
+// transformation: accented Latin letters are transliterated to ASCII, e.g.
+// 'ä' to 'a' and 'ø' to 'o', and all remaining characters that are not in
+// `[a-z0-9]`, such as spaces or emoji, are replaced by a single `-`. Names
+// without any such letter or digit, e.g. written in CJK characters only, get a
+// slug derived from their hash instead of an empty one, as the slug GitHub
+// generates for them can't be predicted. Prefer TeamSlug, which uses the slug
+// retrieved from GitHub.
+func Slug(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if ascii, ok := transliterations[r]; ok {
+			b.WriteString(ascii)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	slug := strings.Trim(nonSlugRegex.ReplaceAllString(b.String(), "-"), "-")
+	if slug == "" && s != "" {
+		sum := sha256.Sum256([]byte(s))
+		slug = "team-" + hex.EncodeToString(sum[:4])
+	}
+	return slug
+}
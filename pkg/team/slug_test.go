@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlug(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want string
+	}{
+		{name: "", want: ""},
+		{name: "sig-datapath", want: "sig-datapath"},
+		{name: "SIG Datapath", want: "sig-datapath"},
+		{name: "  Docs & Website  ", want: "docs-website"},
+		{name: "ci_structure", want: "ci-structure"},
+		{name: "Café", want: "cafe"},
+		{name: "Équipe Réseau", want: "equipe-reseau"},
+		{name: "Łódź", want: "lodz"},
+		{name: "Ærø", want: "aero"},
+		{name: "Straße", want: "strasse"},
+		{name: "Œuvre", want: "oeuvre"},
+		{name: "🚀 Rockets", want: "rockets"},
+		{name: "Rockets 🚀 Launch", want: "rockets-launch"},
+		{name: "datapath 数据路径", want: "datapath"},
+		{name: "v1.15 Release", want: "v1-15-release"},
+	} {
+		if got := Slug(tt.name); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSlugWithoutLatinLetters(t *testing.T) {
+	for _, name := range []string{"数据路径", "ネットワーク", "보안", "🚀"} {
+		slug := Slug(name)
+		if !strings.HasPrefix(slug, "team-") {
+			t.Errorf("Slug(%q) = %q, want a team- fallback", name, slug)
+		}
+		if again := Slug(name); again != slug {
+			t.Errorf("Slug(%q) is not stable: %q then %q", name, slug, again)
+		}
+	}
+	if Slug("数据路径") == Slug("网络") {
+		t.Errorf("Slug returns the same fallback for different names")
+	}
+}
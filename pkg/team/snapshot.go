@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// GetSnapshot returns a snapshot of the current config of the organization.
+// If a previous snapshot is given, only the members of the teams that were
+// updated since that snapshot are queried, the members of all other teams are
+// copied over from the previous snapshot.
+func (tm *Manager) GetSnapshot(ctx context.Context, prev *config.Snapshot) (*config.Snapshot, error) {
+	now := time.Now().UTC()
+	if prev == nil || prev.Config == nil || prev.Config.Organization != tm.owner {
+		c, teamsUpdatedAt, err := tm.fetchConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &config.Snapshot{
+			CreatedAt:      now,
+			TeamsUpdatedAt: teamsUpdatedAt,
+			Config:         c,
+		}, nil
+	}
+
+	prevTeamNames := make(map[string]string, len(prev.Config.Teams))
+	for teamName, teamCfg := range prev.Config.Teams {
+		prevTeamNames[teamCfg.ID] = teamName
+	}
+
+	c := &config.Config{
+		Organization: tm.owner,
+		Teams:        map[string]config.TeamConfig{},
+		Members:      map[string]config.User{},
+	}
+	teamsUpdatedAt := map[string]time.Time{}
+
+	teams, err := tm.queryTeams(ctx, teamQueryOptions{reviewAssignment: true, hierarchy: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+
+	var refetched int
+	for _, t := range teams {
+		teamCfg := newTeamConfig(t)
+		teamsUpdatedAt[teamCfg.ID] = t.UpdatedAt
+
+		prevTeamName, ok := prevTeamNames[teamCfg.ID]
+		if ok && prev.TeamsUpdatedAt[teamCfg.ID].Equal(t.UpdatedAt) {
+			teamCfg.Members = prev.Config.Teams[prevTeamName].Members
+			teamCfg.Maintainers = prev.Config.Teams[prevTeamName].Maintainers
+			for _, member := range teamCfg.Members {
+				c.Members[member] = prev.Config.Members[member]
+			}
+		} else {
+			members, err := tm.queryTeamMembers(ctx, t.Slug, "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to query members of team %q: %w", t.Name, err)
+			}
+			for _, member := range members {
+				teamCfg.Members = append(teamCfg.Members, member.Login)
+				if member.IsMaintainer() {
+					teamCfg.Maintainers = append(teamCfg.Maintainers, member.Login)
+				}
+				c.Members[member.Login] = config.User{
+					ID:   member.ID,
+					Name: member.Name,
+				}
+			}
+			sort.Strings(teamCfg.Members)
+			sort.Strings(teamCfg.Maintainers)
+			refetched++
+		}
+		c.Teams[t.Name] = teamCfg
+	}
+	tm.reporter.Progress("Fetched members of %d out of %d teams", refetched, len(c.Teams))
+	if err := tm.fetchMetadata(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to query custom fields: %w", err)
+	}
+
+	return &config.Snapshot{
+		CreatedAt:      now,
+		TeamsUpdatedAt: teamsUpdatedAt,
+		Config:         c,
+	}, nil
+}
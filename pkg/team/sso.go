@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+
+	gh "github.com/google/go-github/v33/github"
+)
+
+const (
+	// MetadataSSONameID is the Metadata key of the SAML name ID of a
+	// member.
+	MetadataSSONameID = "ssoNameId"
+	// MetadataSSOEmail is the Metadata key of the SAML email of a member.
+	MetadataSSOEmail = "ssoEmail"
+)
+
+// SSOIdentity is the SAML single sign-on identity linked to a GitHub account.
+type SSOIdentity struct {
+	Login  string
+	NameID string
+	Email  string
+}
+
+type externalIdentity struct {
+	SAMLIdentity struct {
+		NameID string `json:"nameId"`
+		Emails []struct {
+			Value string
+		}
+	} `json:"samlIdentity"`
+	User *struct {
+		Login string
+	}
+}
+
+// ListSSOIdentities returns the SAML single sign-on identities linked to the
+// GitHub accounts of the organization members.
+//
+//	{
+//	 organization(login: "cilium") {
+//	   samlIdentityProvider {
+//	     externalIdentities(first: 100) {
+//	       nodes {
+//	         samlIdentity { nameId emails { value } }
+//	         user { login }
+//	       }
+//	     }
+//	   }
+//	 }
+//	}
+func (tm *Manager) ListSSOIdentities(ctx context.Context) ([]SSOIdentity, error) {
+	query := github.BuildQuery(
+		map[string]string{"owner": "String!", "cursor": "String"},
+		github.NewField("organization",
+			github.NewField("samlIdentityProvider",
+				github.Connection("externalIdentities", "first: 100, after: $cursor",
+					github.NewField("samlIdentity", github.NewField("nameId"), github.NewField("emails", github.NewField("value"))),
+					github.NewField("user", github.NewField("login")),
+				),
+			),
+		).WithArgs("login: $owner"),
+	)
+
+	var identities []SSOIdentity
+	variables := map[string]interface{}{
+		"owner":  tm.owner,
+		"cursor": nil,
+	}
+	for {
+		var q struct {
+			Organization struct {
+				SAMLIdentityProvider *struct {
+					ExternalIdentities github.ConnectionResult[externalIdentity]
+				} `json:"samlIdentityProvider"`
+			}
+		}
+		if err := tm.gqlGHClient.QueryRaw(ctx, query, variables, &q); err != nil {
+			return nil, err
+		}
+		idp := q.Organization.SAMLIdentityProvider
+		if idp == nil {
+			return nil, errors.New("organization has no SAML identity provider")
+		}
+		for _, n := range idp.ExternalIdentities.Nodes {
+			// Identities that aren't linked to an account yet have no
+			// user.
+			if n.User == nil {
+				continue
+			}
+			identity := SSOIdentity{
+				Login:  n.User.Login,
+				NameID: n.SAMLIdentity.NameID,
+			}
+			if len(n.SAMLIdentity.Emails) != 0 {
+				identity.Email = n.SAMLIdentity.Emails[0].Value
+			}
+			identities = append(identities, identity)
+		}
+		if !idp.ExternalIdentities.PageInfo.HasNextPage {
+			return identities, nil
+		}
+		variables["cursor"] = idp.ExternalIdentities.PageInfo.EndCursor
+	}
+}
+
+// SetSSOIdentities stores the given identities into the Metadata of the
+// members of cfg and returns the logins of the members without an identity,
+// whose SSO Metadata is removed.
+func SetSSOIdentities(cfg *config.Config, identities []SSOIdentity) []string {
+	byLogin := make(map[string]SSOIdentity, len(identities))
+	for _, identity := range identities {
+		byLogin[identity.Login] = identity
+	}
+
+	var unlinked []string
+	for _, login := range sortedKeys(cfg.Members) {
+		user := cfg.Members[login]
+		identity, ok := byLogin[login]
+		if !ok {
+			unlinked = append(unlinked, login)
+			delete(user.Metadata, MetadataSSONameID)
+			delete(user.Metadata, MetadataSSOEmail)
+			if len(user.Metadata) == 0 {
+				user.Metadata = nil
+			}
+			cfg.Members[login] = user
+			continue
+		}
+		if user.Metadata == nil {
+			user.Metadata = map[string]string{}
+		}
+		user.Metadata[MetadataSSONameID] = identity.NameID
+		if identity.Email != "" {
+			user.Metadata[MetadataSSOEmail] = identity.Email
+		} else {
+			delete(user.Metadata, MetadataSSOEmail)
+		}
+		cfg.Members[login] = user
+	}
+	return unlinked
+}
+
+// CredentialAuthorization is a credential of a member authorized for SAML
+// single sign-on.
+type CredentialAuthorization struct {
+	Login          string    `json:"login"`
+	CredentialType string    `json:"credential_type"`
+	TokenLastEight string    `json:"token_last_eight"`
+	AuthorizedAt   time.Time `json:"credential_authorized_at"`
+}
+
+// ListCredentialAuthorizations returns the credentials, such as personal
+// access tokens and SSH keys, of the organization members authorized for
+// SAML single sign-on.
+func (tm *Manager) ListCredentialAuthorizations(ctx context.Context) ([]CredentialAuthorization, error) {
+	var auths []CredentialAuthorization
+	opts := &gh.ListOptions{PerPage: 100, Page: 1}
+	for {
+		u := fmt.Sprintf("orgs/%s/credential-authorizations?per_page=%d&page=%d", tm.owner, opts.PerPage, opts.Page)
+		req, err := tm.ghClient.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []CredentialAuthorization
+		resp, err := tm.ghClient.Do(ctx, req, &page)
+		if err != nil {
+			return nil, err
+		}
+		auths = append(auths, page...)
+		if resp.NextPage == 0 {
+			return auths, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// UnauthorizedBots returns the logins of the bots of cfg without any
+// credential authorized for SAML single sign-on. Their automation can't
+// access the organization's resources.
+func UnauthorizedBots(cfg *config.Config, auths []CredentialAuthorization) []string {
+	authorized := map[string]struct{}{}
+	for _, auth := range auths {
+		authorized[strings.ToLower(auth.Login)] = struct{}{}
+	}
+
+	var unauthorized []string
+	for _, login := range sortedKeys(cfg.Members) {
+		if !cfg.Members[login].Bot {
+			continue
+		}
+		if _, ok := authorized[strings.ToLower(login)]; !ok {
+			unauthorized = append(unauthorized, login)
+		}
+	}
+	return unauthorized
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// TeamRepositoryChange is a repository a team is granted access to, whose
+// access is revoked or whose permission is changed. Perm is empty for
+// revocations.
+type TeamRepositoryChange struct {
+	Team string                      `json:"team"`
+	Repo string                      `json:"repository"`
+	Perm config.RepositoryPermission `json:"permission,omitempty"`
+	// Current is the current permission of the team, empty for grants.
+	Current config.RepositoryPermission `json:"current,omitempty"`
+}
+
+// PrintTeamRepositoryChanges prints the given changes of team repository
+// access.
+func PrintTeamRepositoryChanges(w io.Writer, changes []TeamRepositoryChange) {
+	team := ""
+	for _, c := range changes {
+		if c.Team != team {
+			team = c.Team
+			fmt.Fprintf(w, " Team: %s\n", team)
+		}
+		switch {
+		case c.Current == "":
+			fmt.Fprintf(w, "    Granting %s permission on %s\n", c.Perm, c.Repo)
+		case c.Perm == "":
+			fmt.Fprintf(w, "    Revoking %s permission on %s\n", c.Current, c.Repo)
+		default:
+			fmt.Fprintf(w, "    Changing permission on %s from %s to %s\n", c.Repo, c.Current, c.Perm)
+		}
+	}
+}
+
+// ListTeamRepositories returns the repositories of the organization the team
+// with the given slug has access to, mapped to its permission on them.
+func (tm *Manager) ListTeamRepositories(ctx context.Context, slug string) (map[string]config.RepositoryPermission, error) {
+	repos := map[string]config.RepositoryPermission{}
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := tm.ghClient.Teams.ListTeamReposBySlug(ctx, tm.owner, slug, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range page {
+			if !strings.EqualFold(repo.GetOwner().GetLogin(), tm.owner) {
+				continue
+			}
+			repos[repo.GetName()] = highestPermission(repo.GetPermissions())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return repos, nil
+}
+
+// ComputeTeamRepositoryChanges returns the changes turning the current
+// repository access of a team into the desired one, sorted by repository.
+func ComputeTeamRepositoryChanges(teamName string, desired, current map[string]config.RepositoryPermission) []TeamRepositoryChange {
+	lowerCurrent := map[string]string{}
+	for repo := range current {
+		lowerCurrent[strings.ToLower(repo)] = repo
+	}
+	var changes []TeamRepositoryChange
+	for repo, perm := range desired {
+		currentRepo, ok := lowerCurrent[strings.ToLower(repo)]
+		if !ok {
+			changes = append(changes, TeamRepositoryChange{Team: teamName, Repo: repo, Perm: perm})
+			continue
+		}
+		delete(lowerCurrent, strings.ToLower(repo))
+		if current[currentRepo] != perm {
+			changes = append(changes, TeamRepositoryChange{Team: teamName, Repo: repo, Perm: perm, Current: current[currentRepo]})
+		}
+	}
+	for _, repo := range lowerCurrent {
+		changes = append(changes, TeamRepositoryChange{Team: teamName, Repo: repo, Current: current[repo]})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return strings.ToLower(changes[i].Repo) < strings.ToLower(changes[j].Repo)
+	})
+	return changes
+}
+
+// syncTeamRepositories grants, updates and revokes the repository access of
+// the teams of cfg that have repositories so that it matches cfg. It returns
+// the number of changes submitted and failed.
+func (tm *Manager) syncTeamRepositories(ctx context.Context, cfg *config.Config, force, dryRun bool) (submitted, failed int, err error) {
+	var changes []TeamRepositoryChange
+	for _, teamName := range sortedKeys(cfg.Teams) {
+		teamCfg := cfg.Teams[teamName]
+		if len(teamCfg.Repositories) == 0 {
+			continue
+		}
+		current := map[string]config.RepositoryPermission{}
+		// Teams without ID weren't created in GitHub yet.
+		if teamCfg.ID != "" {
+			current, err = tm.ListTeamRepositories(ctx, TeamSlug(cfg, teamName))
+			if err != nil {
+				return 0, 0, fmt.Errorf("failed to list repositories of team %s: %w", teamName, err)
+			}
+		}
+		changes = append(changes, ComputeTeamRepositoryChanges(teamName, teamCfg.Repositories, current)...)
+	}
+	if len(changes) == 0 {
+		return 0, 0, nil
+	}
+
+	tm.reporter.Plan(PlanEvent{
+		Title:   "Going to update the following team repository permissions",
+		Changes: changes,
+		Print: func(w io.Writer) {
+			PrintTeamRepositoryChanges(w, changes)
+		},
+	})
+	yes := force
+	if !force {
+		yes, err = terminal.AskForConfirmation("Continue?")
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if !yes {
+		return 0, 0, nil
+	}
+
+	for _, c := range changes {
+		if dryRun {
+			submitted++
+			continue
+		}
+		slug := TeamSlug(cfg, c.Team)
+		if c.Perm == "" {
+			_, err = tm.ghClient.Teams.RemoveTeamRepoBySlug(ctx, tm.owner, slug, tm.owner, c.Repo)
+		} else {
+			// Adding a repository the team has access to updates its
+			// permission.
+			_, err = tm.ghClient.Teams.AddTeamRepoBySlug(ctx, tm.owner, slug, tm.owner, c.Repo,
+				&gh.TeamAddTeamRepoOptions{Permission: string(c.Perm)})
+		}
+		if err != nil {
+			tm.reporter.Error("Unable to update permission of team %s on repository %s: %s", c.Team, c.Repo, github.TranslateError(err))
+			failed++
+			continue
+		}
+		submitted++
+	}
+	return submitted, failed, nil
+}
@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/github"
+	"github.com/cilium/team-manager/pkg/set"
+	"github.com/cilium/team-manager/pkg/terminal"
+)
+
+// TopicIssue is an inconsistency between the topics of a repository and the
+// teams that have access to it.
+type TopicIssue struct {
+	Repository string
+	// Team is the slug of the team.
+	Team    string
+	Problem string
+	// Fix is the change that resolves the issue, empty if it can't be
+	// resolved automatically.
+	Fix TopicFix
+}
+
+// TopicFix is the change resolving a TopicIssue.
+type TopicFix string
+
+const (
+	// TopicFixNone means that the issue needs to be resolved manually.
+	TopicFixNone TopicFix = ""
+	// TopicFixGrantAccess grants the team access to the repository.
+	TopicFixGrantAccess TopicFix = "grant access"
+	// TopicFixAddTopic adds the topic of the team to the repository.
+	TopicFixAddTopic TopicFix = "add topic"
+)
+
+func (i TopicIssue) String() string {
+	if i.Fix == TopicFixNone {
+		return fmt.Sprintf("%s: team %q %s", i.Repository, i.Team, i.Problem)
+	}
+	return fmt.Sprintf("%s: team %q %s (fix: %s)", i.Repository, i.Team, i.Problem, i.Fix)
+}
+
+// CheckRepositoryTopics checks that every repository tagged with the topic of
+// a team, i.e. topicPrefix followed by the team slug, grants that team access
+// and that every repository the teams of the local config have access to is
+// tagged with their topic.
+func (tm *Manager) CheckRepositoryTopics(ctx context.Context, cfg *config.Config, topicPrefix string) ([]TopicIssue, error) {
+	managed := set.New[string]()
+	for teamName := range cfg.Teams {
+		managed.Add(TeamSlug(cfg, teamName))
+	}
+
+	repos, err := tm.listActiveRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	var issues []TopicIssue
+	for _, repo := range repos {
+		teams, err := tm.listRepositoryTeams(ctx, repo.GetName())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list teams of repository %q: %w", repo.GetName(), err)
+		}
+		tagged := set.New[string]()
+		for _, topic := range repo.Topics {
+			if strings.HasPrefix(topic, topicPrefix) {
+				tagged.Add(strings.TrimPrefix(topic, topicPrefix))
+			}
+		}
+
+		for _, teamSlug := range tagged.Elements() {
+			issue := TopicIssue{Repository: repo.GetName(), Team: teamSlug}
+			switch {
+			case !managed.Has(teamSlug):
+				issue.Problem = fmt.Sprintf("referenced by topic %q does not exist in the local config", topicPrefix+teamSlug)
+			case !teams.Has(teamSlug):
+				issue.Problem = fmt.Sprintf("has no access but the repository is tagged with %q", topicPrefix+teamSlug)
+				issue.Fix = TopicFixGrantAccess
+			default:
+				continue
+			}
+			issues = append(issues, issue)
+		}
+		for _, teamSlug := range teams.Elements() {
+			if !managed.Has(teamSlug) || tagged.Has(teamSlug) {
+				continue
+			}
+			issues = append(issues, TopicIssue{
+				Repository: repo.GetName(),
+				Team:       teamSlug,
+				Problem:    fmt.Sprintf("has access but the repository is not tagged with %q", topicPrefix+teamSlug),
+				Fix:        TopicFixAddTopic,
+			})
+		}
+	}
+	return issues, nil
+}
+
+// listRepositoryTeams returns the slugs of all teams that have access to the
+// given repository.
+func (tm *Manager) listRepositoryTeams(ctx context.Context, repo string) (set.Set[string], error) {
+	opts := &gh.ListOptions{PerPage: 100}
+	teams := set.New[string]()
+	for {
+		page, resp, err := tm.ghClient.Repositories.ListTeams(ctx, tm.owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page {
+			teams.Add(t.GetSlug())
+		}
+		if resp.NextPage == 0 {
+			return teams, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// FixRepositoryTopics resolves the given issues by granting perm to the teams
+// missing access to the repositories tagged with their topic, and by tagging
+// the repositories with the topics of the teams that have access to them.
+func (tm *Manager) FixRepositoryTopics(ctx context.Context, issues []TopicIssue, topicPrefix string, perm config.RepositoryPermission, force bool) error {
+	var fixes []TopicIssue
+	for _, issue := range issues {
+		if issue.Fix != TopicFixNone {
+			fixes = append(fixes, issue)
+		}
+	}
+	if len(fixes) == 0 {
+		tm.reporter.Progress("No issue can be fixed automatically")
+		return nil
+	}
+	sort.Slice(fixes, func(i, j int) bool {
+		if fixes[i].Repository != fixes[j].Repository {
+			return fixes[i].Repository < fixes[j].Repository
+		}
+		return fixes[i].Team < fixes[j].Team
+	})
+
+	tm.reporter.Plan(PlanEvent{
+		Title:   "Going to submit the following changes",
+		Changes: fixes,
+		Print: func(w io.Writer) {
+			for _, fix := range fixes {
+				fmt.Fprintf(w, " Repository: %s\n", fix.Repository)
+				switch fix.Fix {
+				case TopicFixGrantAccess:
+					fmt.Fprintf(w, "    Granting %s permission to team %s\n", perm, fix.Team)
+				case TopicFixAddTopic:
+					fmt.Fprintf(w, "    Adding topic %s\n", topicPrefix+fix.Team)
+				}
+			}
+		},
+	})
+	yes := force
+	if !force {
+		var err error
+		yes, err = terminal.AskForConfirmation("Continue?")
+		if err != nil {
+			return err
+		}
+	}
+	if !yes {
+		return nil
+	}
+
+	for _, fix := range fixes {
+		switch fix.Fix {
+		case TopicFixGrantAccess:
+			opts := &gh.TeamAddTeamRepoOptions{Permission: string(perm)}
+			if _, err := tm.ghClient.Teams.AddTeamRepoBySlug(ctx, tm.owner, fix.Team, tm.owner, fix.Repository, opts); err != nil {
+				tm.reporter.Error("Unable to grant %s permission on %s to team %s: %s", perm, fix.Repository, fix.Team, github.TranslateError(err))
+			}
+		case TopicFixAddTopic:
+			// Topics are re-read for every fix since ReplaceAllTopics
+			// overwrites all topics of the repository.
+			topics, _, err := tm.ghClient.Repositories.ListAllTopics(ctx, tm.owner, fix.Repository)
+			if err == nil {
+				_, _, err = tm.ghClient.Repositories.ReplaceAllTopics(ctx, tm.owner, fix.Repository, append(topics, topicPrefix+fix.Team))
+			}
+			if err != nil {
+				tm.reporter.Error("Unable to add topic %s to %s: %s", topicPrefix+fix.Team, fix.Repository, github.TranslateError(err))
+			}
+		}
+	}
+	return nil
+}
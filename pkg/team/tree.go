@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// TeamState tells whether a team is managed by the local configuration.
+type TeamState string
+
+const (
+	// TeamManaged teams exist in both the local configuration and GitHub.
+	TeamManaged TeamState = "managed"
+	// TeamUnmanaged teams only exist in GitHub.
+	TeamUnmanaged TeamState = "unmanaged"
+	// TeamMissing teams only exist in the local configuration, they are
+	// created by the next push.
+	TeamMissing TeamState = "missing"
+)
+
+// TeamNode is a team of the team hierarchy.
+type TeamNode struct {
+	Name     string      `json:"name"`
+	State    TeamState   `json:"state"`
+	Members  int         `json:"members"`
+	Children []*TeamNode `json:"children,omitempty"`
+}
+
+// BuildTeamTree returns the root teams of the hierarchy of the teams of
+// localCfg and upstreamCfg, sorted by name. The parent teams and members of
+// the teams that exist in GitHub are taken from upstreamCfg.
+func BuildTeamTree(localCfg, upstreamCfg *config.Config) []*TeamNode {
+	nodes := map[string]*TeamNode{}
+	parents := map[string]string{}
+	for teamName, teamCfg := range upstreamCfg.Teams {
+		state := TeamUnmanaged
+		if _, ok := localCfg.Teams[teamName]; ok {
+			state = TeamManaged
+		}
+		nodes[teamName] = &TeamNode{Name: teamName, State: state, Members: len(teamCfg.Members)}
+		parents[teamName] = teamCfg.Parent
+	}
+	for teamName, teamCfg := range localCfg.Teams {
+		if _, ok := nodes[teamName]; ok {
+			continue
+		}
+		nodes[teamName] = &TeamNode{Name: teamName, State: TeamMissing, Members: len(teamCfg.Members)}
+		parents[teamName] = teamCfg.Parent
+	}
+
+	var roots []*TeamNode
+	for _, teamName := range sortedKeys(nodes) {
+		node := nodes[teamName]
+		parent, ok := nodes[parents[teamName]]
+		if !ok || isAncestor(parents, teamName, parents[teamName]) {
+			// Teams whose parent is unknown or that are part of a cycle
+			// are shown as roots.
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+	sortTeamNodes(roots)
+	return roots
+}
+
+// isAncestor returns true if teamName is an ancestor of name, or name
+// itself, according to parents.
+func isAncestor(parents map[string]string, teamName, name string) bool {
+	visited := map[string]bool{}
+	for ; name != "" && !visited[name]; name = parents[name] {
+		if name == teamName {
+			return true
+		}
+		visited[name] = true
+	}
+	return false
+}
+
+func sortTeamNodes(nodes []*TeamNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	for _, node := range nodes {
+		sortTeamNodes(node.Children)
+	}
+}
+
+// PrintTeamTree prints the given team hierarchy as an ASCII tree.
+func PrintTeamTree(w io.Writer, roots []*TeamNode) {
+	for _, root := range roots {
+		fmt.Fprintln(w, root)
+		printTeamNodes(w, root.Children, "")
+	}
+}
+
+func printTeamNodes(w io.Writer, nodes []*TeamNode, indent string) {
+	for i, node := range nodes {
+		branch, childIndent := "|-- ", "|   "
+		if i == len(nodes)-1 {
+			branch, childIndent = "`-- ", "    "
+		}
+		fmt.Fprintf(w, "%s%s%s\n", indent, branch, node)
+		printTeamNodes(w, node.Children, indent+childIndent)
+	}
+}
+
+func (n *TeamNode) String() string {
+	members := "members"
+	if n.Members == 1 {
+		members = "member"
+	}
+	return fmt.Sprintf("%s (%s, %d %s)", n.Name, n.State, n.Members, members)
+}
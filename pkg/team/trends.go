@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"time"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// AllTeams is the Team of the TrendPoints of the whole organization.
+const AllTeams = "(all teams)"
+
+// TrendPoint contains the statistics of a team, or of the whole organization
+// for AllTeams, at the time a snapshot was taken.
+type TrendPoint struct {
+	Date time.Time `json:"date"`
+	Team string    `json:"team"`
+	// Members is the number of members of the team.
+	Members int `json:"members"`
+	// Added and Removed are the number of members added to and removed
+	// from the team since the previous snapshot.
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	// Churn is the number of added and removed members relative to the
+	// number of members at the time of the previous snapshot.
+	Churn float64 `json:"churn"`
+	// Exclusions is the number of members excluded from the code review
+	// assignment of the team in the local configuration at that time, -1
+	// if unknown.
+	Exclusions int `json:"exclusions"`
+}
+
+// ComputeTrends returns the statistics of every team, and of the whole
+// organization, at the time of every given snapshot, sorted by date and team.
+// localCfgs are the local configurations at the time of the snapshots, used
+// to count the members excluded from code review assignments as they can't
+// be retrieved from GitHub. They may be nil if unknown.
+func ComputeTrends(snapshots []*config.Snapshot, localCfgs []*config.Config) []TrendPoint {
+	var points []TrendPoint
+	prevMembers := map[string][]string{}
+	for i, snapshot := range snapshots {
+		var localCfg *config.Config
+		if i < len(localCfgs) {
+			localCfg = localCfgs[i]
+		}
+
+		members := map[string][]string{}
+		allMembers := set.New[string]()
+		allExclusions := -1
+		if localCfg != nil {
+			allExclusions = 0
+		}
+		for _, teamName := range sortedKeys(snapshot.Config.Teams) {
+			teamMembers := snapshot.Config.Teams[teamName].Members
+			members[teamName] = teamMembers
+			allMembers.Add(teamMembers...)
+
+			exclusions := countExclusions(localCfg, teamName)
+			if exclusions > 0 {
+				allExclusions += exclusions
+			}
+			points = append(points, newTrendPoint(snapshot.CreatedAt, teamName, teamMembers, prevMembers[teamName], i == 0, exclusions))
+		}
+		members[AllTeams] = allMembers.Elements()
+		points = append(points, newTrendPoint(snapshot.CreatedAt, AllTeams, members[AllTeams], prevMembers[AllTeams], i == 0, allExclusions))
+		prevMembers = members
+	}
+	return points
+}
+
+func newTrendPoint(date time.Time, teamName string, members, prevMembers []string, first bool, exclusions int) TrendPoint {
+	p := TrendPoint{
+		Date:       date,
+		Team:       teamName,
+		Members:    len(members),
+		Exclusions: exclusions,
+	}
+	if first {
+		return p
+	}
+	p.Added = len(set.DifferenceFold(members, prevMembers))
+	p.Removed = len(set.DifferenceFold(prevMembers, members))
+	if len(prevMembers) != 0 {
+		p.Churn = float64(p.Added+p.Removed) / float64(len(prevMembers))
+	}
+	return p
+}
+
+// countExclusions returns the number of members of the given team of cfg
+// excluded from its code review assignment, -1 if cfg is nil or doesn't
+// contain the team.
+func countExclusions(cfg *config.Config, teamName string) int {
+	if cfg == nil {
+		return -1
+	}
+	teamCfg, ok := cfg.Teams[teamName]
+	if !ok {
+		return -1
+	}
+	teamMembers := set.New(teamCfg.Members...)
+	excluded := set.New[string]()
+	for _, xMember := range cfg.ExcludedMembers(teamName) {
+		excluded.Add(xMember.Login)
+	}
+	for _, login := range cfg.ExcludeCRAFromAllTeams {
+		if teamMembers.Has(login) {
+			excluded.Add(login)
+		}
+	}
+	return len(excluded)
+}
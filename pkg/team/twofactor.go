@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+)
+
+// ListMembersWithout2FA returns the logins of the members of the organization
+// without two-factor authentication enabled, sorted. Only owners of the
+// organization are allowed to list them.
+func (tm *Manager) ListMembersWithout2FA(ctx context.Context) ([]string, error) {
+	var logins []string
+	opts := &gh.ListMembersOptions{Filter: "2fa_disabled", ListOptions: gh.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := tm.ghClient.Organizations.ListMembers(ctx, tm.owner, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range page {
+			logins = append(logins, u.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	sort.Slice(logins, func(i, j int) bool {
+		return strings.ToLower(logins[i]) < strings.ToLower(logins[j])
+	})
+	return logins, nil
+}
+
+// TwoFactorAudit are the members of the organization without two-factor
+// authentication enabled.
+type TwoFactorAudit struct {
+	// Members are the logins of all members without two-factor
+	// authentication, including the ones that aren't members of any team.
+	Members []string `json:"members"`
+	// Teams maps the teams of the configuration to their members without
+	// two-factor authentication, teams without any are omitted.
+	Teams map[string][]string `json:"teams"`
+}
+
+// AuditTwoFactor cross-references the given members of the organization
+// without two-factor authentication with the teams of cfg.
+func AuditTwoFactor(cfg *config.Config, without2FA []string) TwoFactorAudit {
+	audit := TwoFactorAudit{
+		Members: without2FA,
+		Teams:   map[string][]string{},
+	}
+	disabled := map[string]struct{}{}
+	for _, login := range without2FA {
+		disabled[strings.ToLower(login)] = struct{}{}
+	}
+	for _, teamName := range sortedKeys(cfg.Teams) {
+		for _, login := range cfg.Teams[teamName].Members {
+			if _, ok := disabled[strings.ToLower(login)]; ok {
+				audit.Teams[teamName] = append(audit.Teams[teamName], login)
+			}
+		}
+	}
+	return audit
+}
+
+// TeamlessMembers returns the members without two-factor authentication that
+// aren't members of any team of the audit.
+func (a TwoFactorAudit) TeamlessMembers() []string {
+	inTeams := map[string]struct{}{}
+	for _, logins := range a.Teams {
+		for _, login := range logins {
+			inTeams[strings.ToLower(login)] = struct{}{}
+		}
+	}
+	var teamless []string
+	for _, login := range a.Members {
+		if _, ok := inTeams[strings.ToLower(login)]; !ok {
+			teamless = append(teamless, login)
+		}
+	}
+	return teamless
+}
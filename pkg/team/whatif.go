@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"fmt"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// TeamImpact is the effect on a team of removing members from the
+// configuration.
+type TeamImpact struct {
+	Team string `json:"team"`
+	// Removed are the removed members that were members of the team.
+	Removed []string `json:"removed,omitempty"`
+	Members int      `json:"members"`
+	// MembersAfter is the number of members of the team after the removal.
+	MembersAfter int `json:"membersAfter"`
+	// Eligible is the number of members of the team eligible for its code
+	// review assignment, 0 if the code review assignment is disabled.
+	Eligible int `json:"eligible"`
+	// EligibleAfter is the number of eligible members after the removal.
+	EligibleAfter int `json:"eligibleAfter"`
+	// Violations are the policy violations caused by the removal.
+	Violations []string `json:"violations,omitempty"`
+}
+
+// Affected returns true if any of the removed members was a member of the
+// team.
+func (i TeamImpact) Affected() bool {
+	return len(i.Removed) != 0
+}
+
+// RemovalImpact is the effect of removing members from the configuration.
+type RemovalImpact struct {
+	Teams []TeamImpact `json:"teams"`
+	// Violations are the policy violations of the organization caused by
+	// the removal, e.g. removing its last owner.
+	Violations []string `json:"violations,omitempty"`
+}
+
+// ViolationCount returns the number of policy violations caused by the
+// removal.
+func (r RemovalImpact) ViolationCount() int {
+	n := len(r.Violations)
+	for _, i := range r.Teams {
+		n += len(i.Violations)
+	}
+	return n
+}
+
+// SimulateRemoval returns the effect on every team of cfg, sorted by name, of
+// removing the given logins from the configuration, without modifying it.
+func SimulateRemoval(cfg *config.Config, logins []string) RemovalImpact {
+	var impact RemovalImpact
+	for _, teamName := range sortedKeys(cfg.Teams) {
+		teamCfg := cfg.Teams[teamName]
+		i := TeamImpact{
+			Team:         teamName,
+			Removed:      set.IntersectionFold(teamCfg.Members, logins),
+			Members:      len(teamCfg.Members),
+			MembersAfter: len(set.DifferenceFold(teamCfg.Members, logins)),
+		}
+		if teamCfg.CodeReviewAssignment.Enabled {
+			eligible := eligibleReviewers(cfg, teamName)
+			i.Eligible = len(eligible)
+			i.EligibleAfter = len(set.DifferenceFold(eligible, logins))
+		}
+		if i.Affected() {
+			i.Violations = teamViolations(teamCfg, i, logins)
+		}
+		impact.Teams = append(impact.Teams, i)
+	}
+
+	if len(cfg.Owners) != 0 && len(set.IntersectionFold(cfg.Owners, logins)) != 0 &&
+		len(set.DifferenceFold(cfg.Owners, logins)) == 0 {
+		impact.Violations = append(impact.Violations, "organization left without owners")
+	}
+	return impact
+}
+
+// eligibleReviewers returns the members of the given team that aren't
+// excluded from its code review assignment.
+func eligibleReviewers(cfg *config.Config, teamName string) []string {
+	excluded := append([]string(nil), cfg.ExcludeCRAFromAllTeams...)
+	for _, xMember := range cfg.ExcludedMembers(teamName) {
+		excluded = append(excluded, xMember.Login)
+	}
+	return set.DifferenceFold(cfg.Teams[teamName].Members, excluded)
+}
+
+// ReviewerCount returns the number of members assigned to review by the code
+// review assignment of the given team of cfg: its member count, or its member
+// percentage of the eligible reviewers, rounded up and at least 1.
+func ReviewerCount(cfg *config.Config, teamName string) int {
+	cra := cfg.Teams[teamName].CodeReviewAssignment
+	if cra.TeamMemberPercentage == 0 {
+		return cra.TeamMemberCount
+	}
+	count := (len(eligibleReviewers(cfg, teamName))*cra.TeamMemberPercentage + 99) / 100
+	if count < 1 {
+		return 1
+	}
+	return count
+}
+
+// teamViolations returns the policy violations of a team caused by the
+// removal with the given impact.
+func teamViolations(teamCfg config.TeamConfig, i TeamImpact, logins []string) []string {
+	var violations []string
+	if i.MembersAfter == 0 {
+		violations = append(violations, "team left without members")
+	}
+	if len(teamCfg.Maintainers) != 0 && len(set.DifferenceFold(teamCfg.Maintainers, logins)) == 0 {
+		violations = append(violations, "team left without maintainers")
+	}
+	cra := teamCfg.CodeReviewAssignment
+	switch {
+	case !cra.Enabled || i.EligibleAfter == i.Eligible:
+	case i.EligibleAfter == 0:
+		violations = append(violations, "no eligible reviewers left for the code review assignment")
+	case i.EligibleAfter < cra.TeamMemberCount:
+		violations = append(violations, fmt.Sprintf("%d eligible reviewers left, fewer than the %d assigned to every pull request", i.EligibleAfter, cra.TeamMemberCount))
+	}
+	return violations
+}
+
+// PolicyViolation is a team or a member of a configuration violating its
+// policy.
+type PolicyViolation struct {
+	Team   string `json:"team,omitempty"`
+	Login  string `json:"login,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// EvaluatePolicy returns the policy violations of cfg: teams without members,
+// code review assignments without enough eligible reviewers, and the
+// membership violations of CheckMemberships.
+func EvaluatePolicy(cfg *config.Config) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, teamName := range sortedKeys(cfg.Teams) {
+		teamCfg := cfg.Teams[teamName]
+		if len(teamCfg.Members) == 0 {
+			violations = append(violations, PolicyViolation{Team: teamName, Reason: "team without members"})
+		}
+		cra := teamCfg.CodeReviewAssignment
+		if !cra.Enabled {
+			continue
+		}
+		switch eligible := len(eligibleReviewers(cfg, teamName)); {
+		case eligible == 0:
+			violations = append(violations, PolicyViolation{Team: teamName, Reason: "no eligible reviewers for the code review assignment"})
+		case eligible < cra.TeamMemberCount:
+			violations = append(violations, PolicyViolation{
+				Team:   teamName,
+				Reason: fmt.Sprintf("%d eligible reviewers, fewer than the %d assigned to every pull request", eligible, cra.TeamMemberCount),
+			})
+		}
+	}
+	for _, v := range CheckMemberships(cfg, cfg.Policy.MaxTeams) {
+		violations = append(violations, PolicyViolation{Login: v.Login, Reason: v.Reason})
+	}
+	return violations
+}
+
+// NewViolations returns the violations of after that aren't in before.
+func NewViolations(before, after []PolicyViolation) []PolicyViolation {
+	return set.Difference(after, before)
+}
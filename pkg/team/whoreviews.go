@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package team
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v33/github"
+
+	"github.com/cilium/team-manager/pkg/config"
+	"github.com/cilium/team-manager/pkg/set"
+)
+
+// ReviewerPool are the reviewers GitHub can request for an owner of paths
+// according to CODEOWNERS.
+type ReviewerPool struct {
+	// Owner is the owner as written in CODEOWNERS, e.g. @org/team.
+	Owner string `json:"owner"`
+	// Team is the name of the team of the configuration the owner refers
+	// to, empty for users and teams missing in the configuration.
+	Team string `json:"team,omitempty"`
+	// Paths is the number of paths the owner owns.
+	Paths int `json:"paths"`
+	// CodeReviewAssignment is the code review assignment of Team, with the
+	// effective exclusions.
+	CodeReviewAssignment *config.CodeReviewAssignment `json:"codeReviewAssignment,omitempty"`
+	// Candidates are the reviewers the code review assignment picks from,
+	// or the requested user.
+	Candidates []string `json:"candidates"`
+	// Excluded are the members of Team that aren't candidates.
+	Excluded []config.ExcludedMember `json:"excluded,omitempty"`
+	// Problem is why no reviewer of the owner gets assigned, if any.
+	Problem string `json:"problem,omitempty"`
+}
+
+// ReviewerPools returns the reviewer pools of the given owners of cfg, mapped
+// to the number of paths they own, sorted by owner. The exclusions are the
+// ones push would set today, and the author of the pull request, if any, is
+// never a candidate.
+func ReviewerPools(cfg *config.Config, org string, owners map[string]int, author string, now time.Time) []ReviewerPool {
+	effectiveCfg := RotateReviewCapacity(cfg, now)
+	pools := make([]ReviewerPool, 0, len(owners))
+	for _, owner := range sortedKeys(owners) {
+		pool := ReviewerPool{Owner: owner, Paths: owners[owner], Candidates: []string{}}
+		ownerOrg, slug, isTeam := strings.Cut(strings.TrimPrefix(owner, "@"), "/")
+		switch {
+		case !strings.HasPrefix(owner, "@"):
+			pool.Problem = "owner referenced by email address, resolved by GitHub"
+		case !isTeam:
+			if strings.EqualFold(ownerOrg, author) {
+				pool.Problem = "author of the pull request"
+			} else {
+				pool.Candidates = []string{ownerOrg}
+			}
+		case !strings.EqualFold(ownerOrg, org):
+			pool.Problem = fmt.Sprintf("team of another organization than %s", org)
+		default:
+			reviewerPool(effectiveCfg, slug, author, &pool)
+		}
+		pools = append(pools, pool)
+	}
+	return pools
+}
+
+// reviewerPool fills pool with the reviewers of the team of cfg with the given
+// slug.
+func reviewerPool(cfg *config.Config, slug, author string, pool *ReviewerPool) {
+	for teamName := range cfg.Teams {
+		if strings.EqualFold(TeamSlug(cfg, teamName), slug) {
+			pool.Team = teamName
+			break
+		}
+	}
+	if pool.Team == "" {
+		pool.Problem = "team missing in the configuration"
+		return
+	}
+
+	teamCfg := cfg.Teams[pool.Team]
+	cra := teamCfg.CodeReviewAssignment
+	cra.ExcludedMembers = cfg.ExcludedMembers(pool.Team)
+	cra.TeamMemberCount = ReviewerCount(cfg, pool.Team)
+	pool.CodeReviewAssignment = &cra
+	var excluded []string
+	exclude := func(xMember config.ExcludedMember) {
+		if set.ContainsFold(teamCfg.Members, xMember.Login) && !set.ContainsFold(excluded, xMember.Login) {
+			excluded = append(excluded, xMember.Login)
+			pool.Excluded = append(pool.Excluded, xMember)
+		}
+	}
+	if author != "" {
+		exclude(config.ExcludedMember{Login: author, Reason: "author of the pull request"})
+	}
+	// Exclusions don't apply when the whole team is requested.
+	if cra.Enabled {
+		for _, login := range cfg.ExcludeCRAFromAllTeams {
+			exclude(config.ExcludedMember{Login: login, Reason: "excluded from the code review assignment of all teams"})
+		}
+		for _, xMember := range cra.ExcludedMembers {
+			exclude(xMember)
+		}
+	}
+
+	pool.Candidates = append(pool.Candidates, set.DifferenceFold(teamCfg.Members, excluded)...)
+	sort.Strings(pool.Candidates)
+	switch {
+	case len(teamCfg.Members) == 0:
+		pool.Problem = "empty team"
+	case !cra.Enabled:
+		pool.Problem = "code review assignment disabled, the whole team is requested"
+	case len(pool.Candidates) == 0:
+		pool.Problem = "no eligible reviewers, the whole team is requested"
+	}
+}
+
+// GetCodeOwners returns the content of the CODEOWNERS file GitHub uses for the
+// given branch of the repository, the first one found in CodeOwnersPaths, or
+// an empty string if there is none. An empty branch is the default branch.
+func (tm *Manager) GetCodeOwners(ctx context.Context, repo, branch string) (string, error) {
+	for _, p := range CodeOwnersPaths {
+		content, err := tm.getFileContent(ctx, repo, branch, p)
+		if err != nil || content != "" {
+			return content, err
+		}
+	}
+	return "", nil
+}
+
+// GetPullRequestFiles returns the base branch, the author and the paths of the
+// files changed by the given pull request of the repository.
+func (tm *Manager) GetPullRequestFiles(ctx context.Context, repo string, number int) (base, author string, files []string, err error) {
+	pr, _, err := tm.ghClient.PullRequests.Get(ctx, tm.owner, repo, number)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to get pull request %s#%d: %w", repo, number, err)
+	}
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := tm.ghClient.PullRequests.ListFiles(ctx, tm.owner, repo, number, opts)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("failed to list files of pull request %s#%d: %w", repo, number, err)
+		}
+		for _, f := range page {
+			files = append(files, f.GetFilename())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return pr.GetBase().GetRef(), pr.GetUser().GetLogin(), files, nil
+}
@@ -18,16 +18,19 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"os/exec"
 	"strings"
 )
 
-func AskForConfirmation(s string) (bool, error) {
-	reader := bufio.NewReader(os.Stdin)
+// stdin is shared by all prompts so that input buffered by one prompt is not
+// lost for the next one.
+var stdin = bufio.NewReader(os.Stdin)
 
+func AskForConfirmation(s string) (bool, error) {
 	for {
 		fmt.Printf("%s [y/n]: ", s)
 
-		response, err := reader.ReadString('\n')
+		response, err := stdin.ReadString('\n')
 		if err != nil {
 			return false, err
 		}
@@ -41,3 +44,49 @@ func AskForConfirmation(s string) (bool, error) {
 		}
 	}
 }
+
+// AskForInput prints the given question and returns the trimmed answer. If
+// the answer is empty, defaultValue is returned.
+func AskForInput(s, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Printf("%s [%s]: ", s, defaultValue)
+	} else {
+		fmt.Printf("%s: ", s)
+	}
+
+	response, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	response = strings.TrimSpace(response)
+	if response == "" {
+		return defaultValue, nil
+	}
+	return response, nil
+}
+
+// AskForSecret prints the given question and returns the trimmed answer,
+// without echoing it if stdin is a terminal that supports stty(1).
+func AskForSecret(s string) (string, error) {
+	fmt.Printf("%s: ", s)
+
+	if err := stty("-echo"); err == nil {
+		defer func() {
+			stty("echo")
+			fmt.Println()
+		}()
+	}
+
+	response, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(response), nil
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}